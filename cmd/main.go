@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"api.backend.xjco2913/service/user"
 	"api.backend.xjco2913/util/config"
 	"api.backend.xjco2913/util/zlog"
 )
@@ -31,6 +32,10 @@ func main() {
 
 	// Async flush logs into mysql
 	go FlushLogs(ctx)
+	go SyncBans(ctx)
+	go SweepMemberships(ctx)
+	// Continue account deletions interrupted by a restart
+	go user.Service().ResumeDeletions(ctx)
 
 	zlog.Info(fmt.Sprintf("Starting listening at :%v...", port))
 	r.Run(fmt.Sprintf(":%v", port))
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"time"
 
 	"api.backend.xjco2913/controller/activity"
 	"api.backend.xjco2913/controller/admin"
@@ -13,13 +12,13 @@ import (
 	"api.backend.xjco2913/controller/moment"
 	"api.backend.xjco2913/controller/notify"
 	"api.backend.xjco2913/controller/organiser"
+	"api.backend.xjco2913/controller/sso"
 	"api.backend.xjco2913/controller/user"
 	"api.backend.xjco2913/controller/ws"
 	"api.backend.xjco2913/middleware"
+	"api.backend.xjco2913/service/token"
 	userService "api.backend.xjco2913/service/user"
-	"api.backend.xjco2913/util/config"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -36,6 +35,7 @@ func NewRouter() *gin.Engine {
 	commentController := comment.NewCommentController()
 	organiserController := organiser.NewOrganiserController()
 	notifyController := notify.NewNotifyController()
+	ssoController := sso.NewSSOController()
 
 	// Global middleware
 	// Prometheus
@@ -47,64 +47,56 @@ func NewRouter() *gin.Engine {
 	// WebSocket
 	r.GET("/ws", gin.WrapF(websocketController.HandleConnections))
 
-	// Demo admin login api, only for test
-	r.GET("/api/getAdmin", func(c *gin.Context) {
-		username := c.Query("username")
-		password := c.Query("password")
-
-		if username != "deck" {
-			c.JSON(400, gin.H{
-				"username": username,
-				"password": password,
-				"msg":      "not a admin",
-			})
-			return
-		} else if password != "20030416" {
-			c.JSON(400, gin.H{
-				"username": username,
-				"password": password,
-				"msg":      "wrong password",
-			})
-			return
-		}
-
-		claims := jwt.MapClaims{
-			"userID":         "123123123",
-			"isAdmin":        true,
-			"isOrganiser":    true,
-			"membershipType": 2,
-			"exp":            time.Now().Add(24 * time.Hour).Unix(),
-		}
-
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		secret := config.Get("jwt.secret")
-		tokenStr, _ := token.SignedString([]byte(secret))
-
-		c.JSON(200, gin.H{
-			"username": username,
-			"password": password,
-			"token":    tokenStr,
-		})
-	})
-
 	api := r.Group("/api")
 	{
 		// Group middleware
-		api.Use(middleware.VerifyToken())
+		api.Use(middleware.VerifyToken(token.Service().Validate))
 
 		api.POST("/user/refresh", userController.RefreshToken)
+		api.POST("/user/logout", userController.Logout)
+		api.POST("/user/logout/all", userController.LogoutAll)
+		api.PATCH("/user/password", userController.ChangePassword)
+		api.POST("/user/password/forgot", userController.ForgotPassword)
+		api.POST("/user/password/reset", userController.ResetPassword)
+		api.POST("/user/email/verify", userController.VerifyEmail)
+		api.POST("/user/email/resend", userController.ResendVerification)
+		api.GET("/user/sessions", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.GetSessions)
+		api.DELETE("/user/session", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.RevokeSession)
+		api.DELETE("/user/sessions", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.RevokeAllSessions)
 		api.POST("/user/register", userController.SignUp)
 		api.POST("/user/login", userController.Login)
+		api.POST("/user/login/2fa", userController.LoginTwoFactor)
+		api.POST("/user/2fa/setup", userController.SetupTwoFactor)
+		api.POST("/user/2fa/enable", userController.EnableTwoFactor)
+		api.POST("/user/2fa/disable", userController.DisableTwoFactor)
+		api.POST("/user/2fa/recovery", userController.RegenerateRecoveryCodes)
 		api.GET("/user", userController.GetByID)
-		api.GET("/users", userController.GetAll)
-		api.DELETE("/user", userController.DeleteByID)
-		api.POST("/user/ban", userController.BanByID)
-		api.POST("/user/unban", userController.UnbanByID)
-		api.GET("/user/status", userController.IsBanned)
-		api.GET("/user/statuses", userController.GetAllStatus)
-		api.PATCH("/user", userController.UpdateByID)
-		api.POST("/user/subscribe", userController.Subscribe)
-		api.POST("/user/cancel", userController.CancelByID)
+		api.GET("/user/stats", userController.Stats)
+		api.GET("/user/achievements", userController.Achievements)
+		api.GET("/user/privacy", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.GetPrivacy)
+		api.PATCH("/user/privacy", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.UpdatePrivacy)
+		api.GET("/user/privacy/zones", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.ListPrivacyZones)
+		api.POST("/user/privacy/zone", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.CreatePrivacyZone)
+		api.DELETE("/user/privacy/zone", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.DeletePrivacyZone)
+		api.GET("/users", middleware.RequirePermission(middleware.PERM_USER_MANAGE), userController.GetAll)
+		api.DELETE("/user", middleware.RequirePermission(middleware.PERM_USER_MANAGE), userController.DeleteByID)
+		api.POST("/user/deletion", middleware.RequireRole(middleware.ROLE_USER), userController.DeleteAccount)
+		api.GET("/user/deletion", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.DeletionStatus)
+		api.GET("/user/export", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.ExportData)
+		api.POST("/user/ban", middleware.RequirePermission(middleware.PERM_USER_MANAGE), userController.BanByID)
+		api.POST("/user/unban", middleware.RequirePermission(middleware.PERM_USER_MANAGE), userController.UnbanByID)
+		api.PATCH("/user/ban/appeal", middleware.RequirePermission(middleware.PERM_USER_MANAGE), userController.AppealBan)
+		api.GET("/user/status", middleware.RequirePermission(middleware.PERM_USER_MANAGE), userController.IsBanned)
+		api.GET("/user/statuses", middleware.RequirePermission(middleware.PERM_USER_MANAGE), userController.GetAllStatus)
+		api.PATCH("/user", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.UpdateByID)
+		api.POST("/user/subscribe", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.Subscribe)
+		api.POST("/user/cancel", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.CancelByID)
+		api.GET("/user/membership", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.GetMembership)
+		api.GET("/user/membership/plans", userController.MembershipPlans)
+		api.PUT("/user/membership/autorenew", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.SetAutoRenew)
+		api.POST("/user/voucher/redeem", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.RedeemVoucher)
+		api.POST("/user/trial", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), userController.StartTrial)
+		api.GET("/user/credits", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), userController.Credits)
 		api.GET("/test", func(c *gin.Context) {
 			userID := c.GetString("userID")
 			isAdmin := c.GetBool("isAdmin")
@@ -115,12 +107,36 @@ func NewRouter() *gin.Engine {
 			})
 		})
 
-		api.POST("/user/avatar", userController.UploadAvatar)
+		api.GET("/user/identities", middleware.RequireOwnership(middleware.QueryOwner("userID"), middleware.PERM_USER_MANAGE), ssoController.Identities)
+		api.DELETE("/user/identity", middleware.RequireOwnership(middleware.QueryOwner("userID"), ""), ssoController.Unlink)
+		api.POST("/user/avatar", middleware.RequireOwnership(middleware.FormOwner("userId"), middleware.PERM_USER_MANAGE), userController.UploadAvatar)
 
 		// Admin
 		admin := api.Group("/admin")
 		{
 			admin.POST("/login", adminController.Login)
+			admin.POST("/refresh", adminController.RefreshToken)
+			admin.POST("/logout", adminController.Logout)
+			admin.POST("/login/2fa", adminController.LoginTwoFactor)
+			admin.POST("/login/2fa/setup", adminController.SetupTwoFactorLogin)
+			admin.POST("/2fa/setup", middleware.RequireRole(middleware.ROLE_ADMIN), adminController.SetupTwoFactor)
+			admin.POST("/2fa/enable", middleware.RequireRole(middleware.ROLE_ADMIN), adminController.EnableTwoFactor)
+			admin.POST("/2fa/disable", middleware.RequireRole(middleware.ROLE_ADMIN), adminController.DisableTwoFactor)
+			admin.POST("/2fa/recovery", middleware.RequireRole(middleware.ROLE_ADMIN), adminController.RegenerateRecoveryCodes)
+			admin.GET("/2fa/enforce", middleware.RequireRole(middleware.ROLE_ADMIN), adminController.TwoFactorEnforcement)
+			admin.PUT("/2fa/enforce", middleware.RequireRole(middleware.ROLE_ADMIN), adminController.EnforceTwoFactor)
+			admin.POST("/vouchers", middleware.RequirePermission(middleware.PERM_VOUCHER_MANAGE), adminController.CreateVouchers)
+			admin.GET("/vouchers", middleware.RequirePermission(middleware.PERM_VOUCHER_MANAGE), adminController.VoucherBatches)
+			admin.DELETE("/vouchers", middleware.RequirePermission(middleware.PERM_VOUCHER_MANAGE), adminController.RevokeVoucherBatch)
+		}
+
+		// Login through OpenID Connect providers
+		sso := api.Group("/sso")
+		{
+			sso.GET("/providers", ssoController.Providers)
+			sso.GET("/login", ssoController.Login)
+			sso.POST("/callback", ssoController.Callback)
+			sso.GET("/link", middleware.RequireRole(middleware.ROLE_USER), ssoController.Link)
 		}
 
 		// Moments
@@ -128,24 +144,35 @@ func NewRouter() *gin.Engine {
 		{
 			moment.POST("/create", momentController.Create)
 			moment.GET("/feed", momentController.Feed)
+			moment.GET("/trending", momentController.Trending)
+			moment.PUT("", middleware.RequireOwnership(momentController.OwnsMoment, ""), momentController.Edit)
+			moment.DELETE("", middleware.RequireOwnership(momentController.OwnsMoment, middleware.PERM_USER_MANAGE), momentController.Delete)
 			moment.POST("/like", likeController.Create)
 			moment.DELETE("/unlike", likeController.DeleteByIDs)
 			moment.POST("/comment", commentController.Create)
+			moment.PUT("/comment", commentController.Edit)
+			moment.DELETE("/comment", commentController.Delete)
+			moment.GET("/comment/history", commentController.History)
+			moment.GET("/comments", momentController.Comments)
 			moment.GET("/me", momentController.GetByUserID)
+			moment.GET("/user", momentController.GetByUserID)
 		}
 
 		// Activity
 		activity := api.Group("/activity")
 		{
-			activity.POST("/create", activityController.Create)
+			activity.POST("/create", middleware.RequirePermission(middleware.PERM_ACTIVITY_CREATE), activityController.Create)
 			activity.GET("", activityController.GetByID)
 			activity.GET("/all", activityController.GetAll)
 			activity.GET("/feed", activityController.Feed)
-			activity.DELETE("", activityController.DeleteByID)
+			activity.GET("/trending", activityController.Trending)
+			activity.DELETE("", middleware.RequireOwnership(activityController.OwnsActivity, middleware.PERM_ACTIVITY_MANAGE), activityController.DeleteByID)
 			activity.POST("/signup", activityController.SignUpByActivityID)
 			activity.GET("/user", activityController.GetByUserID)
 			activity.GET("/creator", activityController.GetByCreatorID)
-			activity.GET("/profit", activityController.GetProfitWithOption)
+			activity.GET("/profit", middleware.RequirePermission(middleware.PERM_REPORT_VIEW), activityController.GetProfitWithOption)
+			activity.GET("/revenue", middleware.RequirePermission(middleware.PERM_REPORT_VIEW), activityController.RevenueReport)
+			activity.GET("/revenue/export", middleware.RequirePermission(middleware.PERM_REPORT_VIEW), activityController.ExportRevenueReport)
 			activity.GET("/tags", activityController.TagsInfo)
 			activity.GET("/counts", activityController.Counts)
 			activity.POST("/route", activityController.UploadRoute)
@@ -156,6 +183,16 @@ func NewRouter() *gin.Engine {
 		friend := api.Group("/friend")
 		{
 			friend.POST("/follow", friendController.Follow)
+			friend.DELETE("/follow", friendController.Unfollow)
+			friend.GET("/requests", friendController.FollowRequests)
+			friend.POST("/request/accept", friendController.AcceptFollowRequest)
+			friend.POST("/request/decline", friendController.DeclineFollowRequest)
+			friend.POST("/block", friendController.Block)
+			friend.DELETE("/block", friendController.Unblock)
+			friend.GET("/blocks", friendController.Blocks)
+			friend.POST("/mute", friendController.Mute)
+			friend.DELETE("/mute", friendController.Unmute)
+			friend.GET("/mutes", friendController.Mutes)
 			friend.GET("/follower", friendController.GetAllFollower)
 			friend.GET("/following", friendController.GetAllFollowing)
 			friend.GET("/", friendController.GetAll)
@@ -163,9 +200,9 @@ func NewRouter() *gin.Engine {
 
 		organiser := api.Group("/org")
 		{
-			organiser.GET("", organiserController.GetAll)
-			organiser.POST("/agree", organiserController.Agree)
-			organiser.POST("/refuse", organiserController.Refuse)
+			organiser.GET("", middleware.RequirePermission(middleware.PERM_ORG_REVIEW), organiserController.GetAll)
+			organiser.POST("/agree", middleware.RequirePermission(middleware.PERM_ORG_REVIEW), organiserController.Agree)
+			organiser.POST("/refuse", middleware.RequirePermission(middleware.PERM_ORG_REVIEW), organiserController.Refuse)
 			organiser.POST("/apply", organiserController.Apply)
 		}
 
@@ -173,7 +210,7 @@ func NewRouter() *gin.Engine {
 		{
 			notify.GET("/pull", notifyController.Pull)
 			notify.POST("/route", notifyController.ShareRoute)
-			notify.POST("/org", notifyController.OrgResult)
+			notify.POST("/org", middleware.RequirePermission(middleware.PERM_ORG_REVIEW), notifyController.OrgResult)
 		}
 
 		// mock data api
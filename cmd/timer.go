@@ -5,6 +5,10 @@ import (
 	"time"
 
 	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/membership"
+	"api.backend.xjco2913/service/user"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
 )
 
 func FlushLogs(ctx context.Context) {
@@ -15,3 +19,25 @@ func FlushLogs(ctx context.Context) {
 		redis.SyncLogs(ctx, SQL_LOG_KEY)
 	}
 }
+
+// SyncBans rebuilds the redis mirror of the bans at start up and then periodically
+func SyncBans(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Minute)
+	for {
+		if err := user.Service().SyncBans(ctx); err != nil {
+			zlog.Error("Error while syncing bans", zap.Error(err))
+		}
+		<-ticker.C
+	}
+}
+
+// SweepMemberships renews or ends expired memberships and sends the reminders
+func SweepMemberships(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Minute)
+	for {
+		if err := membership.Service().Sweep(ctx); err != nil {
+			zlog.Error("Error while sweeping memberships", zap.Error(err))
+		}
+		<-ticker.C
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"time"
 
 	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/middleware"
 	"api.backend.xjco2913/service/activity"
 	"api.backend.xjco2913/service/friend"
 	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,13 +24,11 @@ func NewActivityController() *ActivityController {
 }
 
 func (a *ActivityController) Create(c *gin.Context) {
-	isOrganiser, isOrganiserExists := c.Get("isOrganiser")
 	userID, userIDExists := c.Get("userID")
-
-	if !isOrganiserExists || !userIDExists || !isOrganiser.(bool) {
+	if !userIDExists {
 		c.JSON(403, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only organisers can access this resource",
+			StatusMsg:  "User ID does not exist",
 		})
 		return
 	}
@@ -278,7 +278,7 @@ func (a *ActivityController) GetByID(c *gin.Context) {
 		}
 	}
 
-	activity, serviceErr := activity.Service().GetByID(c.Request.Context(), activityID)
+	activity, serviceErr := activity.Service().GetByID(c.Request.Context(), activityID, middleware.Viewer(c))
 	if serviceErr != nil {
 		c.JSON(serviceErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -387,30 +387,26 @@ func (a *ActivityController) Feed(c *gin.Context) {
 	})
 }
 
-func (a *ActivityController) DeleteByID(c *gin.Context) {
-	activityID := c.Query("activityID")
+// Trending returns the activities trending everywhere, or in the region query if one is given
+func (a *ActivityController) Trending(c *gin.Context) {
+	activities, err := activity.Service().Trending(c.Request.Context(), c.Query("region"))
+	if err != nil {
+		c.JSON(err.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  err.Error(),
+		})
+		return
+	}
 
-	isAdmin, isAdminExists := c.Get("isAdmin")
-	if !isAdminExists || !isAdmin.(bool) {
-		// Non-admins must be the creator to delete the activity
-		activityDetail, serviceErr := activity.Service().GetByID(c.Request.Context(), activityID)
-		if serviceErr != nil {
-			c.JSON(serviceErr.Code(), dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  serviceErr.Error(),
-			})
-			return
-		}
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get trending activities successfully",
+		Data:       activities,
+	})
+}
 
-		userID, userIDExists := c.Get("userID")
-		if !userIDExists || activityDetail.CreatorID != userID.(string) {
-			c.JSON(403, dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  "Forbidden: You are not the creator of this activity",
-			})
-			return
-		}
-	}
+func (a *ActivityController) DeleteByID(c *gin.Context) {
+	activityID := c.Query("activityID")
 
 	serviceErr := activity.Service().DeleteByID(c.Request.Context(), activityID)
 	if serviceErr != nil {
@@ -462,6 +458,7 @@ func (a *ActivityController) SignUpByActivityID(c *gin.Context) {
 		UserID:         userID.(string),
 		ActivityID:     activityID,
 		MembershipType: membershipType,
+		UseCredits:     c.Query("useCredits") == "true",
 	}
 
 	serviceErr := activity.Service().SignUpByActivityID(c.Request.Context(), input)
@@ -696,6 +693,83 @@ func (a *ActivityController) GetProfitWithOption(c *gin.Context) {
 	})
 }
 
+// OwnsActivity is the ownership check for routes addressing activities by the activityID query
+func (a *ActivityController) OwnsActivity(c *gin.Context, userID string) (bool, *errorx.ServiceErr) {
+	return activity.Service().IsCreator(c.Request.Context(), c.Query("activityID"), userID)
+}
+
+func (a *ActivityController) RevenueReport(c *gin.Context) {
+	var req dto.RevenueReportReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	resp, sErr := activity.Service().RevenueReport(c.Request.Context(), &sdto.RevenueReportInput{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Interval:  req.Interval,
+		GroupBy:   req.GroupBy,
+		Timezone:  req.Timezone,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get revenue report successfully",
+		Data: gin.H{
+			"interval": resp.Interval,
+			"groupBy":  resp.GroupBy,
+			"timezone": resp.Timezone,
+			"buckets":  resp.Buckets,
+			"series":   resp.Series,
+			"total":    resp.Total,
+			"signups":  resp.Signups,
+		},
+	})
+}
+
+func (a *ActivityController) ExportRevenueReport(c *gin.Context) {
+	var req dto.RevenueReportReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+
+	resp, sErr := activity.Service().ExportRevenueReport(c.Request.Context(), &sdto.RevenueReportInput{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Interval:  req.Interval,
+		GroupBy:   req.GroupBy,
+		Timezone:  req.Timezone,
+	}, req.Format)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	c.Data(200, resp.ContentType, resp.Data)
+}
+
 func (a *ActivityController) UploadRoute(c *gin.Context) {
 	userID, userIDExists := c.Get("userID")
 	if !userIDExists {
@@ -763,6 +837,7 @@ func (a *ActivityController) GetRouteByIDs(c *gin.Context) {
 	input := &sdto.GetRouteInput{
 		UserID:     userID,
 		ActivityID: activityID,
+		Viewer:     middleware.Viewer(c),
 	}
 
 	output, serviceErr := activity.Service().GetRouteByIDs(c.Request.Context(), input)
@@ -1,6 +1,8 @@
 package admin
 
 import (
+	"time"
+
 	"api.backend.xjco2913/controller/dto"
 	"api.backend.xjco2913/service/admin"
 	"api.backend.xjco2913/service/sdto"
@@ -26,11 +28,33 @@ func (a *AdminController) Login(c *gin.Context) {
 	resp, err := admin.Service().Authenticate(c.Request.Context(), &sdto.AdminAuthenticateInput{
 		Name:     req.Name,
 		Password: req.Password,
+		Device: sdto.SessionDevice{
+			DeviceName: req.DeviceName,
+			UserAgent:  c.Request.UserAgent(),
+			IP:         c.ClientIP(),
+		},
 	})
 	if err != nil {
+		data := gin.H{
+			"remaining_attempts": err.Get("remaining_attempts"),
+		}
+		if t, ok := err.Get("lock_expires").(time.Time); ok {
+			data["lock_expires"] = t.Unix()
+		}
+
 		c.JSON(err.Code(), dto.CommonRes{
 			StatusCode: -1,
 			StatusMsg:  err.Error(),
+			Data:       data,
+		})
+		return
+	}
+
+	if resp.Challenge != nil {
+		c.JSON(200, dto.CommonRes{
+			StatusCode: 0,
+			StatusMsg:  "Two-factor authentication required",
+			Data:       twoFactorChallengeData(resp.Challenge),
 		})
 		return
 	}
@@ -39,9 +63,66 @@ func (a *AdminController) Login(c *gin.Context) {
 		StatusCode: 0,
 		StatusMsg:  "Admin login successfully",
 		Data: gin.H{
-			"token":   resp.Token,
-			"name":    resp.Name,
-			"adminID": resp.AdminId,
+			"token":        resp.Token,
+			"refreshToken": resp.RefreshToken,
+			"expiresAt":    resp.ExpiresAt,
+			"name":         resp.Name,
+			"adminID":      resp.AdminId,
+		},
+	})
+}
+
+func (a *AdminController) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	resp, err := admin.Service().RefreshToken(c.Request.Context(), req.RefreshToken, &sdto.SessionDevice{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
+	if err != nil {
+		c.JSON(err.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Refresh token successfully",
+		Data: gin.H{
+			"newToken":     resp.NewToken,
+			"refreshToken": resp.NewRefreshToken,
+			"expiresAt":    resp.ExpiresAt,
 		},
 	})
 }
+
+func (a *AdminController) Logout(c *gin.Context) {
+	err := admin.Service().Logout(
+		c.Request.Context(),
+		c.GetString("adminID"),
+		c.GetString("sessionID"),
+		c.GetString("tokenID"),
+		c.GetTime("tokenExp"),
+	)
+	if err != nil {
+		c.JSON(err.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Admin logout successfully",
+	})
+}
@@ -0,0 +1,239 @@
+package admin
+
+import (
+	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/twofactor"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactor completes an admin login that was answered with a challenge.
+// For an enrolment challenge the code confirms the new secret and the recovery codes are returned.
+func (a *AdminController) LoginTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	out, sErr := twofactor.Service().CompleteLogin(c.Request.Context(), req.ChallengeToken, true, req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+			Data: gin.H{
+				"remaining_attempts": sErr.Get("remaining_attempts"),
+			},
+		})
+		return
+	}
+
+	data := gin.H{
+		"token":        out.Tokens.AccessToken,
+		"refreshToken": out.Tokens.RefreshToken,
+		"expiresAt":    out.Tokens.ExpiresAt,
+		"adminID":      out.SubjectID,
+	}
+	if out.RecoveryCodes != nil {
+		data["recoveryCodes"] = out.RecoveryCodes
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Admin login successfully",
+		Data:       data,
+	})
+}
+
+// SetupTwoFactorLogin hands out a secret to an admin who must enrol before logging in
+func (a *AdminController) SetupTwoFactorLogin(c *gin.Context) {
+	var req dto.TwoFactorChallengeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	out, sErr := twofactor.Service().SetupWithChallenge(c.Request.Context(), req.ChallengeToken, true)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Scan the provisioning uri and log in with a code",
+		Data:       twoFactorSetupData(out),
+	})
+}
+
+func (a *AdminController) SetupTwoFactor(c *gin.Context) {
+	out, sErr := twofactor.Service().Setup(c.Request.Context(), c.GetString("adminID"), true)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Scan the provisioning uri and confirm with a code",
+		Data:       twoFactorSetupData(out),
+	})
+}
+
+func (a *AdminController) EnableTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	codes, sErr := twofactor.Service().Enable(c.Request.Context(), c.GetString("adminID"), req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Two-factor authentication enabled",
+		Data: gin.H{
+			"recoveryCodes": codes,
+		},
+	})
+}
+
+func (a *AdminController) DisableTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := twofactor.Service().Disable(c.Request.Context(), c.GetString("adminID"), true, req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Two-factor authentication disabled",
+	})
+}
+
+func (a *AdminController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	codes, sErr := twofactor.Service().RegenerateRecoveryCodes(c.Request.Context(), c.GetString("adminID"), req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Recovery codes regenerated",
+		Data: gin.H{
+			"recoveryCodes": codes,
+		},
+	})
+}
+
+// EnforceTwoFactor turns mandatory 2FA for every admin account on or off.
+// Admins without 2FA have to enrol on their next login, existing sessions are not affected.
+func (a *AdminController) EnforceTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorEnforceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := twofactor.Service().SetAdminEnforced(c.Request.Context(), *req.Enforced)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Admin two-factor enforcement updated",
+		Data: gin.H{
+			"enforced": *req.Enforced,
+		},
+	})
+}
+
+func (a *AdminController) TwoFactorEnforcement(c *gin.Context) {
+	enforced, sErr := twofactor.Service().IsAdminEnforced(c.Request.Context())
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get admin two-factor enforcement successfully",
+		Data: gin.H{
+			"enforced": enforced,
+		},
+	})
+}
+
+func twoFactorChallengeData(challenge *sdto.TwoFactorChallenge) gin.H {
+	return gin.H{
+		"twoFactorRequired": true,
+		"challengeToken":    challenge.ChallengeToken,
+		"enrollRequired":    challenge.EnrollRequired,
+		"expiresAt":         challenge.ExpiresAt,
+	}
+}
+
+func twoFactorSetupData(out *sdto.TwoFactorSetupOutput) gin.H {
+	return gin.H{
+		"secret":          out.Secret,
+		"provisioningUri": out.ProvisioningURI,
+	}
+}
@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/service/membership"
+	"api.backend.xjco2913/service/sdto"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateVouchers generates a batch of voucher codes, the codes are only in this response
+func (a *AdminController) CreateVouchers(c *gin.Context) {
+	var req dto.CreateVouchersReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	in := &sdto.VoucherBatchInput{
+		AdminID:        c.GetString("adminID"),
+		Name:           req.Name,
+		MembershipType: req.MembershipType,
+		Days:           req.Days,
+		Quantity:       req.Quantity,
+	}
+	if req.RedeemBy > 0 {
+		redeemBy := time.Unix(req.RedeemBy, 0)
+		in.RedeemBy = &redeemBy
+	}
+
+	res, sErr := membership.Service().CreateVouchers(c.Request.Context(), in)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Create vouchers successfully",
+		Data:       res,
+	})
+}
+
+func (a *AdminController) VoucherBatches(c *gin.Context) {
+	res, sErr := membership.Service().VoucherBatches(c.Request.Context())
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get voucher batches successfully",
+		Data:       res,
+	})
+}
+
+func (a *AdminController) RevokeVoucherBatch(c *gin.Context) {
+	batchID, err := strconv.Atoi(c.Query("batchID"))
+	if err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong batch id",
+		})
+		return
+	}
+
+	sErr := membership.Service().RevokeVoucherBatch(c.Request.Context(), int32(batchID), c.GetString("adminID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Revoke voucher batch successfully",
+	})
+}
@@ -2,6 +2,7 @@ package comment
 
 import (
 	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/middleware"
 	"api.backend.xjco2913/service/comment"
 	"api.backend.xjco2913/service/sdto"
 	"github.com/gin-gonic/gin"
@@ -36,6 +37,7 @@ func (cc *CommentController) Create(c *gin.Context) {
 		AuthorID: userID.(string),
 		MomentID: req.MomentID,
 		Content:  req.Content,
+		ParentID: req.ParentID,
 	}
 
 	err := comment.Service().Create(c.Request.Context(), input)
@@ -52,3 +54,88 @@ func (cc *CommentController) Create(c *gin.Context) {
 		StatusMsg:  "Create comment successfully",
 	})
 }
+
+// Edit replaces the content of a comment of the requester
+func (cc *CommentController) Edit(c *gin.Context) {
+	var req dto.EditCommentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := comment.Service().Edit(c.Request.Context(), &sdto.EditCommentInput{
+		UserID:    c.GetString("userID"),
+		CommentID: req.CommentID,
+		Content:   req.Content,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Edit comment successfully",
+	})
+}
+
+// History returns the earlier versions of the comment in the commentId query
+func (cc *CommentController) History(c *gin.Context) {
+	commentID := c.Query("commentId")
+	if commentID == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing comment ID",
+		})
+		return
+	}
+
+	edits, sErr := comment.Service().History(c.Request.Context(), middleware.Viewer(c), commentID)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get comment history successfully",
+		Data: gin.H{
+			"edits": edits,
+		},
+	})
+}
+
+// Delete removes the comment in the commentId query together with its replies
+func (cc *CommentController) Delete(c *gin.Context) {
+	commentID := c.Query("commentId")
+	if commentID == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing comment ID",
+		})
+		return
+	}
+
+	sErr := comment.Service().Delete(c.Request.Context(), middleware.Viewer(c), commentID)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Delete comment successfully",
+	})
+}
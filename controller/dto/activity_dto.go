@@ -16,3 +16,12 @@ type UploadRouteReq struct {
 	ActivityID string                `form:"activityId" binding:"required"`
 	GPXData    *multipart.FileHeader `form:"gpxData" binding:"required"`
 }
+
+type RevenueReportReq struct {
+	StartDate string `form:"startDate" binding:"required"`
+	EndDate   string `form:"endDate" binding:"required"`
+	Interval  string `form:"interval" binding:"required,oneof=day week month"`
+	GroupBy   string `form:"groupBy" binding:"omitempty,oneof=tag organiser"`
+	Timezone  string `form:"tz"`
+	Format    string `form:"format" binding:"omitempty,oneof=csv xlsx"`
+}
@@ -1,6 +1,16 @@
 package dto
 
 type AdminLoginReq struct {
-	Name string `binding:"required"`
-	Password string `binding:"required"`
-}
\ No newline at end of file
+	Name       string `binding:"required"`
+	Password   string `binding:"required"`
+	DeviceName string
+}
+
+type CreateVouchersReq struct {
+	Name           string `json:"name" binding:"required,max=128"`
+	MembershipType int32  `json:"membershipType" binding:"required"`
+	Days           int32  `json:"days" binding:"required,min=1"`
+	Quantity       int32  `json:"quantity" binding:"required,min=1,max=1000"`
+	// unix timestamp, 0 if the codes do not expire
+	RedeemBy int64 `json:"redeemBy" binding:"min=0"`
+}
@@ -3,4 +3,16 @@ package dto
 type CreateCommentReq struct {
 	MomentID string `json:"momentId" binding:"required"`
 	Content  string `json:"content" binding:"required"`
+	ParentID string `json:"parentId"`
+}
+
+type EditCommentReq struct {
+	CommentID string `json:"commentId" binding:"required"`
+	Content   string `json:"content" binding:"required"`
+}
+
+type CommentPageReq struct {
+	MomentID string `form:"momentId" binding:"required"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"pageSize" binding:"omitempty,min=1,max=100"`
 }
@@ -0,0 +1,13 @@
+package dto
+
+type FriendPageReq struct {
+	// the own list unless the list of another user is asked for
+	UserID   string `form:"userID"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"pageSize" binding:"omitempty,min=1,max=100"`
+}
+
+type FollowRequestPageReq struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"pageSize" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type EditMomentReq struct {
+	Content string `json:"content" binding:"required"`
+}
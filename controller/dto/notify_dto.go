@@ -3,6 +3,8 @@ package dto
 type ShareRouteReq struct {
 	ReceiverID string     `json:"receiverId"`
 	RouteData  [][]string `json:"routeData"`
+	// the moment the route is shared from, if any
+	MomentID string `json:"momentId"`
 }
 
 type OrgResultReq struct {
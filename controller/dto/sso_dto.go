@@ -0,0 +1,17 @@
+package dto
+
+type SSOLoginReq struct {
+	Provider   string `form:"provider" binding:"required"`
+	DeviceName string `form:"deviceName"`
+}
+
+type SSOCallbackReq struct {
+	Provider string `json:"provider" binding:"required"`
+	State    string `json:"state" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type UnlinkIdentityReq struct {
+	UserID   string `form:"userID" binding:"required"`
+	Provider string `form:"provider" binding:"required"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+type TwoFactorCodeReq struct {
+	// TOTP code or recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginReq struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type TwoFactorChallengeReq struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+}
+
+type TwoFactorEnforceReq struct {
+	Enforced *bool `json:"enforced" binding:"required"`
+}
@@ -9,14 +9,27 @@ type CommonRes struct {
 type UserSignUpReq struct {
 	Username string `binding:"required"`
 	Password string `binding:"required"`
+	Email    string `binding:"required,email"`
 	Gender   *int32 `binding:"required"`
 	Birthday string
 	Region   string `binding:"required"`
+	// optional, referral code of the user who referred the new user
+	ReferralCode string
 }
 
 type UserLoginReq struct {
-	Username string `binding:"required"`
-	Password string `binding:"required"`
+	Username   string `binding:"required"`
+	Password   string `binding:"required"`
+	DeviceName string
+}
+
+type RevokeSessionReq struct {
+	UserID    string `form:"userID" binding:"required"`
+	SessionID string `form:"sessionID" binding:"required"`
+}
+
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
 type UserUpdateReq struct {
@@ -25,4 +38,69 @@ type UserUpdateReq struct {
 	Gender   *int32  `json:"gender,omitempty"`
 	Birthday *string `json:"birthday,omitempty"`
 	Region   *string `json:"region,omitempty"`
+	Email    *string `json:"email,omitempty" binding:"omitempty,email"`
+}
+
+type BanUserReq struct {
+	Reason string `json:"reason" binding:"required,max=512"`
+	// seconds, 0 bans permanently
+	Duration int64 `json:"duration" binding:"min=0"`
+}
+
+type BanAppealReq struct {
+	BanID int32  `json:"banId" binding:"required"`
+	Note  string `json:"note" binding:"required"`
+}
+
+type ChangePasswordReq struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8"`
+}
+
+type ForgotPasswordReq struct {
+	// username or email
+	Account string `json:"account" binding:"required"`
+}
+
+type VerifyEmailReq struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResetPasswordReq struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8"`
+}
+
+type DeleteAccountReq struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type AutoRenewReq struct {
+	AutoRenew *bool `json:"autoRenew" binding:"required"`
+}
+
+type RedeemVoucherReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type UpdatePrivacyReq struct {
+	// 0 is public, 1 is followers only, 2 is private
+	ProfileVisibility *int32 `json:"profileVisibility" binding:"omitempty,min=0,max=2"`
+	HideBirthday      *bool  `json:"hideBirthday"`
+	HideRoutes        *bool  `json:"hideRoutes"`
+	// 0 is everyone may follow, 1 is nobody may follow, 2 is followers need approval
+	FollowPolicy *int32 `json:"followPolicy" binding:"omitempty,min=0,max=2"`
+}
+
+type PrivacyZoneReq struct {
+	Name      string   `json:"name" binding:"max=64"`
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+	// in meters
+	Radius int32 `json:"radius" binding:"required"`
+}
+
+type StatsReq struct {
+	UserID   string `form:"userID" binding:"required"`
+	Timezone string `form:"tz"`
 }
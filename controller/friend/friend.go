@@ -1,9 +1,15 @@
 package friend
 
 import (
+	"context"
+	"strconv"
+
 	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/middleware"
+	"api.backend.xjco2913/service/block"
 	"api.backend.xjco2913/service/friend"
 	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
 	"github.com/gin-gonic/gin"
 )
 
@@ -32,7 +38,7 @@ func (f *FriendController) Follow(c *gin.Context) {
 		return
 	}
 
-	sErr := friend.Service().Follow(c.Request.Context(), &sdto.FollowInput{
+	resp, sErr := friend.Service().Follow(c.Request.Context(), &sdto.FollowInput{
 		FollowerId:  userId,
 		FollowingId: followId,
 	})
@@ -44,13 +50,20 @@ func (f *FriendController) Follow(c *gin.Context) {
 		return
 	}
 
+	msg := "Follow user successfully"
+	if resp.Status == friend.FOLLOW_STATUS_REQUESTED {
+		msg = "Follow request sent successfully"
+	}
+
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Follow user successfully",
+		StatusMsg:  msg,
+		Data:       resp,
 	})
 }
 
-func (f *FriendController) GetAllFollower(c *gin.Context) {
+// Unfollow also withdraws a follow request that is not decided yet
+func (f *FriendController) Unfollow(c *gin.Context) {
 	userId := c.GetString("userID")
 	if userId == "" {
 		c.JSON(400, dto.CommonRes{
@@ -60,7 +73,40 @@ func (f *FriendController) GetAllFollower(c *gin.Context) {
 		return
 	}
 
-	resp, sErr := friend.Service().GetAllFollower(c.Request.Context(), userId)
+	followId := c.Query("followingId")
+	if followId == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing follow user ID",
+		})
+		return
+	}
+
+	sErr := friend.Service().Unfollow(c.Request.Context(), &sdto.FollowInput{
+		FollowerId:  userId,
+		FollowingId: followId,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Unfollow user successfully",
+	})
+}
+
+func (f *FriendController) GetAllFollower(c *gin.Context) {
+	in, ok := pageInput(c)
+	if !ok {
+		return
+	}
+
+	resp, sErr := friend.Service().GetAllFollower(c.Request.Context(), in)
 	if sErr != nil {
 		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -77,6 +123,50 @@ func (f *FriendController) GetAllFollower(c *gin.Context) {
 }
 
 func (f *FriendController) GetAllFollowing(c *gin.Context) {
+	in, ok := pageInput(c)
+	if !ok {
+		return
+	}
+
+	resp, sErr := friend.Service().GetAllFollowing(c.Request.Context(), in)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get all followings successfully",
+		Data:       resp.Followings,
+	})
+}
+
+func (f *FriendController) GetAll(c *gin.Context) {
+	in, ok := pageInput(c)
+	if !ok {
+		return
+	}
+
+	resp, sErr := friend.Service().GetAll(c.Request.Context(), in)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get friends successfully",
+		Data:       resp.Friends,
+	})
+}
+
+func (f *FriendController) FollowRequests(c *gin.Context) {
 	userId := c.GetString("userID")
 	if userId == "" {
 		c.JSON(400, dto.CommonRes{
@@ -86,7 +176,16 @@ func (f *FriendController) GetAllFollowing(c *gin.Context) {
 		return
 	}
 
-	resp, sErr := friend.Service().GetAllFollowing(c.Request.Context(), userId)
+	var req dto.FollowRequestPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	res, sErr := friend.Service().FollowRequests(c.Request.Context(), userId, req.Page, req.PageSize)
 	if sErr != nil {
 		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -97,12 +196,79 @@ func (f *FriendController) GetAllFollowing(c *gin.Context) {
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Get all followings successfully",
-		Data:       resp.Followings,
+		StatusMsg:  "Get follow requests successfully",
+		Data:       res,
 	})
 }
 
-func (f *FriendController) GetAll(c *gin.Context) {
+func (f *FriendController) AcceptFollowRequest(c *gin.Context) {
+	userId, requestId, ok := requestParams(c)
+	if !ok {
+		return
+	}
+
+	sErr := friend.Service().AcceptFollowRequest(c.Request.Context(), userId, requestId)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Accept follow request successfully",
+	})
+}
+
+func (f *FriendController) DeclineFollowRequest(c *gin.Context) {
+	userId, requestId, ok := requestParams(c)
+	if !ok {
+		return
+	}
+
+	sErr := friend.Service().DeclineFollowRequest(c.Request.Context(), userId, requestId)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Decline follow request successfully",
+	})
+}
+
+func (f *FriendController) Block(c *gin.Context) {
+	f.relate(c, block.Service().Block, "Block user successfully")
+}
+
+func (f *FriendController) Unblock(c *gin.Context) {
+	f.relate(c, block.Service().Unblock, "Unblock user successfully")
+}
+
+func (f *FriendController) Mute(c *gin.Context) {
+	f.relate(c, block.Service().Mute, "Mute user successfully")
+}
+
+func (f *FriendController) Unmute(c *gin.Context) {
+	f.relate(c, block.Service().Unmute, "Unmute user successfully")
+}
+
+func (f *FriendController) Blocks(c *gin.Context) {
+	f.related(c, block.Service().Blocks, "Get blocked users successfully")
+}
+
+func (f *FriendController) Mutes(c *gin.Context) {
+	f.related(c, block.Service().Mutes, "Get muted users successfully")
+}
+
+// relate blocks, mutes or undoes either for the user in the token and the user in the targetId query
+func (f *FriendController) relate(c *gin.Context, action func(ctx context.Context, userID, targetID string) *errorx.ServiceErr, msg string) {
 	userId := c.GetString("userID")
 	if userId == "" {
 		c.JSON(400, dto.CommonRes{
@@ -112,7 +278,41 @@ func (f *FriendController) GetAll(c *gin.Context) {
 		return
 	}
 
-	resp, sErr := friend.Service().GetAll(c.Request.Context(), userId)
+	targetId := c.Query("targetId")
+	if targetId == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing target user ID",
+		})
+		return
+	}
+
+	if sErr := action(c.Request.Context(), userId, targetId); sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  msg,
+	})
+}
+
+// related lists the users the user in the token blocked or muted
+func (f *FriendController) related(c *gin.Context, list func(ctx context.Context, userID string) ([]*sdto.RelatedUser, *errorx.ServiceErr), msg string) {
+	userId := c.GetString("userID")
+	if userId == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing userID in token",
+		})
+		return
+	}
+
+	res, sErr := list(c.Request.Context(), userId)
 	if sErr != nil {
 		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -122,8 +322,61 @@ func (f *FriendController) GetAll(c *gin.Context) {
 	}
 
 	c.JSON(200, dto.CommonRes{
-		StatusCode: -1,
-		StatusMsg:  "Get friends successfully",
-		Data:       resp.Friends,
+		StatusCode: 0,
+		StatusMsg:  msg,
+		Data:       res,
 	})
 }
+
+// pageInput reads the user and the page of a follow list, writing the response if they are wrong
+func pageInput(c *gin.Context) (*sdto.FollowPageInput, bool) {
+	var req dto.FriendPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	if req.UserID == "" {
+		req.UserID = c.GetString("userID")
+	}
+	if req.UserID == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing userID in token",
+		})
+		return nil, false
+	}
+
+	return &sdto.FollowPageInput{
+		Viewer:   middleware.Viewer(c),
+		UserID:   req.UserID,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, true
+}
+
+// requestParams reads the user deciding and the follow request, writing the response if they are wrong
+func requestParams(c *gin.Context) (string, int32, bool) {
+	userId := c.GetString("userID")
+	if userId == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing userID in token",
+		})
+		return "", 0, false
+	}
+
+	requestId, err := strconv.Atoi(c.Query("requestID"))
+	if err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong request id",
+		})
+		return "", 0, false
+	}
+
+	return userId, int32(requestId), true
+}
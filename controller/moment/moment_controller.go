@@ -9,8 +9,10 @@ import (
 	"strconv"
 
 	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/middleware"
 	"api.backend.xjco2913/service/moment"
 	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
 	"github.com/gin-gonic/gin"
 )
 
@@ -30,97 +32,28 @@ func (m *MomentController) Create(c *gin.Context) {
 		return
 	}
 
-	content := c.PostForm("content")
-
-	videoFileHeader, errVideo := c.FormFile("videoFile")
-	imageFileHeader, errImage := c.FormFile("imageFile")
-	GPXFileHeader, errGPX := c.FormFile("gpxFile")
-
-	// Get the number of files and available file headers
-	fileHeaderMap := map[string]*multipart.FileHeader{
-		"gpxFile":   GPXFileHeader,
-		"imageFile": imageFileHeader,
-		"videoFile": videoFileHeader,
+	in := &sdto.CreateMomentInput{
+		UserID:  userId.(string),
+		Content: c.PostForm("content"),
 	}
 
-	fileCnt := 0
-	validFileHeader := ""
-	if errGPX == nil {
-		fileCnt++
-		validFileHeader = "gpxFile"
-	}
-	if errImage == nil {
-		fileCnt++
-		validFileHeader = "imageFile"
-	}
-	if errVideo == nil {
-		fileCnt++
-		validFileHeader = "videoFile"
+	// imageFile may be sent several times, the images are kept in the order they were sent
+	var imageHeaders, videoHeaders, gpxHeaders []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		imageHeaders = form.File["imageFile"]
+		videoHeaders = form.File["videoFile"]
+		gpxHeaders = form.File["gpxFile"]
 	}
-
-	// Process files
-	// 1. When the content is empty, only one file can be transferred. Any other number of files (including 0) is a logical error.
-	// 2. If the content is not empty, then the file does not need to be transferred.
-	switch {
-	case fileCnt == 0:
-		if content == "" {
-			// The number of files is 0
-			c.JSON(400, dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  "Moment cannot be empty",
-			})
-			return
-		}
-
-		validFileHeader = "null"
-
-	case fileCnt > 1:
-		// Too many files
+	if len(videoHeaders) > 1 || len(gpxHeaders) > 1 {
 		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Only allow upload one type of file",
+			StatusMsg:  "Only allow upload one video and one gpx file",
 		})
 		return
 	}
 
-	fileHeader := fileHeaderMap[validFileHeader]
-	switch validFileHeader {
-	case "gpxFile":
-		// Parser gpx file logic
-		gpxFile, err := fileHeader.Open()
-		if err != nil {
-			c.JSON(400, dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  fmt.Sprintf("Failed to get gpx file: %s", err.Error()),
-			})
-			return
-		}
-		defer gpxFile.Close()
-
-		gpxBuf := bytes.NewBuffer(nil)
-		if _, err := io.Copy(gpxBuf, gpxFile); err != nil {
-			c.JSON(400, dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  fmt.Sprintf("Failed copy image data: %s", err.Error()),
-			})
-			return
-		}
-
-		sErr := moment.Service().CreateWithGPX(context.Background(), &sdto.CreateMomentGPXInput{
-			UserID:  userId.(string),
-			Content: content,
-			GPXData: gpxBuf.Bytes(),
-		})
-		if sErr != nil {
-			c.JSON(sErr.Code(), dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  sErr.Error(),
-			})
-			return
-		}
-
-	case "imageFile":
-		imageFile, err := fileHeader.Open()
+	for _, fileHeader := range imageHeaders {
+		data, err := readFile(fileHeader)
 		if err != nil {
 			c.JSON(400, dto.CommonRes{
 				StatusCode: -1,
@@ -128,32 +61,10 @@ func (m *MomentController) Create(c *gin.Context) {
 			})
 			return
 		}
-		defer imageFile.Close()
-
-		imageBuf := bytes.NewBuffer(nil)
-		if _, err := io.Copy(imageBuf, imageFile); err != nil {
-			c.JSON(400, dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  fmt.Sprintf("Failed to copy image data: %s", err.Error()),
-			})
-			return
-		}
-
-		sErr := moment.Service().CreateWithImage(c.Request.Context(), &sdto.CreateMomentImageInput{
-			UserID:    userId.(string),
-			Content:   content,
-			ImageData: imageBuf.Bytes(),
-		})
-		if sErr != nil {
-			c.JSON(sErr.Code(), dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  sErr.Error(),
-			})
-			return
-		}
-
-	case "videoFile":
-		videoFile, err := fileHeader.Open()
+		in.Images = append(in.Images, data)
+	}
+	if len(videoHeaders) == 1 {
+		data, err := readFile(videoHeaders[0])
 		if err != nil {
 			c.JSON(400, dto.CommonRes{
 				StatusCode: -1,
@@ -161,43 +72,27 @@ func (m *MomentController) Create(c *gin.Context) {
 			})
 			return
 		}
-		defer videoFile.Close()
-
-		videoBuf := bytes.NewBuffer(nil)
-		if _, err := io.Copy(videoBuf, videoFile); err != nil {
+		in.Video = data
+		in.VideoContentType = videoHeaders[0].Header.Get("Content-Type")
+	}
+	if len(gpxHeaders) == 1 {
+		data, err := readFile(gpxHeaders[0])
+		if err != nil {
 			c.JSON(400, dto.CommonRes{
 				StatusCode: -1,
-				StatusMsg:  fmt.Sprintf("Failed copy video data: %s", err.Error()),
-			})
-			return
-		}
-
-		sErr := moment.Service().CreateWithVideo(context.Background(), &sdto.CreateMomentVideoInput{
-			UserID:    userId.(string),
-			Content:   content,
-			VideoData: videoBuf.Bytes(),
-		})
-		if sErr != nil {
-			c.JSON(sErr.Code(), dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  sErr.Error(),
+				StatusMsg:  fmt.Sprintf("Failed to get gpx file: %s", err.Error()),
 			})
 			return
 		}
+		in.GPXData = data
+	}
 
-	case "null":
-		// Only content, no files
-		sErr := moment.Service().Create(c.Request.Context(), &sdto.CreateMomentInput{
-			UserID:  userId.(string),
-			Content: content,
+	if sErr := moment.Service().Create(c.Request.Context(), in); sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
 		})
-		if sErr != nil {
-			c.JSON(sErr.Code(), dto.CommonRes{
-				StatusCode: -1,
-				StatusMsg:  sErr.Error(),
-			})
-			return
-		}
+		return
 	}
 
 	// Retrieve the latest moment after creation
@@ -223,12 +118,7 @@ func (m *MomentController) Create(c *gin.Context) {
 		},
 	}
 
-	if moment.ImageURL != nil {
-		momentResp["media_image"] = moment.ImageURL
-	}
-	if moment.VideoURL != nil {
-		momentResp["media_video"] = moment.VideoURL
-	}
+	withAttachments(momentResp, res.Attachments, "media_image", "media_video")
 	if moment.RouteID != nil {
 		momentResp["media"] = res.GPXRouteText
 	}
@@ -279,6 +169,94 @@ func (m *MomentController) Feed(c *gin.Context) {
 		return
 	}
 
+	moments, ok := feedMoments(c, userId, res)
+	if !ok {
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get feed moments successfully",
+		Data: gin.H{
+			"moments":  moments,
+			"nextTime": res.NextTime,
+		},
+	})
+}
+
+// Trending returns the moments trending everywhere, or in the region query if one is given
+func (m *MomentController) Trending(c *gin.Context) {
+	userId := c.GetString("userID")
+	if userId == "" {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Missing user Id in token",
+		})
+		return
+	}
+
+	res, sErr := moment.Service().Trending(c.Request.Context(), &sdto.TrendingInput{
+		UserID: userId,
+		Region: c.Query("region"),
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	moments, ok := feedMoments(c, userId, res)
+	if !ok {
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get trending moments successfully",
+		Data: gin.H{
+			"moments": moments,
+		},
+	})
+}
+
+// withAttachments adds the attachments of a moment to its response, the first image and video
+// are also set under imageKey and videoKey for clients that show one of each
+func withAttachments(momentResp gin.H, attachments []*sdto.MomentAttachment, imageKey, videoKey string) {
+	momentResp["attachments"] = attachments
+
+	for _, attachment := range attachments {
+		key := ""
+		switch attachment.Type {
+		case "image":
+			key = imageKey
+		case "video":
+			key = videoKey
+		}
+		if _, ok := momentResp[key]; key != "" && !ok {
+			momentResp[key] = attachment.URL
+		}
+	}
+}
+
+func readFile(fileHeader *multipart.FileHeader) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, file); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// feedMoments shapes the moments together with their likes and comments, writing the response on failure
+func feedMoments(c *gin.Context, userId string, res *sdto.FeedMomentOutput) ([]gin.H, bool) {
 	moments := make([]gin.H, len(res.Moments))
 	for i := range res.Moments {
 		momentID := res.Moments[i].MomentID
@@ -293,38 +271,36 @@ func (m *MomentController) Feed(c *gin.Context) {
 			},
 		}
 
-		// Check and set media url
-		if res.Moments[i].ImageURL != nil {
-			moments[i]["media_image"] = res.Moments[i].ImageURL
-		}
-		if res.Moments[i].VideoURL != nil {
-			moments[i]["media_video"] = res.Moments[i].VideoURL
-		}
+		withAttachments(moments[i], res.Attachments[momentID], "media_image", "media_video")
 		if GPXPath, ok := res.GPXRouteText[i]; ok {
 			moments[i]["media"] = GPXPath
 		}
 
 		// Get moment liked person
-		likeResp, sErr := moment.Service().GetLikesByMomentId(context.Background(), momentID)
+		likeResp, sErr := moment.Service().GetLikesByMomentId(context.Background(), momentID, userId)
 		if sErr != nil {
 			c.JSON(sErr.Code(), dto.CommonRes{
 				StatusCode: -1,
 				StatusMsg:  sErr.Error(),
 			})
-			return
+			return nil, false
 		}
 		moments[i]["personLikes"] = likeResp.PersonLikes
 
 		// Get comment list
-		commentResp, sErr := moment.Service().GetCommentListByMomentId(context.Background(), momentID)
+		commentResp, sErr := moment.Service().GetCommentListByMomentId(context.Background(), &sdto.CommentPageInput{
+			MomentID: momentID,
+			Viewer:   &sdto.Viewer{UserID: userId},
+		})
 		if sErr != nil {
 			c.JSON(sErr.Code(), dto.CommonRes{
 				StatusCode: -1,
 				StatusMsg:  sErr.Error(),
 			})
-			return
+			return nil, false
 		}
 		moments[i]["comments"] = commentResp.CommentList
+		moments[i]["commentThreads"] = commentResp.Total
 
 		isLiked, sErr := moment.Service().IsLiked(context.Background(), momentID, userId)
 		if sErr != nil {
@@ -332,23 +308,106 @@ func (m *MomentController) Feed(c *gin.Context) {
 				StatusCode: -1,
 				StatusMsg:  sErr.Error(),
 			})
-			return
+			return nil, false
 		}
 		moments[i]["isLiked"] = isLiked
 	}
 
+	return moments, true
+}
+
+// OwnsMoment is the ownership check for routes addressing a moment by the momentID query
+func (m *MomentController) OwnsMoment(c *gin.Context, userID string) (bool, *errorx.ServiceErr) {
+	return moment.Service().IsAuthor(c.Request.Context(), c.Query("momentID"), userID)
+}
+
+// Edit replaces the text of the moment in the momentID query
+func (m *MomentController) Edit(c *gin.Context) {
+	var req dto.EditMomentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := moment.Service().Edit(c.Request.Context(), &sdto.EditMomentInput{
+		MomentID: c.Query("momentID"),
+		Content:  req.Content,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Get feed moments successfully",
+		StatusMsg:  "Edit moment successfully",
+	})
+}
+
+// Delete removes the moment in the momentID query together with its likes, comments and media
+func (m *MomentController) Delete(c *gin.Context) {
+	sErr := moment.Service().Delete(c.Request.Context(), c.Query("momentID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Delete moment successfully",
+	})
+}
+
+// Comments returns a page of the comment threads of a moment
+func (m *MomentController) Comments(c *gin.Context) {
+	var req dto.CommentPageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	res, sErr := moment.Service().GetCommentListByMomentId(c.Request.Context(), &sdto.CommentPageInput{
+		MomentID: req.MomentID,
+		Viewer:   middleware.Viewer(c),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get comments successfully",
 		Data: gin.H{
-			"moments":  moments,
-			"nextTime": res.NextTime,
+			"comments": res.CommentList,
+			"total":    res.Total,
 		},
 	})
 }
 
+// GetByUserID returns the own moments, or the ones of the user in the userID query
 func (m *MomentController) GetByUserID(c *gin.Context) {
-	userID := c.GetString("userID")
+	userID := c.Query("userID")
+	if userID == "" {
+		userID = c.GetString("userID")
+	}
 	if userID == "" {
 		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
@@ -357,7 +416,7 @@ func (m *MomentController) GetByUserID(c *gin.Context) {
 		return
 	}
 
-	res, sErr := moment.Service().GetByUserID(context.Background(), userID)
+	res, sErr := moment.Service().GetByUserID(context.Background(), middleware.Viewer(c), userID)
 	if sErr != nil {
 		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -374,13 +433,7 @@ func (m *MomentController) GetByUserID(c *gin.Context) {
 			"content":   moment.Content,
 		}
 
-		// Add media URLs if present
-		if moment.ImageURL != nil {
-			moments[i]["imageUrl"] = moment.ImageURL
-		}
-		if moment.VideoURL != nil {
-			moments[i]["videoUrl"] = moment.VideoURL
-		}
+		withAttachments(moments[i], res.Attachments[moment.MomentID], "imageUrl", "videoUrl")
 		if gpxText, ok := res.GPXRouteText[i]; ok {
 			moments[i]["gpxRoute"] = gpxText
 		}
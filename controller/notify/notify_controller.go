@@ -52,8 +52,10 @@ func (n *NotifyController) ShareRoute(c *gin.Context) {
 	}
 
 	sErr := notify.Service().ShareRoute(context.Background(), &sdto.ShareRouteInput{
+		SenderID:   c.GetString("userID"),
 		ReceiverID: req.ReceiverID,
 		RouteData:  req.RouteData,
+		MomentID:   req.MomentID,
 	})
 	if sErr != nil {
 		c.JSON(sErr.Code(), dto.CommonRes{
@@ -0,0 +1,190 @@
+package sso
+
+import (
+	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sso"
+	"github.com/gin-gonic/gin"
+)
+
+type SSOController struct{}
+
+func NewSSOController() *SSOController {
+	return &SSOController{}
+}
+
+func (s *SSOController) Providers(c *gin.Context) {
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get identity providers successfully",
+		Data:       sso.Service().Providers(),
+	})
+}
+
+// Login returns the url of the provider the client has to open
+func (s *SSOController) Login(c *gin.Context) {
+	var req dto.SSOLoginReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	out, sErr := sso.Service().Begin(c.Request.Context(), req.Provider, "", req.DeviceName)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Continue at the identity provider",
+		Data: gin.H{
+			"authUrl":   out.AuthURL,
+			"expiresAt": out.ExpiresAt,
+		},
+	})
+}
+
+// Link starts the same flow for a logged in user, the callback links the identity to them
+func (s *SSOController) Link(c *gin.Context) {
+	var req dto.SSOLoginReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	out, sErr := sso.Service().Begin(c.Request.Context(), req.Provider, c.GetString("userID"), "")
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Continue at the identity provider",
+		Data: gin.H{
+			"authUrl":   out.AuthURL,
+			"expiresAt": out.ExpiresAt,
+		},
+	})
+}
+
+func (s *SSOController) Callback(c *gin.Context) {
+	var req dto.SSOCallbackReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	out, sErr := sso.Service().Callback(c.Request.Context(), &sdto.SSOCallbackInput{
+		Provider: req.Provider,
+		State:    req.State,
+		Code:     req.Code,
+		Device: sdto.SessionDevice{
+			UserAgent: c.Request.UserAgent(),
+			IP:        c.ClientIP(),
+		},
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	if out.Linked {
+		c.JSON(200, dto.CommonRes{
+			StatusCode: 0,
+			StatusMsg:  "Identity linked successfully",
+			Data: gin.H{
+				"userID":   out.UserID,
+				"provider": req.Provider,
+			},
+		})
+		return
+	}
+
+	if out.Challenge != nil {
+		c.JSON(200, dto.CommonRes{
+			StatusCode: 0,
+			StatusMsg:  "Two-factor authentication required",
+			Data: gin.H{
+				"twoFactorRequired": true,
+				"challengeToken":    out.Challenge.ChallengeToken,
+				"enrollRequired":    out.Challenge.EnrollRequired,
+				"expiresAt":         out.Challenge.ExpiresAt,
+			},
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Login successfully",
+		Data: gin.H{
+			"token":        out.Tokens.AccessToken,
+			"refreshToken": out.Tokens.RefreshToken,
+			"expiresAt":    out.Tokens.ExpiresAt,
+			"userID":       out.UserID,
+			"created":      out.Created,
+		},
+	})
+}
+
+func (s *SSOController) Identities(c *gin.Context) {
+	identities, sErr := sso.Service().Identities(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get identities successfully",
+		Data:       identities,
+	})
+}
+
+func (s *SSOController) Unlink(c *gin.Context) {
+	var req dto.UnlinkIdentityReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := sso.Service().Unlink(c.Request.Context(), req.UserID, req.Provider)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Identity unlinked successfully",
+	})
+}
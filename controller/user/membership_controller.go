@@ -0,0 +1,171 @@
+package user
+
+import (
+	"strconv"
+
+	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/service/membership"
+	"api.backend.xjco2913/service/user"
+	"github.com/gin-gonic/gin"
+)
+
+// Subscribe starts, renews, upgrades or downgrades the membership depending on the current one
+func (u *UserController) Subscribe(c *gin.Context) {
+	queryUserID := c.Query("userID")
+	membershipTypeStr := c.Query("membershipType")
+
+	membershipType, err := strconv.Atoi(membershipTypeStr)
+	if err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong membership type",
+		})
+		return
+	}
+
+	record, serviceErr := membership.Service().Subscribe(c.Request.Context(), queryUserID, int32(membershipType))
+	if serviceErr != nil {
+		c.JSON(serviceErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  serviceErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Subscribe successfully",
+		Data:       record,
+	})
+}
+
+func (u *UserController) CancelByID(c *gin.Context) {
+	queryUserID := c.Query("userID")
+
+	record, serviceErr := membership.Service().Cancel(c.Request.Context(), queryUserID)
+	if serviceErr != nil {
+		c.JSON(serviceErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  serviceErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Cancel subscription successfully",
+		Data:       record,
+	})
+}
+
+func (u *UserController) GetMembership(c *gin.Context) {
+	res, sErr := membership.Service().Get(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get membership successfully",
+		Data:       res,
+	})
+}
+
+func (u *UserController) MembershipPlans(c *gin.Context) {
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get membership plans successfully",
+		Data:       membership.Service().Plans(),
+	})
+}
+
+func (u *UserController) SetAutoRenew(c *gin.Context) {
+	var req dto.AutoRenewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := membership.Service().SetAutoRenew(c.Request.Context(), c.Query("userID"), *req.AutoRenew)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Update auto renew successfully",
+	})
+}
+
+// RedeemVoucher grants the membership of a voucher code, on top of the current membership
+func (u *UserController) RedeemVoucher(c *gin.Context) {
+	var req dto.RedeemVoucherReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	record, sErr := membership.Service().RedeemVoucher(c.Request.Context(), c.Query("userID"), req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Redeem voucher successfully",
+		Data:       record,
+	})
+}
+
+func (u *UserController) StartTrial(c *gin.Context) {
+	record, sErr := membership.Service().StartTrial(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Start free trial successfully",
+		Data:       record,
+	})
+}
+
+// Credits returns the credit balance, the referral code and the credit ledger
+func (u *UserController) Credits(c *gin.Context) {
+	res, sErr := user.Service().Credits(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get credits successfully",
+		Data:       res,
+	})
+}
@@ -0,0 +1,157 @@
+package user
+
+import (
+	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/twofactor"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactor completes a login that was answered with a challenge
+func (u *UserController) LoginTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	out, sErr := twofactor.Service().CompleteLogin(c.Request.Context(), req.ChallengeToken, false, req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+			Data: gin.H{
+				"remaining_attempts": sErr.Get("remaining_attempts"),
+			},
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Login successfully",
+		Data: gin.H{
+			"token":        out.Tokens.AccessToken,
+			"refreshToken": out.Tokens.RefreshToken,
+			"expiresAt":    out.Tokens.ExpiresAt,
+			"userID":       out.SubjectID,
+		},
+	})
+}
+
+func (u *UserController) SetupTwoFactor(c *gin.Context) {
+	out, sErr := twofactor.Service().Setup(c.Request.Context(), c.GetString("userID"), false)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Scan the provisioning uri and confirm with a code",
+		Data:       twoFactorSetupData(out),
+	})
+}
+
+func (u *UserController) EnableTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	codes, sErr := twofactor.Service().Enable(c.Request.Context(), c.GetString("userID"), req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Two-factor authentication enabled",
+		Data: gin.H{
+			"recoveryCodes": codes,
+		},
+	})
+}
+
+func (u *UserController) DisableTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := twofactor.Service().Disable(c.Request.Context(), c.GetString("userID"), false, req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Two-factor authentication disabled",
+	})
+}
+
+func (u *UserController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	codes, sErr := twofactor.Service().RegenerateRecoveryCodes(c.Request.Context(), c.GetString("userID"), req.Code)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Recovery codes regenerated",
+		Data: gin.H{
+			"recoveryCodes": codes,
+		},
+	})
+}
+
+func twoFactorChallengeData(challenge *sdto.TwoFactorChallenge) gin.H {
+	return gin.H{
+		"twoFactorRequired": true,
+		"challengeToken":    challenge.ChallengeToken,
+		"enrollRequired":    challenge.EnrollRequired,
+		"expiresAt":         challenge.ExpiresAt,
+	}
+}
+
+func twoFactorSetupData(out *sdto.TwoFactorSetupOutput) gin.H {
+	return gin.H{
+		"secret":          out.Secret,
+		"provisioningUri": out.ProvisioningURI,
+	}
+}
@@ -9,12 +9,18 @@ import (
 	"time"
 
 	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/middleware"
+	"api.backend.xjco2913/service/achievement"
 	"api.backend.xjco2913/service/friend"
 	"api.backend.xjco2913/service/notify"
+	"api.backend.xjco2913/service/privacy"
 	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/stats"
 	"api.backend.xjco2913/service/user"
 	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/zlog"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type UserController struct{}
@@ -43,11 +49,13 @@ func (u *UserController) SignUp(c *gin.Context) {
 	}
 
 	err := user.Service().Create(c.Request.Context(), &sdto.CreateUserInput{
-		Username: req.Username,
-		Password: req.Password,
-		Gender:   *req.Gender,
-		Region:   req.Region,
-		Birthday: req.Birthday,
+		Username:     req.Username,
+		Password:     req.Password,
+		Email:        req.Email,
+		Gender:       *req.Gender,
+		Region:       req.Region,
+		Birthday:     req.Birthday,
+		ReferralCode: req.ReferralCode,
 	})
 	if err != nil {
 		c.JSON(400, dto.CommonRes{
@@ -63,6 +71,7 @@ func (u *UserController) SignUp(c *gin.Context) {
 		Data: gin.H{
 			"userInfo": gin.H{
 				"username": req.Username,
+				"email":    req.Email,
 				"gender":   req.Gender,
 				"birthday": req.Birthday,
 				"region":   req.Region,
@@ -84,6 +93,11 @@ func (u *UserController) Login(c *gin.Context) {
 	out, err := user.Service().Authenticate(c.Request.Context(), &sdto.AuthenticateInput{
 		Username: req.Username,
 		Password: req.Password,
+		Device: sdto.SessionDevice{
+			DeviceName: req.DeviceName,
+			UserAgent:  c.Request.UserAgent(),
+			IP:         c.ClientIP(),
+		},
 	})
 	if err != nil {
 		data := gin.H{
@@ -92,6 +106,10 @@ func (u *UserController) Login(c *gin.Context) {
 		if t, ok := err.Get("lock_expires").(time.Time); ok {
 			data["lock_expires"] = t.Unix()
 		}
+		if reason, ok := err.Get("ban_reason").(string); ok {
+			data["ban_reason"] = reason
+			data["ban_expires"] = err.Get("ban_expires")
+		}
 
 		c.JSON(err.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -101,6 +119,15 @@ func (u *UserController) Login(c *gin.Context) {
 		return
 	}
 
+	if out.Challenge != nil {
+		c.JSON(200, dto.CommonRes{
+			StatusCode: 0,
+			StatusMsg:  "Two-factor authentication required",
+			Data:       twoFactorChallengeData(out.Challenge),
+		})
+		return
+	}
+
 	followerCount, err := friend.Service().GetFollowerCount(c.Request.Context(), out.UserID)
 	if err != nil {
 		c.JSON(err.Code(), dto.CommonRes{
@@ -132,7 +159,9 @@ func (u *UserController) Login(c *gin.Context) {
 		StatusCode: 0,
 		StatusMsg:  "Login successfully",
 		Data: gin.H{
-			"token": out.Token,
+			"token":        out.Token,
+			"refreshToken": out.RefreshToken,
+			"expiresAt":    out.ExpiresAt,
 			"userInfo": gin.H{
 				"username":           req.Username,
 				"gender":             out.Gender,
@@ -148,15 +177,6 @@ func (u *UserController) Login(c *gin.Context) {
 }
 
 func (u *UserController) GetAll(c *gin.Context) {
-	isAdmin, exists := c.Get("isAdmin")
-	if !exists || !isAdmin.(bool) {
-		c.JSON(403, dto.CommonRes{
-			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
-		})
-		return
-	}
-
 	users, err := user.Service().GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(err.Code(), dto.CommonRes{
@@ -192,23 +212,11 @@ func (u *UserController) GetAll(c *gin.Context) {
 	})
 }
 
+// GetByID returns the profile of any user, as far as the privacy settings of the user allow
 func (u *UserController) GetByID(c *gin.Context) {
 	userID := c.Query("userID")
 
-	currentUserID, currentUserExists := c.Get("userID")
-	isAdmin, isAdminExists := c.Get("isAdmin")
-
-	// Check if the current user is an administrator,
-	// otherwise check if the requested userID is the same as the current userID.
-	if !isAdminExists || !currentUserExists || !isAdmin.(bool) && userID != currentUserID.(string) {
-		c.JSON(403, dto.CommonRes{
-			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
-		})
-		return
-	}
-
-	userDetail, serviceErr := user.Service().GetByID(c.Request.Context(), userID)
+	userDetail, serviceErr := user.Service().GetByID(c.Request.Context(), middleware.Viewer(c), userID)
 	if serviceErr != nil {
 		c.JSON(serviceErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -217,7 +225,7 @@ func (u *UserController) GetByID(c *gin.Context) {
 		return
 	}
 
-	followerCount, err := friend.Service().GetFollowerCount(c.Request.Context(), currentUserID.(string))
+	followerCount, err := friend.Service().GetFollowerCount(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(err.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -226,7 +234,7 @@ func (u *UserController) GetByID(c *gin.Context) {
 		return
 	}
 
-	followingCount, err := friend.Service().GetFollowingCount(c.Request.Context(), currentUserID.(string))
+	followingCount, err := friend.Service().GetFollowingCount(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(err.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -235,7 +243,7 @@ func (u *UserController) GetByID(c *gin.Context) {
 		return
 	}
 
-	newNotificationCnt, err := notify.Service().UnreadCount(context.Background(), userID)
+	badges, err := achievement.Service().Badges(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(err.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -245,18 +253,35 @@ func (u *UserController) GetByID(c *gin.Context) {
 	}
 
 	responseData := gin.H{
-		"userId":             userDetail.UserID,
-		"username":           userDetail.Username,
-		"avatarUrl":          userDetail.AvatarURL,
-		"isOrganiser":        userDetail.IsOrganiser,
-		"gender":             userDetail.Gender,
-		"birthday":           userDetail.Birthday,
-		"region":             userDetail.Region,
-		"membershipTime":     userDetail.MembershipTime,
-		"membershipType":     userDetail.MembershipType,
-		"followers":          followerCount.Count,
-		"followings":         followingCount.Count,
-		"newNotificationCnt": newNotificationCnt,
+		"userId":      userDetail.UserID,
+		"username":    userDetail.Username,
+		"avatarUrl":   userDetail.AvatarURL,
+		"isOrganiser": userDetail.IsOrganiser,
+		"gender":      userDetail.Gender,
+		"birthday":    userDetail.Birthday,
+		"region":      userDetail.Region,
+		"followers":   followerCount.Count,
+		"followings":  followingCount.Count,
+		"badges":      badges,
+	}
+
+	if !userDetail.Restricted {
+		newNotificationCnt, err := notify.Service().UnreadCount(context.Background(), userID)
+		if err != nil {
+			c.JSON(err.Code(), dto.CommonRes{
+				StatusCode: -1,
+				StatusMsg:  err.Error(),
+			})
+			return
+		}
+
+		responseData["email"] = userDetail.Email
+		responseData["emailVerified"] = userDetail.EmailVerified
+		responseData["membershipTime"] = userDetail.MembershipTime
+		responseData["membershipType"] = userDetail.MembershipType
+		responseData["credits"] = userDetail.Credits
+		responseData["referralCode"] = userDetail.ReferralCode
+		responseData["newNotificationCnt"] = newNotificationCnt
 	}
 
 	c.JSON(200, dto.CommonRes{
@@ -266,46 +291,183 @@ func (u *UserController) GetByID(c *gin.Context) {
 	})
 }
 
-func (u *UserController) DeleteByID(c *gin.Context) {
-	userID := c.Query("userID")
+// GetPrivacy returns the privacy settings of the user
+func (u *UserController) GetPrivacy(c *gin.Context) {
+	res, sErr := privacy.Service().Get(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get privacy settings successfully",
+		Data:       res,
+	})
+}
 
-	isAdmin, exists := c.Get("isAdmin")
-	if !exists || !isAdmin.(bool) {
-		c.JSON(403, dto.CommonRes{
+func (u *UserController) UpdatePrivacy(c *gin.Context) {
+	var req dto.UpdatePrivacyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
+			StatusMsg:  "Wrong params: " + err.Error(),
 		})
 		return
 	}
 
-	serviceErr := user.Service().DeleteByID(c.Request.Context(), userID)
-	if serviceErr != nil {
-		c.JSON(serviceErr.Code(), dto.CommonRes{
+	res, sErr := privacy.Service().Update(c.Request.Context(), c.Query("userID"), &sdto.UpdatePrivacyInput{
+		ProfileVisibility: req.ProfileVisibility,
+		HideBirthday:      req.HideBirthday,
+		HideRoutes:        req.HideRoutes,
+		FollowPolicy:      req.FollowPolicy,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  serviceErr.Error(),
+			StatusMsg:  sErr.Error(),
 		})
 		return
 	}
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Delete user(s) successfully",
+		StatusMsg:  "Update privacy settings successfully",
+		Data:       res,
 	})
 }
 
-func (u *UserController) BanByID(c *gin.Context) {
-	userID := c.Query("userID")
+// ListPrivacyZones returns the areas cut out of the routes of the user shown to others
+func (u *UserController) ListPrivacyZones(c *gin.Context) {
+	res, sErr := privacy.Service().Zones(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get privacy zones successfully",
+		Data:       res,
+	})
+}
+
+func (u *UserController) CreatePrivacyZone(c *gin.Context) {
+	var req dto.PrivacyZoneReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	res, sErr := privacy.Service().CreateZone(c.Request.Context(), &sdto.PrivacyZoneInput{
+		UserID:    c.Query("userID"),
+		Name:      req.Name,
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		Radius:    req.Radius,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Create privacy zone successfully",
+		Data:       res,
+	})
+}
+
+func (u *UserController) DeletePrivacyZone(c *gin.Context) {
+	zoneID, err := strconv.Atoi(c.Query("zoneID"))
+	if err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong zone id",
+		})
+		return
+	}
 
-	isAdmin, exists := c.Get("isAdmin")
-	if !exists || !isAdmin.(bool) {
-		c.JSON(403, dto.CommonRes{
+	sErr := privacy.Service().DeleteZone(c.Request.Context(), c.Query("userID"), int32(zoneID))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
+			StatusMsg:  sErr.Error(),
 		})
 		return
 	}
 
-	serviceErr := user.Service().BanByID(c.Request.Context(), userID)
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Delete privacy zone successfully",
+	})
+}
+
+// Stats returns the ride totals and series of any user whose profile the viewer may see
+func (u *UserController) Stats(c *gin.Context) {
+	var req dto.StatsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	res, sErr := stats.Service().Get(c.Request.Context(), &sdto.StatsInput{
+		Viewer:   middleware.Viewer(c),
+		UserID:   req.UserID,
+		Timezone: req.Timezone,
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get user stats successfully",
+		Data:       res,
+	})
+}
+
+// Achievements returns the badges and personal records of any user whose profile the viewer may see
+func (u *UserController) Achievements(c *gin.Context) {
+	res, sErr := achievement.Service().Get(c.Request.Context(), middleware.Viewer(c), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get achievements successfully",
+		Data:       res,
+	})
+}
+
+func (u *UserController) DeleteByID(c *gin.Context) {
+	userID := c.Query("userID")
+
+	serviceErr := user.Service().DeleteByID(c.Request.Context(), userID, c.GetString("adminID"))
 	if serviceErr != nil {
 		c.JSON(serviceErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -316,23 +478,95 @@ func (u *UserController) BanByID(c *gin.Context) {
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Ban User(s) successfully",
+		StatusMsg:  "Deletion of user(s) scheduled",
 	})
 }
 
-func (u *UserController) UnbanByID(c *gin.Context) {
+// DeleteAccount lets users delete their own account, it is logged out right away
+// and the data is deleted in the background
+func (u *UserController) DeleteAccount(c *gin.Context) {
+	var req dto.DeleteAccountReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	status, sErr := user.Service().DeleteAccount(c.Request.Context(), c.GetString("userID"), req.Password)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(202, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Account deletion scheduled",
+		Data:       status,
+	})
+}
+
+func (u *UserController) DeletionStatus(c *gin.Context) {
+	status, sErr := user.Service().DeletionStatus(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get account deletion successfully",
+		Data:       status,
+	})
+}
+
+// ExportData streams a zip of everything stored about the user
+func (u *UserController) ExportData(c *gin.Context) {
+	resp, sErr := user.Service().ExportData(c.Request.Context(), c.Query("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	c.Header("Content-Type", "application/zip")
+	c.Status(200)
+
+	if err := resp.WriteTo(c.Request.Context(), c.Writer); err != nil {
+		// the headers are sent already, the client sees a truncated archive
+		zlog.Error("Error while writing data export", zap.String("userID", c.Query("userID")), zap.Error(err))
+		c.Abort()
+	}
+}
+
+func (u *UserController) BanByID(c *gin.Context) {
 	userID := c.Query("userID")
 
-	isAdmin, exists := c.Get("isAdmin")
-	if !exists || !isAdmin.(bool) {
-		c.JSON(403, dto.CommonRes{
+	var req dto.BanUserReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
+			StatusMsg:  "Wrong params: " + err.Error(),
 		})
 		return
 	}
 
-	serviceErr := user.Service().UnbanByID(c.Request.Context(), userID)
+	serviceErr := user.Service().BanByID(c.Request.Context(), &sdto.BanInput{
+		UserIDs:  userID,
+		AdminID:  c.GetString("adminID"),
+		Reason:   req.Reason,
+		Duration: time.Duration(req.Duration) * time.Second,
+	})
 	if serviceErr != nil {
 		c.JSON(serviceErr.Code(), dto.CommonRes{
 			StatusCode: -1,
@@ -343,44 +577,76 @@ func (u *UserController) UnbanByID(c *gin.Context) {
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Unban user(s) successfully",
+		StatusMsg:  "Ban User(s) successfully",
 	})
 }
 
-func (u *UserController) IsBanned(c *gin.Context) {
+func (u *UserController) UnbanByID(c *gin.Context) {
 	userID := c.Query("userID")
 
-	isAdmin, exists := c.Get("isAdmin")
-	if !exists || !isAdmin.(bool) {
-		c.JSON(403, dto.CommonRes{
+	serviceErr := user.Service().UnbanByID(c.Request.Context(), userID, c.GetString("adminID"))
+	if serviceErr != nil {
+		c.JSON(serviceErr.Code(), dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
+			StatusMsg:  serviceErr.Error(),
 		})
 		return
 	}
 
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Unban user(s) successfully",
+	})
+}
+
+func (u *UserController) IsBanned(c *gin.Context) {
+	userID := c.Query("userID")
+
 	isBanned := user.Service().IsBanned(c.Request.Context(), userID)
 
+	data := gin.H{
+		"userId":   userID,
+		"isBanned": isBanned,
+	}
+	if isBanned {
+		if ban, sErr := user.Service().ActiveBan(c.Request.Context(), userID); sErr == nil && ban != nil {
+			data["ban"] = ban
+		}
+	}
+
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
 		StatusMsg:  "Check status successfully",
-		Data: gin.H{
-			"userId":   userID,
-			"isBanned": isBanned,
-		},
+		Data:       data,
 	})
 }
 
-func (u *UserController) GetAllStatus(c *gin.Context) {
-	isAdmin, exists := c.Get("isAdmin")
-	if !exists || !isAdmin.(bool) {
-		c.JSON(403, dto.CommonRes{
+func (u *UserController) AppealBan(c *gin.Context) {
+	var req dto.BanAppealReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
+			StatusMsg:  "Wrong params: " + err.Error(),
 		})
 		return
 	}
 
+	serviceErr := user.Service().AppealBan(c.Request.Context(), req.BanID, req.Note)
+	if serviceErr != nil {
+		c.JSON(serviceErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  serviceErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Record appeal successfully",
+	})
+}
+
+func (u *UserController) GetAllStatus(c *gin.Context) {
 	userStatusList, serviceErr := user.Service().GetAllStatus(c.Request.Context())
 	if serviceErr != nil {
 		c.JSON(serviceErr.Code(), dto.CommonRes{
@@ -400,19 +666,6 @@ func (u *UserController) GetAllStatus(c *gin.Context) {
 func (u *UserController) UpdateByID(c *gin.Context) {
 	userID := c.Query("userID")
 
-	currentUserID, currentUserExists := c.Get("userID")
-	isAdmin, isAdminExists := c.Get("isAdmin")
-
-	// Check if the current user is an administrator,
-	// otherwise check if the requested userID is the same as the current userID.
-	if !isAdminExists || !currentUserExists || (!isAdmin.(bool) && userID != currentUserID.(string)) {
-		c.JSON(403, dto.CommonRes{
-			StatusCode: -1,
-			StatusMsg:  "Forbidden: Only admins can access this resource",
-		})
-		return
-	}
-
 	var req dto.UserUpdateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, dto.CommonRes{
@@ -435,6 +688,7 @@ func (u *UserController) UpdateByID(c *gin.Context) {
 		Gender:   req.Gender,
 		Birthday: req.Birthday,
 		Region:   req.Region,
+		Email:    req.Email,
 	}
 
 	serviceErr := user.Service().UpdateByID(c.Request.Context(), userID, input)
@@ -452,154 +706,318 @@ func (u *UserController) UpdateByID(c *gin.Context) {
 	})
 }
 
-func (u *UserController) Subscribe(c *gin.Context) {
-	queryUserID := c.Query("userID")
-	membershipTypeStr := c.Query("membershipType")
-
-	currentUserID, currentUserExists := c.Get("userID")
-	if !currentUserExists || queryUserID != currentUserID.(string) {
-		c.JSON(403, dto.CommonRes{
+func (u *UserController) UploadAvatar(c *gin.Context) {
+	userId := c.PostForm("userId")
+	avatarFileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: UserID mismatch",
+			StatusMsg:  fmt.Sprintf("Bad avatar file header: %s", err.Error()),
 		})
 		return
 	}
 
-	membershipType, err := strconv.Atoi(membershipTypeStr)
+	avatarFile, err := avatarFileHeader.Open()
 	if err != nil {
 		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Wrong membership type",
+			StatusMsg:  fmt.Sprintf("Failed to get avatar file: %s", err.Error()),
+		})
+		return
+	}
+	defer avatarFile.Close()
+
+	avatarBuf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(avatarBuf, avatarFile); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  fmt.Sprintf("Failed copy avatar data: %s", err.Error()),
 		})
 		return
 	}
 
-	serviceErr := user.Service().Subscribe(c.Request.Context(), queryUserID, membershipType)
-	if serviceErr != nil {
-		c.JSON(serviceErr.Code(), dto.CommonRes{
+	errx := user.Service().UploadAvatar(c.Request.Context(), sdto.UploadAvatarInput{
+		UserId:     userId,
+		AvatarData: avatarBuf.Bytes(),
+	})
+	if errx != nil {
+		c.JSON(errx.Code(), dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  serviceErr.Error(),
+			StatusMsg:  errx.Error(),
 		})
 		return
 	}
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Subscribe successfully",
+		StatusMsg:  "Upload avatar successfully",
 	})
 }
 
-func (u *UserController) CancelByID(c *gin.Context) {
-	queryUserID := c.Query("userID")
+func (u *UserController) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
 
-	currentUserID, currentUserExists := c.Get("userID")
-	if !currentUserExists || queryUserID != currentUserID.(string) {
-		c.JSON(403, dto.CommonRes{
+	res, sErr := user.Service().RefreshToken(c.Request.Context(), req.RefreshToken, &sdto.SessionDevice{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: UserID mismatch",
+			StatusMsg:  sErr.Error(),
 		})
 		return
 	}
 
-	serviceErr := user.Service().CancelByID(c.Request.Context(), queryUserID)
-	if serviceErr != nil {
-		c.JSON(serviceErr.Code(), dto.CommonRes{
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Refresh token successfully",
+		Data: gin.H{
+			"newToken":     res.NewToken,
+			"refreshToken": res.NewRefreshToken,
+			"expiresAt":    res.ExpiresAt,
+		},
+	})
+}
+
+func (u *UserController) Logout(c *gin.Context) {
+	sErr := user.Service().Logout(
+		c.Request.Context(),
+		c.GetString("userID"),
+		c.GetString("sessionID"),
+		c.GetString("tokenID"),
+		c.GetTime("tokenExp"),
+	)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  serviceErr.Error(),
+			StatusMsg:  sErr.Error(),
 		})
 		return
 	}
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Cancel subscription successfully",
+		StatusMsg:  "Logout successfully",
 	})
 }
 
-func (u *UserController) UploadAvatar(c *gin.Context) {
-	userId := c.PostForm("userId")
-	avatarFileHeader, err := c.FormFile("avatar")
-	if err != nil {
+func (u *UserController) LogoutAll(c *gin.Context) {
+	sErr := user.Service().LogoutAll(c.Request.Context(), c.GetString("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Logout from all devices successfully",
+	})
+}
+
+func (u *UserController) GetSessions(c *gin.Context) {
+	userID := c.Query("userID")
+
+	// only the caller's own list can contain the session of this request
+	currentSessionID := ""
+	if userID == c.GetString("userID") {
+		currentSessionID = c.GetString("sessionID")
+	}
+
+	sessions, sErr := user.Service().GetSessions(c.Request.Context(), userID, currentSessionID)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Get sessions successfully",
+		Data:       sessions,
+	})
+}
+
+func (u *UserController) RevokeSession(c *gin.Context) {
+	var req dto.RevokeSessionReq
+	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  fmt.Sprintf("Bad avatar file header: %s", err.Error()),
+			StatusMsg:  "Wrong params: " + err.Error(),
 		})
 		return
 	}
 
-	currentUserID, currentUserExists := c.Get("userID")
-	isAdmin, isAdminExists := c.Get("isAdmin")
+	sErr := user.Service().RevokeSession(c.Request.Context(), req.UserID, req.SessionID)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
 
-	// Check if the current user is an administrator,
-	// otherwise check if the requested userID is the same as the current userID.
-	if !isAdminExists || !currentUserExists || (!isAdmin.(bool) && userId != currentUserID.(string)) {
-		c.JSON(403, dto.CommonRes{
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Revoke session successfully",
+	})
+}
+
+func (u *UserController) RevokeAllSessions(c *gin.Context) {
+	userID := c.Query("userID")
+	if util.IsEmpty(userID) {
+		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Forbidden: You cannot access this resource",
+			StatusMsg:  "Missing userID",
 		})
 		return
 	}
 
-	avatarFile, err := avatarFileHeader.Open()
-	if err != nil {
+	sErr := user.Service().LogoutAll(c.Request.Context(), userID)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Revoke all sessions successfully",
+	})
+}
+
+func (u *UserController) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  fmt.Sprintf("Failed to get avatar file: %s", err.Error()),
+			StatusMsg:  "Wrong params: " + err.Error(),
 		})
 		return
 	}
-	defer avatarFile.Close()
 
-	avatarBuf := bytes.NewBuffer(nil)
-	if _, err := io.Copy(avatarBuf, avatarFile); err != nil {
+	sErr := user.Service().ChangePassword(
+		c.Request.Context(),
+		c.GetString("userID"),
+		c.GetString("sessionID"),
+		req.OldPassword,
+		req.NewPassword,
+	)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Change password successfully",
+	})
+}
+
+func (u *UserController) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  fmt.Sprintf("Failed copy avatar data: %s", err.Error()),
+			StatusMsg:  "Wrong params: " + err.Error(),
 		})
 		return
 	}
 
-	errx := user.Service().UploadAvatar(c.Request.Context(), sdto.UploadAvatarInput{
-		UserId:     userId,
-		AvatarData: avatarBuf.Bytes(),
+	sErr := user.Service().ForgotPassword(c.Request.Context(), req.Account)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "If the account exists, a reset mail has been sent",
 	})
-	if errx != nil {
-		c.JSON(errx.Code(), dto.CommonRes{
+}
+
+func (u *UserController) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  errx.Error(),
+			StatusMsg:  "Wrong params: " + err.Error(),
+		})
+		return
+	}
+
+	sErr := user.Service().ResetPassword(c.Request.Context(), req.Token, req.NewPassword)
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
 		})
 		return
 	}
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Upload avatar successfully",
+		StatusMsg:  "Reset password successfully",
 	})
 }
 
-func (u *UserController) RefreshToken(c *gin.Context) {
-	userID := c.GetString("userID")
-	if util.IsEmpty(userID) {
-		c.JSON(403, dto.CommonRes{
+func (u *UserController) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, dto.CommonRes{
 			StatusCode: -1,
-			StatusMsg:  "Missing userID in token",
+			StatusMsg:  "Wrong params: " + err.Error(),
 		})
 		return
 	}
 
-	res, sErr := user.Service().RefreshToken(c.Request.Context(), userID)
+	sErr := user.Service().VerifyEmail(c.Request.Context(), req.Token)
 	if sErr != nil {
 		c.JSON(sErr.Code(), dto.CommonRes{
 			StatusCode: -1,
 			StatusMsg:  sErr.Error(),
 		})
+		return
 	}
 
 	c.JSON(200, dto.CommonRes{
 		StatusCode: 0,
-		StatusMsg:  "Refresh token successfully",
-		Data: gin.H{
-			"newToken": res.NewToken,
-		},
+		StatusMsg:  "Verify email successfully",
+	})
+}
+
+func (u *UserController) ResendVerification(c *gin.Context) {
+	sErr := user.Service().SendVerification(c.Request.Context(), c.GetString("userID"))
+	if sErr != nil {
+		c.JSON(sErr.Code(), dto.CommonRes{
+			StatusCode: -1,
+			StatusMsg:  sErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, dto.CommonRes{
+		StatusCode: 0,
+		StatusMsg:  "Verification mail sent",
 	})
 }
@@ -0,0 +1,227 @@
+package dao
+
+import (
+	"context"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+	"gorm.io/gen/field"
+)
+
+// Queries used to export and to erase everything an account owns
+
+func GetLikesByUserID(ctx context.Context, userID string) ([]*model.Like, error) {
+	l := query.Use(DB).Like
+
+	return l.WithContext(ctx).Where(l.UserID.Eq(userID)).Find()
+}
+
+func GetCommentsByAuthorID(ctx context.Context, authorID string) ([]*model.Comment, error) {
+	c := query.Use(DB).Comment
+
+	return c.WithContext(ctx).Where(c.AuthorID.Eq(authorID)).Order(c.CreatedAt.Asc()).Find()
+}
+
+func GetActivityUsersByUserID(ctx context.Context, userID string) ([]*model.ActivityUser, error) {
+	a := query.Use(DB).ActivityUser
+
+	return a.WithContext(ctx).Where(a.UserID.Eq(userID)).Find()
+}
+
+// GetNotificationsByUserID returns the notifications the user sent or received
+func GetNotificationsByUserID(ctx context.Context, userID string) ([]*model.Notification, error) {
+	n := query.Use(DB).Notification
+
+	return n.WithContext(ctx).Where(field.Or(n.ReceiverID.Eq(userID), n.SenderID.Eq(userID))).Find()
+}
+
+// DeleteMomentCascade deletes a moment together with its likes, comments with their history, attachments and route,
+// the media objects have to be removed from minio by the caller
+func DeleteMomentCascade(ctx context.Context, moment *model.Moment) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		if _, err := tx.Like.WithContext(ctx).Where(tx.Like.MomentID.Eq(moment.MomentID)).Delete(); err != nil {
+			return err
+		}
+		var commentIds []string
+		if err := tx.Comment.WithContext(ctx).Where(tx.Comment.MomentID.Eq(moment.MomentID)).Pluck(tx.Comment.CommentID, &commentIds); err != nil {
+			return err
+		}
+		if err := deleteComments(ctx, tx, commentIds); err != nil {
+			return err
+		}
+		if _, err := tx.MomentAttachment.WithContext(ctx).Where(tx.MomentAttachment.MomentID.Eq(moment.MomentID)).Delete(); err != nil {
+			return err
+		}
+		if _, err := tx.Moment.WithContext(ctx).Where(tx.Moment.ID.Eq(moment.ID)).Delete(); err != nil {
+			return err
+		}
+		if moment.RouteID != nil {
+			if _, err := tx.GPSRoute.WithContext(ctx).Where(tx.GPSRoute.ID.Eq(*moment.RouteID)).Delete(); err != nil {
+				return err
+			}
+
+			r := tx.Ride
+			_, err := r.WithContext(ctx).Where(
+				r.UserID.Eq(moment.AuthorID),
+				r.Source.Eq(RIDE_MOMENT),
+				r.RefID.Eq(moment.MomentID),
+			).Delete()
+			if err != nil {
+				return err
+			}
+			if err := refreshUserStats(ctx, tx, moment.AuthorID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func DeleteLikesByUserID(ctx context.Context, userID string) error {
+	l := query.Use(DB).Like
+
+	_, err := l.WithContext(ctx).Where(l.UserID.Eq(userID)).Delete()
+
+	return err
+}
+
+// DeleteCommentsByAuthorID deletes the comments of the author, with the replies of others
+// under them and their history
+func DeleteCommentsByAuthorID(ctx context.Context, authorID string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		c := tx.Comment
+
+		var ids []string
+		if err := c.WithContext(ctx).Where(c.AuthorID.Eq(authorID)).Pluck(c.CommentID, &ids); err != nil {
+			return err
+		}
+
+		return deleteComments(ctx, tx, ids)
+	})
+}
+
+// DeleteFollowsByUserID deletes the follows and follow requests of the user in both directions,
+// the follow counts of the other users go down with them
+func DeleteFollowsByUserID(ctx context.Context, userID string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		f, r := tx.Follow, tx.FollowRequest
+		db := f.WithContext(ctx).UnderlyingDB()
+
+		err := db.Exec(
+			`UPDATE users u JOIN follow f ON f.followingId = u.userId AND f.userId = ?
+			SET u.followerCount = GREATEST(u.followerCount - 1, 0)`,
+			userID,
+		).Error
+		if err != nil {
+			return err
+		}
+		err = db.Exec(
+			`UPDATE users u JOIN follow f ON f.userId = u.userId AND f.followingId = ?
+			SET u.followingCount = GREATEST(u.followingCount - 1, 0)`,
+			userID,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.WithContext(ctx).Where(field.Or(f.UserID.Eq(userID), f.FollowingID.Eq(userID))).Delete(); err != nil {
+			return err
+		}
+		_, err = r.WithContext(ctx).Where(field.Or(r.RequesterID.Eq(userID), r.TargetID.Eq(userID))).Delete()
+
+		return err
+	})
+}
+
+// DeleteNotificationsByUserID deletes the notifications the user sent or received
+// and the routes that were shared through them
+func DeleteNotificationsByUserID(ctx context.Context, userID string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		n := tx.Notification
+		cond := field.Or(n.ReceiverID.Eq(userID), n.SenderID.Eq(userID))
+
+		notifications, err := n.WithContext(ctx).Where(cond).Find()
+		if err != nil {
+			return err
+		}
+
+		var routeIDs []int32
+		for _, notification := range notifications {
+			if notification.RouteID != nil {
+				routeIDs = append(routeIDs, *notification.RouteID)
+			}
+		}
+
+		if _, err := n.WithContext(ctx).Where(cond).Delete(); err != nil {
+			return err
+		}
+		if len(routeIDs) > 0 {
+			if _, err := tx.GPSRoute.WithContext(ctx).Where(tx.GPSRoute.ID.In(routeIDs...)).Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AnonymiseActivityUsers moves the sign ups of the user to placeholder, so that the fees
+// still count towards the revenue of the activities, and deletes the uploaded routes
+func AnonymiseActivityUsers(ctx context.Context, userID string, placeholder string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		a := tx.ActivityUser
+
+		activityUsers, err := a.WithContext(ctx).Where(a.UserID.Eq(userID)).Find()
+		if err != nil {
+			return err
+		}
+
+		var routeIDs []int32
+		for _, activityUser := range activityUsers {
+			if activityUser.RouteID != nil {
+				routeIDs = append(routeIDs, *activityUser.RouteID)
+			}
+		}
+
+		_, err = a.WithContext(ctx).Where(a.UserID.Eq(userID)).Updates(map[string]interface{}{
+			"userId":  placeholder,
+			"routeId": nil,
+		})
+		if err != nil {
+			return err
+		}
+		if len(routeIDs) > 0 {
+			if _, err := tx.GPSRoute.WithContext(ctx).Where(tx.GPSRoute.ID.In(routeIDs...)).Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReassignActivitiesCreator keeps the activities of a deleted organiser for their participants
+func ReassignActivitiesCreator(ctx context.Context, creatorID string, placeholder string) error {
+	a := query.Use(DB).Activity
+
+	_, err := a.WithContext(ctx).Where(a.CreatorID.Eq(creatorID)).Update(a.CreatorID, placeholder)
+
+	return err
+}
+
+func DeleteOrganiserByUserID(ctx context.Context, userID string) error {
+	o := query.Use(DB).Organiser
+
+	_, err := o.WithContext(ctx).Where(o.UserID.Eq(userID)).Delete()
+
+	return err
+}
+
+func DeleteBansByUserID(ctx context.Context, userID string) error {
+	b := query.Use(DB).Ban
+
+	_, err := b.WithContext(ctx).Where(b.UserID.Eq(userID)).Delete()
+
+	return err
+}
@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+)
+
+// GetLongestRide returns the longest ride of the user other than excludeID
+func GetLongestRide(ctx context.Context, userID string, excludeID int32) (*model.Ride, error) {
+	r := query.Use(DB).Ride
+
+	return bestRide(ctx, userID, excludeID, r.Distance.Desc())
+}
+
+// GetBiggestClimb returns the ride of the user other than excludeID that climbed most
+func GetBiggestClimb(ctx context.Context, userID string, excludeID int32) (*model.Ride, error) {
+	r := query.Use(DB).Ride
+
+	return bestRide(ctx, userID, excludeID, r.ElevationGain.Desc(), r.ElevationGain.Gt(0))
+}
+
+// GetFastest10K returns the ride of the user other than excludeID with the fastest 10 km
+func GetFastest10K(ctx context.Context, userID string, excludeID int32) (*model.Ride, error) {
+	r := query.Use(DB).Ride
+
+	return bestRide(ctx, userID, excludeID, r.Best10k.Asc(), r.Best10k.IsNotNull())
+}
+
+func bestRide(ctx context.Context, userID string, excludeID int32, order field.Expr, conds ...gen.Condition) (*model.Ride, error) {
+	r := query.Use(DB).Ride
+
+	return r.WithContext(ctx).Where(
+		r.UserID.Eq(userID),
+		r.ID.Neq(excludeID),
+	).Where(conds...).Order(order, r.RodeAt.Asc()).First()
+}
+
+// GetRideTimes returns when the user rode, in no particular order
+func GetRideTimes(ctx context.Context, userID string) ([]time.Time, error) {
+	r := query.Use(DB).Ride
+
+	var times []time.Time
+	err := r.WithContext(ctx).Where(r.UserID.Eq(userID)).Pluck(r.RodeAt, &times)
+
+	return times, err
+}
+
+// AwardBadge gives the badge to the user unless the user has it already
+func AwardBadge(ctx context.Context, badge *model.UserBadge) (bool, error) {
+	b := query.Use(DB).UserBadge
+
+	count, err := b.WithContext(ctx).Where(b.UserID.Eq(badge.UserID), b.Badge.Eq(badge.Badge)).Count()
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	if err := b.WithContext(ctx).Create(badge); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func GetBadgesByUserID(ctx context.Context, userID string) ([]*model.UserBadge, error) {
+	b := query.Use(DB).UserBadge
+
+	return b.WithContext(ctx).Where(b.UserID.Eq(userID)).Order(b.AwardedAt.Asc(), b.ID.Asc()).Find()
+}
+
+func DeleteBadgesByUserID(ctx context.Context, userID string) error {
+	b := query.Use(DB).UserBadge
+
+	_, err := b.WithContext(ctx).Where(b.UserID.Eq(userID)).Delete()
+
+	return err
+}
@@ -59,12 +59,6 @@ func ActivityUserCount(ctx context.Context) (int64, error) {
 	return au.WithContext(ctx).Count()
 }
 
-func GetFinalFeesByActivityId(ctx context.Context, activityId string) ([]*model.ActivityUser, error) {
-	au := query.Use(DB).ActivityUser
-
-	return au.WithContext(ctx).Where(au.ActivityID.Eq(activityId)).Find()
-}
-
 func UpdateActivityUserRoute(ctx context.Context, activityID, userID string, routeID int32) error {
 	a := query.Use(DB).ActivityUser
 
@@ -81,3 +75,9 @@ func UpdateActivityUserRoute(ctx context.Context, activityID, userID string, rou
 
 	return nil
 }
+
+func CountActivityUsersByUserID(ctx context.Context, userID string) (int64, error) {
+	a := query.Use(DB).ActivityUser
+
+	return a.WithContext(ctx).Where(a.UserID.Eq(userID)).Count()
+}
@@ -16,4 +16,23 @@ func FindAdminByName(ctx context.Context, name string) (*model.Admin, error) {
 	}
 
 	return admin, nil
-}
\ No newline at end of file
+}
+
+func UpdateAdminPassword(ctx context.Context, adminID string, hashedPassword string) error {
+	a := query.Use(DB).Admin
+
+	_, err := a.WithContext(ctx).Where(a.ID.Eq(adminID)).Update(a.Password, hashedPassword)
+
+	return err
+}
+
+func GetAdminByID(ctx context.Context, adminID string) (*model.Admin, error) {
+	a := query.Use(DB).Admin
+
+	admin, err := a.WithContext(ctx).Where(a.ID.Eq(adminID)).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
@@ -0,0 +1,91 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+)
+
+func CreateBan(ctx context.Context, ban *model.Ban) error {
+	return query.Use(DB).Ban.WithContext(ctx).Create(ban)
+}
+
+// activeBanConds match the bans in force at now
+func activeBanConds(b *query.Query, now time.Time) []gen.Condition {
+	return []gen.Condition{
+		b.Ban.LiftedAt.IsNull(),
+		b.Ban.StartAt.Lte(now),
+		field.Or(b.Ban.EndAt.IsNull(), b.Ban.EndAt.Gt(now)),
+	}
+}
+
+// GetActiveBan returns the ban in force for the user, the latest one if several overlap
+func GetActiveBan(ctx context.Context, userID string, now time.Time) (*model.Ban, error) {
+	q := query.Use(DB)
+	b := q.Ban
+
+	ban, err := b.WithContext(ctx).Where(activeBanConds(q, now)...).Where(b.UserID.Eq(userID)).Order(b.ID.Desc()).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return ban, nil
+}
+
+func GetActiveBans(ctx context.Context, now time.Time) ([]*model.Ban, error) {
+	q := query.Use(DB)
+	b := q.Ban
+
+	return b.WithContext(ctx).Where(activeBanConds(q, now)...).Order(b.ID).Find()
+}
+
+// GetBansByUserIDs returns the ban history of the users, every user when userIDs is empty
+func GetBansByUserIDs(ctx context.Context, userIDs []string) ([]*model.Ban, error) {
+	b := query.Use(DB).Ban
+
+	conds := []gen.Condition{}
+	if len(userIDs) > 0 {
+		conds = append(conds, b.UserID.In(userIDs...))
+	}
+
+	return b.WithContext(ctx).Where(conds...).Order(b.StartAt.Desc()).Find()
+}
+
+func GetBanByID(ctx context.Context, banID int32) (*model.Ban, error) {
+	b := query.Use(DB).Ban
+
+	ban, err := b.WithContext(ctx).Where(b.ID.Eq(banID)).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return ban, nil
+}
+
+// LiftActiveBans ends every ban of the user in force at now
+func LiftActiveBans(ctx context.Context, userID string, adminID string, now time.Time) (int64, error) {
+	q := query.Use(DB)
+	b := q.Ban
+
+	result, err := b.WithContext(ctx).Where(activeBanConds(q, now)...).Where(b.UserID.Eq(userID)).Updates(map[string]interface{}{
+		"liftedAt": now,
+		"liftedBy": adminID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+func UpdateBanAppeal(ctx context.Context, banID int32, note string) error {
+	b := query.Use(DB).Ban
+
+	_, err := b.WithContext(ctx).Where(b.ID.Eq(banID)).Update(b.AppealNote, note)
+
+	return err
+}
@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+	"gorm.io/gen/field"
+)
+
+const (
+	RELATION_BLOCK = "block"
+	RELATION_MUTE  = "mute"
+)
+
+// RelatedUserRow is a user the user blocked or muted
+type RelatedUserRow struct {
+	UserID    string     `gorm:"column:userId"`
+	Username  string     `gorm:"column:username"`
+	AvatarURL *string    `gorm:"column:avatarUrl"`
+	CreatedAt *time.Time `gorm:"column:createdAt"`
+}
+
+// BlockUser blocks the target, the follows between the two users and the pending requests
+// to follow go with it. False if the user blocked the target already.
+func BlockUser(ctx context.Context, userId, targetId string) (bool, error) {
+	var blocked bool
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		var err error
+		blocked, err = addRelation(ctx, tx, userId, targetId, RELATION_BLOCK)
+		if err != nil || !blocked {
+			return err
+		}
+
+		if _, err := unfollow(ctx, tx, userId, targetId); err != nil {
+			return err
+		}
+		if _, err := unfollow(ctx, tx, targetId, userId); err != nil {
+			return err
+		}
+
+		r := tx.FollowRequest
+		_, err = r.WithContext(ctx).Where(field.Or(
+			field.And(r.RequesterID.Eq(userId), r.TargetID.Eq(targetId)),
+			field.And(r.RequesterID.Eq(targetId), r.TargetID.Eq(userId)),
+		)).Delete()
+
+		return err
+	})
+
+	return blocked, err
+}
+
+// MuteUser mutes the target, false if the user muted the target already
+func MuteUser(ctx context.Context, userId, targetId string) (bool, error) {
+	var muted bool
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		var err error
+		muted, err = addRelation(ctx, tx, userId, targetId, RELATION_MUTE)
+		return err
+	})
+
+	return muted, err
+}
+
+func addRelation(ctx context.Context, tx *query.Query, userId, targetId, kind string) (bool, error) {
+	r := tx.UserRelation
+
+	count, err := r.WithContext(ctx).Where(r.UserID.Eq(userId), r.TargetID.Eq(targetId), r.Kind.Eq(kind)).Count()
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	err = r.WithContext(ctx).Create(&model.UserRelation{
+		UserID:   userId,
+		TargetID: targetId,
+		Kind:     kind,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteRelation unblocks or unmutes the target, false if there was nothing to undo
+func DeleteRelation(ctx context.Context, userId, targetId, kind string) (bool, error) {
+	r := query.Use(DB).UserRelation
+
+	result, err := r.WithContext(ctx).Where(r.UserID.Eq(userId), r.TargetID.Eq(targetId), r.Kind.Eq(kind)).Delete()
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// IsBlockedBetween reports whether either user blocked the other
+func IsBlockedBetween(ctx context.Context, userId, otherId string) (bool, error) {
+	r := query.Use(DB).UserRelation
+
+	count, err := r.WithContext(ctx).Where(
+		r.Kind.Eq(RELATION_BLOCK),
+		field.Or(
+			field.And(r.UserID.Eq(userId), r.TargetID.Eq(otherId)),
+			field.And(r.UserID.Eq(otherId), r.TargetID.Eq(userId)),
+		),
+	).Count()
+
+	return count > 0, err
+}
+
+// GetHiddenUserIDs returns the users the user blocked or was blocked by,
+// and with mutes also the users the user muted
+func GetHiddenUserIDs(ctx context.Context, userId string, withMutes bool) ([]string, error) {
+	stat := `SELECT targetId FROM user_relations WHERE userId = ? AND kind = ?
+		UNION
+		SELECT userId FROM user_relations WHERE targetId = ? AND kind = ?`
+	args := []interface{}{userId, RELATION_BLOCK, userId, RELATION_BLOCK}
+	if withMutes {
+		stat += `
+		UNION
+		SELECT targetId FROM user_relations WHERE userId = ? AND kind = ?`
+		args = append(args, userId, RELATION_MUTE)
+	}
+
+	var ids []string
+	err := DB.WithContext(ctx).Raw(stat, args...).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetRelatedUsers returns the users the user blocked or muted, newest first
+func GetRelatedUsers(ctx context.Context, userId, kind string) ([]*RelatedUserRow, error) {
+	var rows []*RelatedUserRow
+	err := DB.WithContext(ctx).Raw(
+		`SELECT u.userId AS userId, u.username AS username, u.avatarUrl AS avatarUrl, r.createdAt AS createdAt
+		FROM user_relations r
+		JOIN users u ON u.userId = r.targetId
+		WHERE r.userId = ? AND r.kind = ?
+		ORDER BY r.createdAt DESC, r.id DESC`,
+		userId, kind,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// DeleteRelationsByUserID deletes the blocks and mutes of the user in both directions
+func DeleteRelationsByUserID(ctx context.Context, userId string) error {
+	r := query.Use(DB).UserRelation
+
+	_, err := r.WithContext(ctx).Where(field.Or(r.UserID.Eq(userId), r.TargetID.Eq(userId))).Delete()
+
+	return err
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	"api.backend.xjco2913/dao/model"
 	"api.backend.xjco2913/dao/query"
@@ -16,9 +17,94 @@ func CreateNewComment(ctx context.Context, newComment *model.Comment) error {
 	return nil
 }
 
+func GetCommentByID(ctx context.Context, commentId string) (*model.Comment, error) {
+	c := query.Use(DB).Comment
+
+	return c.WithContext(ctx).Where(c.CommentID.Eq(commentId)).First()
+}
+
 // Order by createdAt Asc
 func GetCommentsByMomentId(ctx context.Context, momentId string) ([]*model.Comment, error) {
 	c := query.Use(DB).Comment
 
 	return c.WithContext(ctx).Where(c.MomentID.Eq(momentId)).Order(c.CreatedAt.Asc()).Find()
 }
+
+// GetCommentThreads returns a page of the top-level comments of the moment, oldest first,
+// together with how many there are
+func GetCommentThreads(ctx context.Context, momentId string, offset, limit int) ([]*model.Comment, int64, error) {
+	c := query.Use(DB).Comment
+
+	return c.WithContext(ctx).
+		Where(c.MomentID.Eq(momentId), c.ParentID.IsNull()).
+		Order(c.CreatedAt.Asc(), c.CommentID).
+		FindByPage(offset, limit)
+}
+
+// GetRepliesByParentIDs returns the replies in the threads of the comments, oldest first
+func GetRepliesByParentIDs(ctx context.Context, parentIds []string) ([]*model.Comment, error) {
+	if len(parentIds) == 0 {
+		return []*model.Comment{}, nil
+	}
+
+	c := query.Use(DB).Comment
+
+	return c.WithContext(ctx).Where(c.ParentID.In(parentIds...)).Order(c.CreatedAt.Asc(), c.CommentID).Find()
+}
+
+// EditComment replaces the content of the comment, keeping the earlier content in its history
+func EditComment(ctx context.Context, comment *model.Comment, content string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		err := tx.CommentEdit.WithContext(ctx).Create(&model.CommentEdit{
+			CommentID: comment.CommentID,
+			Content:   comment.Content,
+		})
+		if err != nil {
+			return err
+		}
+
+		c := tx.Comment
+		_, err = c.WithContext(ctx).Where(c.CommentID.Eq(comment.CommentID)).UpdateSimple(
+			c.Content.Value(content),
+			c.EditedAt.Value(time.Now()),
+		)
+
+		return err
+	})
+}
+
+// GetCommentEdits returns the earlier versions of the comment, oldest first
+func GetCommentEdits(ctx context.Context, commentId string) ([]*model.CommentEdit, error) {
+	e := query.Use(DB).CommentEdit
+
+	return e.WithContext(ctx).Where(e.CommentID.Eq(commentId)).Order(e.EditedAt.Asc(), e.ID.Asc()).Find()
+}
+
+// DeleteCommentThread deletes the comment and its history, a top-level comment takes its replies with it
+func DeleteCommentThread(ctx context.Context, comment *model.Comment) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		return deleteComments(ctx, tx, []string{comment.CommentID})
+	})
+}
+
+// deleteComments deletes the comments with the replies in their threads and the history of all of them
+func deleteComments(ctx context.Context, tx *query.Query, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	c, e := tx.Comment, tx.CommentEdit
+
+	var replyIds []string
+	if err := c.WithContext(ctx).Where(c.ParentID.In(ids...)).Pluck(c.CommentID, &replyIds); err != nil {
+		return err
+	}
+	ids = append(ids, replyIds...)
+
+	if _, err := e.WithContext(ctx).Where(e.CommentID.In(ids...)).Delete(); err != nil {
+		return err
+	}
+	_, err := c.WithContext(ctx).Where(c.CommentID.In(ids...)).Delete()
+
+	return err
+}
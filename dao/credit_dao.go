@@ -0,0 +1,185 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+	"gorm.io/gorm"
+)
+
+const (
+	CREDIT_REFERRER = "referrer"
+	CREDIT_REFERRED = "referred"
+	CREDIT_ACTIVITY = "activity"
+)
+
+func GetUserByReferralCode(ctx context.Context, referralCode string) (*model.User, error) {
+	u := query.Use(DB).User
+
+	return u.WithContext(ctx).Where(u.ReferralCode.Eq(referralCode)).First()
+}
+
+// SetReferralCode gives the user a referral code unless it has one already
+func SetReferralCode(ctx context.Context, userID string, referralCode string) (bool, error) {
+	u := query.Use(DB).User
+
+	result, err := u.WithContext(ctx).Where(u.UserID.Eq(userID), u.ReferralCode.IsNull()).Update(u.ReferralCode, referralCode)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// RewardReferral credits the referred user and the referrer once, the referrer is skipped
+// if the account does not exist anymore
+func RewardReferral(ctx context.Context, referredID string, referrerAmount int32, referredAmount int32) (bool, error) {
+	rewarded := false
+
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		u := tx.User
+
+		referred, err := u.WithContext(ctx).Where(
+			u.UserID.Eq(referredID),
+			u.ReferredBy.IsNotNull(),
+			u.ReferralRewarded.Is(false),
+		).First()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		result, err := u.WithContext(ctx).Where(
+			u.UserID.Eq(referredID),
+			u.ReferralRewarded.Is(false),
+		).Update(u.ReferralRewarded, true)
+		if err != nil || result.RowsAffected == 0 {
+			return err
+		}
+
+		if _, err := addCredits(ctx, tx, referredID, referredAmount, CREDIT_REFERRED, *referred.ReferredBy); err != nil {
+			return err
+		}
+		if _, err := addCredits(ctx, tx, *referred.ReferredBy, referrerAmount, CREDIT_REFERRER, referredID); err != nil {
+			return err
+		}
+
+		rewarded = true
+		return nil
+	})
+
+	return rewarded, err
+}
+
+// GetReferralsDueForMembership returns referred users who have not been rewarded and paid for a
+// membership at or before paidBefore without cancelling it within window of the payment.
+// paidActions are the membership actions that charge the user.
+func GetReferralsDueForMembership(ctx context.Context, paidBefore time.Time, window time.Duration, paidActions []string, cancelAction string, limit int) ([]string, error) {
+	var ids []string
+	err := DB.WithContext(ctx).Raw(
+		`SELECT u.userId FROM users u
+		WHERE u.referredBy IS NOT NULL AND u.referralRewarded = FALSE
+		AND EXISTS (
+			SELECT 1 FROM membership_histories h
+			WHERE h.userId = u.userId AND h.action IN ? AND h.amount > 0 AND h.createdAt <= ?
+			AND NOT EXISTS (
+				SELECT 1 FROM membership_histories c
+				WHERE c.userId = h.userId AND c.action = ?
+				AND c.createdAt >= h.createdAt AND c.createdAt <= h.createdAt + INTERVAL ? SECOND
+			)
+		)
+		LIMIT ?`,
+		paidActions, paidBefore, cancelAction, int64(window/time.Second), limit,
+	).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CreateActivityUserWithCredits signs the user up and spends credits on the fee, nothing is
+// created if the balance is too low
+func CreateActivityUserWithCredits(ctx context.Context, activityUser *model.ActivityUser, credits int32) (bool, error) {
+	created := false
+
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		if credits > 0 {
+			ok, err := addCredits(ctx, tx, activityUser.UserID, -credits, CREDIT_ACTIVITY, activityUser.ActivityID)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		if err := tx.ActivityUser.WithContext(ctx).Create(activityUser); err != nil {
+			return err
+		}
+
+		created = true
+		return nil
+	})
+
+	return created, err
+}
+
+// addCredits changes the balance and records the change, a balance never gets negative
+func addCredits(ctx context.Context, tx *query.Query, userID string, amount int32, reason string, refID string) (bool, error) {
+	if amount == 0 {
+		return true, nil
+	}
+
+	u := tx.User
+	result, err := u.WithContext(ctx).Where(
+		u.UserID.Eq(userID),
+		u.Credits.Gte(-amount),
+	).UpdateSimple(u.Credits.Add(amount))
+	if err != nil || result.RowsAffected == 0 {
+		return false, err
+	}
+
+	err = tx.CreditLedger.WithContext(ctx).Create(&model.CreditLedger{
+		UserID: userID,
+		Amount: amount,
+		Reason: reason,
+		RefID:  refID,
+	})
+
+	return err == nil, err
+}
+
+func GetCreditLedger(ctx context.Context, userID string) ([]*model.CreditLedger, error) {
+	c := query.Use(DB).CreditLedger
+
+	return c.WithContext(ctx).Where(c.UserID.Eq(userID)).Order(c.CreatedAt.Desc(), c.ID.Desc()).Find()
+}
+
+func DeleteCreditLedgerByUserID(ctx context.Context, userID string) error {
+	c := query.Use(DB).CreditLedger
+
+	_, err := c.WithContext(ctx).Where(c.UserID.Eq(userID)).Delete()
+
+	return err
+}
+
+// AnonymiseReferrals replaces a deleted user by placeholder in the referrals of other users
+func AnonymiseReferrals(ctx context.Context, userID string, placeholder string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		u, c := tx.User, tx.CreditLedger
+
+		if _, err := u.WithContext(ctx).Where(u.ReferredBy.Eq(userID)).Update(u.ReferredBy, placeholder); err != nil {
+			return err
+		}
+
+		_, err := c.WithContext(ctx).Where(
+			c.RefID.Eq(userID),
+			c.Reason.In(CREDIT_REFERRER, CREDIT_REFERRED),
+		).Update(c.RefID, placeholder)
+
+		return err
+	})
+}
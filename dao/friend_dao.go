@@ -3,100 +3,310 @@ package dao
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"api.backend.xjco2913/dao/model"
 	"api.backend.xjco2913/dao/query"
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
-func FollowById(ctx context.Context, followerId, followingId string) error {
-	f := query.Use(DB).Follow
+const (
+	FOLLOW_REQUEST_PENDING  = "pending"
+	FOLLOW_REQUEST_ACCEPTED = "accepted"
+	FOLLOW_REQUEST_DECLINED = "declined"
+)
 
-	newFollow := model.Follow{
-		UserID:      followerId,
-		FollowingID: followingId,
-	}
+// FollowRow is a user in a follow list, isFollowed tells whether the viewer follows the user
+type FollowRow struct {
+	UserID     string     `gorm:"column:userId"`
+	Username   string     `gorm:"column:username"`
+	AvatarURL  *string    `gorm:"column:avatarUrl"`
+	Region     string     `gorm:"column:region"`
+	FollowedAt *time.Time `gorm:"column:followedAt"`
+	IsFollowed bool       `gorm:"column:isFollowed"`
+}
 
-	return f.WithContext(ctx).Create(&newFollow)
+type FollowRequestRow struct {
+	RequestID   int32      `gorm:"column:requestId"`
+	UserID      string     `gorm:"column:userId"`
+	Username    string     `gorm:"column:username"`
+	AvatarURL   *string    `gorm:"column:avatarUrl"`
+	Region      string     `gorm:"column:region"`
+	RequestedAt *time.Time `gorm:"column:requestedAt"`
 }
 
-func GetFollowersByUserID(ctx context.Context, userId string) ([]*model.User, error) {
-	f := query.Use(DB).Follow
+// FollowById makes the follower follow the user and updates the counts of both,
+// false if the follower follows the user already
+func FollowById(ctx context.Context, followerId, followingId string) (bool, error) {
+	var followed bool
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		var err error
+		followed, err = follow(ctx, tx, followerId, followingId)
+		return err
+	})
 
-	// get follower
-	follows, err := f.WithContext(ctx).Where(f.FollowingID.Eq(userId)).Find()
-	if err != nil {
-		return nil, err
+	return followed, err
+}
+
+func follow(ctx context.Context, tx *query.Query, followerId, followingId string) (bool, error) {
+	f, u := tx.Follow, tx.User
+
+	count, err := f.WithContext(ctx).Where(f.UserID.Eq(followerId), f.FollowingID.Eq(followingId)).Count()
+	if err != nil || count > 0 {
+		return false, err
 	}
 
-	followers := make([]*model.User, len(follows))
-	for i, follow := range follows {
-		follower, err := GetUserByID(ctx, follow.UserID)
-		if err != nil {
-			return nil, err
+	// a concurrent request may have inserted the follow since the count
+	if err := f.WithContext(ctx).Create(&model.Follow{UserID: followerId, FollowingID: followingId}); err != nil {
+		if isDuplicateKey(err) {
+			return false, nil
 		}
+		return false, err
+	}
+	if _, err := u.WithContext(ctx).Where(u.UserID.Eq(followerId)).UpdateSimple(u.FollowingCount.Add(1)); err != nil {
+		return false, err
+	}
+	if _, err := u.WithContext(ctx).Where(u.UserID.Eq(followingId)).UpdateSimple(u.FollowerCount.Add(1)); err != nil {
+		return false, err
+	}
 
-		followers[i] = follower
+	return true, nil
+}
+
+// isDuplicateKey reports whether err is a violation of a unique key
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// UnfollowById removes the follow and updates the counts of both users,
+// false if the follower did not follow the user
+func UnfollowById(ctx context.Context, followerId, followingId string) (bool, error) {
+	var unfollowed bool
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		var err error
+		unfollowed, err = unfollow(ctx, tx, followerId, followingId)
+		return err
+	})
+
+	return unfollowed, err
+}
+
+func unfollow(ctx context.Context, tx *query.Query, followerId, followingId string) (bool, error) {
+	f, u := tx.Follow, tx.User
+
+	result, err := f.WithContext(ctx).Where(f.UserID.Eq(followerId), f.FollowingID.Eq(followingId)).Delete()
+	if err != nil || result.RowsAffected == 0 {
+		return false, err
 	}
 
-	return followers, nil
+	if _, err := u.WithContext(ctx).Where(u.UserID.Eq(followerId), u.FollowingCount.Gt(0)).UpdateSimple(u.FollowingCount.Sub(1)); err != nil {
+		return false, err
+	}
+	if _, err := u.WithContext(ctx).Where(u.UserID.Eq(followingId), u.FollowerCount.Gt(0)).UpdateSimple(u.FollowerCount.Sub(1)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetFollowersByUserID returns every follower of the user
+func GetFollowersByUserID(ctx context.Context, userId string) ([]*model.User, error) {
+	u, f := query.Use(DB).User, query.Use(DB).Follow
+
+	return u.WithContext(ctx).Join(f, f.UserID.EqCol(u.UserID)).Where(f.FollowingID.Eq(userId)).Find()
 }
 
+// GetFollowingsByUserID returns every user the user follows
 func GetFollowingsByUserID(ctx context.Context, userId string) ([]*model.User, error) {
+	u, f := query.Use(DB).User, query.Use(DB).Follow
+
+	return u.WithContext(ctx).Join(f, f.FollowingID.EqCol(u.UserID)).Where(f.UserID.Eq(userId)).Find()
+}
+
+// GetFollowerIDs returns the IDs of every follower of the user
+func GetFollowerIDs(ctx context.Context, userId string) ([]string, error) {
 	f := query.Use(DB).Follow
 
-	// get following
-	follows, err := f.WithContext(ctx).Where(f.UserID.Eq(userId)).Find()
+	var ids []string
+	err := f.WithContext(ctx).Where(f.FollowingID.Eq(userId)).Pluck(f.UserID, &ids)
+
+	return ids, err
+}
+
+// GetFollowersPage returns a page of the followers of the user, newest first
+func GetFollowersPage(ctx context.Context, userId, viewerId string, offset, limit int) ([]*FollowRow, error) {
+	return followPage(ctx, "JOIN users u ON u.userId = f.userId", "f.followingId = ?", userId, viewerId, offset, limit)
+}
+
+// GetFollowingsPage returns a page of the users the user follows, newest first
+func GetFollowingsPage(ctx context.Context, userId, viewerId string, offset, limit int) ([]*FollowRow, error) {
+	return followPage(ctx, "JOIN users u ON u.userId = f.followingId", "f.userId = ?", userId, viewerId, offset, limit)
+}
+
+// GetFriendsPage returns a page of the users the user follows who follow back, newest first
+func GetFriendsPage(ctx context.Context, userId, viewerId string, offset, limit int) ([]*FollowRow, error) {
+	return followPage(ctx,
+		`JOIN follow back ON back.userId = f.followingId AND back.followingId = f.userId
+		JOIN users u ON u.userId = f.followingId`,
+		"f.userId = ?",
+		userId, viewerId, offset, limit,
+	)
+}
+
+// followPage lists the users joined to the follow rows f in one query,
+// together with whether the viewer follows them
+func followPage(ctx context.Context, join string, where string, userId, viewerId string, offset, limit int) ([]*FollowRow, error) {
+	stat := fmt.Sprintf(
+		`SELECT u.userId AS userId, u.username AS username, u.avatarUrl AS avatarUrl, u.region AS region,
+			f.createdAt AS followedAt, vf.userId IS NOT NULL AS isFollowed
+		FROM follow f
+		%s
+		LEFT JOIN follow vf ON vf.userId = ? AND vf.followingId = u.userId
+		WHERE %s
+		ORDER BY f.createdAt DESC, u.userId
+		LIMIT ? OFFSET ?`,
+		join, where,
+	)
+
+	var rows []*FollowRow
+	err := DB.WithContext(ctx).Raw(stat, viewerId, userId, limit, offset).Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
 
-	followings := make([]*model.User, len(follows))
-	for i, follow := range follows {
-		following, err := GetUserByID(ctx, follow.FollowingID)
+	return rows, nil
+}
+
+// RequestFollow creates a pending request to follow the target, or reopens an earlier one.
+// False if a request is pending already.
+func RequestFollow(ctx context.Context, requesterId, targetId string) (*model.FollowRequest, bool, error) {
+	var (
+		request *model.FollowRequest
+		created bool
+	)
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		r := tx.FollowRequest
+
+		var err error
+		request, err = r.WithContext(ctx).Where(r.RequesterID.Eq(requesterId), r.TargetID.Eq(targetId)).First()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			request = &model.FollowRequest{
+				RequesterID: requesterId,
+				TargetID:    targetId,
+				Status:      FOLLOW_REQUEST_PENDING,
+			}
+			created = true
+			return r.WithContext(ctx).Create(request)
+		}
+		if err != nil || request.Status == FOLLOW_REQUEST_PENDING {
+			return err
+		}
+
+		now := time.Now()
+		_, err = r.WithContext(ctx).Where(r.ID.Eq(request.ID)).Updates(map[string]interface{}{
+			"status":    FOLLOW_REQUEST_PENDING,
+			"createdAt": now,
+			"decidedAt": nil,
+		})
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		followings[i] = following
-	}
+		request.Status, request.CreatedAt, request.DecidedAt = FOLLOW_REQUEST_PENDING, &now, nil
+		created = true
+		return nil
+	})
 
-	return followings, nil
+	return request, created, err
 }
 
-func GetFriendsByUserID(ctx context.Context, userId string) ([]*model.User, error) {
-	f := query.Use(DB).Follow
+func GetFollowRequestByID(ctx context.Context, requestId int32) (*model.FollowRequest, error) {
+	r := query.Use(DB).FollowRequest
+
+	return r.WithContext(ctx).Where(r.ID.Eq(requestId)).First()
+}
 
-	followers, err := f.WithContext(ctx).Where(f.FollowingID.Eq(userId)).Find()
+// DecideFollowRequest accepts or declines a pending request, accepting it also creates the follow.
+// False if the request is not pending anymore.
+func DecideFollowRequest(ctx context.Context, request *model.FollowRequest, status string) (bool, error) {
+	var decided bool
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		r := tx.FollowRequest
+
+		result, err := r.WithContext(ctx).Where(
+			r.ID.Eq(request.ID),
+			r.Status.Eq(FOLLOW_REQUEST_PENDING),
+		).Updates(map[string]interface{}{
+			"status":    status,
+			"decidedAt": time.Now(),
+		})
+		if err != nil || result.RowsAffected == 0 {
+			return err
+		}
+
+		if status == FOLLOW_REQUEST_ACCEPTED {
+			if _, err := follow(ctx, tx, request.RequesterID, request.TargetID); err != nil {
+				return err
+			}
+		}
+
+		decided = true
+		return nil
+	})
+
+	return decided, err
+}
+
+// CancelFollowRequest withdraws a pending request, false if there was none
+func CancelFollowRequest(ctx context.Context, requesterId, targetId string) (bool, error) {
+	r := query.Use(DB).FollowRequest
+
+	result, err := r.WithContext(ctx).Where(
+		r.RequesterID.Eq(requesterId),
+		r.TargetID.Eq(targetId),
+		r.Status.Eq(FOLLOW_REQUEST_PENDING),
+	).Delete()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	followings, err := f.WithContext(ctx).Where(f.UserID.Eq(userId)).Find()
+	return result.RowsAffected > 0, nil
+}
+
+// GetPendingFollowRequests returns a page of the requests waiting for the user, newest first
+func GetPendingFollowRequests(ctx context.Context, targetId string, offset, limit int) ([]*FollowRequestRow, error) {
+	var rows []*FollowRequestRow
+	err := DB.WithContext(ctx).Raw(
+		`SELECT r.id AS requestId, u.userId AS userId, u.username AS username, u.avatarUrl AS avatarUrl,
+			u.region AS region, r.createdAt AS requestedAt
+		FROM follow_requests r
+		JOIN users u ON u.userId = r.requesterId
+		WHERE r.targetId = ? AND r.status = ?
+		ORDER BY r.createdAt DESC, r.id DESC
+		LIMIT ? OFFSET ?`,
+		targetId, FOLLOW_REQUEST_PENDING, limit, offset,
+	).Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
 
-	followersMap := make(map[string]bool)
-	for _, follower := range followers {
-		followersMap[follower.UserID] = true
-	}
-
-	friends := []*model.User{}
-	for _, following := range followings {
-		if !followersMap[following.FollowingID] {
-			continue
-		}
+	return rows, nil
+}
 
-		friend, err := GetUserByID(ctx, following.FollowingID)
-		if err != nil {
-			return nil, err
-		}
+// HasPendingFollowRequest reports whether the requester waits for the target to decide
+func HasPendingFollowRequest(ctx context.Context, requesterId, targetId string) (bool, error) {
+	r := query.Use(DB).FollowRequest
 
-		friends = append(friends, friend)
-	}
+	count, err := r.WithContext(ctx).Where(
+		r.RequesterID.Eq(requesterId),
+		r.TargetID.Eq(targetId),
+		r.Status.Eq(FOLLOW_REQUEST_PENDING),
+	).Count()
 
-	return friends, nil
+	return count > 0, err
 }
 
 func CheckIsFollowed(ctx context.Context, userId, otherUserId string) (bool, error) {
@@ -112,4 +322,4 @@ func CheckIsFollowed(ctx context.Context, userId, otherUserId string) (bool, err
 	}
 
 	return true, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+)
+
+// errNotChanged rolls back a membership change whose conditions no longer hold
+var errNotChanged = errors.New("membership not changed")
+
+// ChangeMembership applies updates and records the change only if the membership of the user
+// is still fromType until fromTime, so that a sweep and a request cannot both change it
+func ChangeMembership(ctx context.Context, userID string, fromType int32, fromTime int64, updates map[string]interface{}, history *model.MembershipHistory) (bool, error) {
+	return changeMembership(ctx, userID, fromType, fromTime, updates, history, nil)
+}
+
+// StartTrial is ChangeMembership for the free trial, which every user can start once
+func StartTrial(ctx context.Context, userID string, fromType int32, fromTime int64, updates map[string]interface{}, history *model.MembershipHistory) (bool, error) {
+	updates["trialUsed"] = true
+
+	return changeMembership(ctx, userID, fromType, fromTime, updates, history, nil, query.Use(DB).User.TrialUsed.Is(false))
+}
+
+// RedeemVoucher is ChangeMembership that also marks the voucher as redeemed by the user,
+// nothing changes if the voucher was redeemed, revoked or has expired in the meantime
+func RedeemVoucher(ctx context.Context, voucher *model.Voucher, userID string, fromType int32, fromTime int64, updates map[string]interface{}, history *model.MembershipHistory) (bool, error) {
+	return changeMembership(ctx, userID, fromType, fromTime, updates, history, func(tx *query.Query) (bool, error) {
+		v, b := tx.Voucher, tx.VoucherBatch
+		now := time.Now()
+
+		redeemable := b.WithContext(ctx).Where(
+			b.ID.Eq(voucher.BatchID),
+			b.RevokedAt.IsNull(),
+		).Where(field.Or(b.RedeemBy.IsNull(), b.RedeemBy.Gt(now)))
+		if count, err := redeemable.Count(); err != nil || count == 0 {
+			return false, err
+		}
+
+		result, err := v.WithContext(ctx).Where(
+			v.ID.Eq(voucher.ID),
+			v.RedeemedBy.IsNull(),
+		).Updates(map[string]interface{}{
+			"redeemedBy": userID,
+			"redeemedAt": now,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		return result.RowsAffected > 0, nil
+	})
+}
+
+func changeMembership(ctx context.Context, userID string, fromType int32, fromTime int64, updates map[string]interface{}, history *model.MembershipHistory, also func(tx *query.Query) (bool, error), conds ...gen.Condition) (bool, error) {
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		u := tx.User
+
+		result, err := u.WithContext(ctx).Where(
+			u.UserID.Eq(userID),
+			u.MembershipType.Eq(fromType),
+			u.MembershipTime.Eq(fromTime),
+		).Where(conds...).Updates(updates)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return errNotChanged
+		}
+
+		if also != nil {
+			ok, err := also(tx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errNotChanged
+			}
+		}
+
+		return tx.MembershipHistory.WithContext(ctx).Create(history)
+	})
+	if errors.Is(err, errNotChanged) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func GetMembershipHistory(ctx context.Context, userID string) ([]*model.MembershipHistory, error) {
+	m := query.Use(DB).MembershipHistory
+
+	return m.WithContext(ctx).Where(m.UserID.Eq(userID)).Order(m.CreatedAt.Desc(), m.ID.Desc()).Find()
+}
+
+// GetExpiredMembers returns members whose membership ended at or before now
+func GetExpiredMembers(ctx context.Context, now int64, limit int) ([]*model.User, error) {
+	u := query.Use(DB).User
+
+	return u.WithContext(ctx).Where(
+		u.MembershipType.Neq(0),
+		u.MembershipTime.Lte(now),
+	).Order(u.MembershipTime.Asc()).Limit(limit).Find()
+}
+
+// GetMembersExpiringBetween returns members whose membership ends in (from, to]
+func GetMembersExpiringBetween(ctx context.Context, from int64, to int64) ([]*model.User, error) {
+	u := query.Use(DB).User
+
+	return u.WithContext(ctx).Where(
+		u.MembershipType.Neq(0),
+		u.MembershipTime.Gt(from),
+		u.MembershipTime.Lte(to),
+	).Find()
+}
+
+// AnonymiseMembershipHistory keeps the payments of a deleted user under placeholder
+func AnonymiseMembershipHistory(ctx context.Context, userID string, placeholder string) error {
+	m := query.Use(DB).MembershipHistory
+
+	_, err := m.WithContext(ctx).Where(m.UserID.Eq(userID)).Update(m.UserID, placeholder)
+
+	return err
+}
@@ -86,15 +86,9 @@ func DeleteActivityCover(ctx context.Context, coverName string) error {
 	return nil
 }
 
-func UploadMomentImage(ctx context.Context, momentImageName string, momentImageData []byte) error {
-	imageReader := bytes.NewReader(momentImageData)
-
-	err := UploadFile(ctx, MOMENT_BUCKET, momentImageName, imageReader, int64(len(momentImageData)), "image/jpeg")
-	if err != nil {
-		return err
-	}
-
-	return nil
+// UploadMomentMedia stores an image or video of a moment
+func UploadMomentMedia(ctx context.Context, objectName string, data []byte, contentType string) error {
+	return UploadFile(ctx, MOMENT_BUCKET, objectName, bytes.NewReader(data), int64(len(data)), contentType)
 }
 
 func GetMomentImageUrl(ctx context.Context, momentImageName string) (string, error) {
@@ -106,17 +100,6 @@ func GetMomentImageUrl(ctx context.Context, momentImageName string) (string, err
 	return imageUrl.String(), nil
 }
 
-func UploadMomentVideo(ctx context.Context, momentVideoName string, momentVideoData []byte) error {
-	videoReader := bytes.NewReader(momentVideoData)
-
-	err := UploadFile(ctx, MOMENT_BUCKET, momentVideoName, videoReader, int64(len(momentVideoData)), "video/mp4")
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func GetMomentVideoUrl(ctx context.Context, momentVideoName string) (string, error) {
 	videoUrl, err := GetObjectUrl(ctx, MOMENT_BUCKET, momentVideoName, 0)
 	if err != nil {
@@ -130,6 +113,17 @@ func RemoveObjectFromMoment(ctx context.Context, objectName string) error {
 	return minioClient.RemoveObject(ctx, MOMENT_BUCKET, objectName, minio.RemoveObjectOptions{})
 }
 
+// RemoveMomentMedia deletes the image and video objects of a moment
+func RemoveMomentMedia(ctx context.Context, objectNames ...string) error {
+	for _, objectName := range objectNames {
+		if err := RemoveObjectFromMoment(ctx, objectName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, contentType string) error {
 	_, err := minioClient.PutObject(ctx, bucketName, objectName, reader, objectSize, minio.PutObjectOptions{
 		ContentType: contentType,
@@ -153,3 +147,24 @@ func GetObjectUrl(ctx context.Context, bucketName, objectName string, exp time.D
 
 	return preSignedUrl, nil
 }
+
+// GetObject opens an object for reading, the caller has to close it
+func GetObject(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error) {
+	object, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// GetObject is lazy, stat makes a missing object fail here instead of on the first read
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// RemoveObject deletes an object, removing a missing object is not an error
+func RemoveObject(ctx context.Context, bucketName, objectName string) error {
+	return minioClient.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{})
+}
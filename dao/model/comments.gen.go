@@ -18,6 +18,8 @@ type Comment struct {
 	Content   string     `gorm:"column:content;not null" json:"content"`
 	CreatedAt *time.Time `gorm:"column:createdAt;default:CURRENT_TIMESTAMP" json:"createdAt"`
 	UpdatedAt *time.Time `gorm:"column:updatedAt;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+	ParentID  *string    `gorm:"column:parentId;comment:the top-level comment of the thread, null for top-level comments" json:"parentId"` // the top-level comment of the thread, null for top-level comments
+	EditedAt  *time.Time `gorm:"column:editedAt" json:"editedAt"`
 }
 
 // TableName Comment's table name
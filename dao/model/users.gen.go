@@ -12,19 +12,33 @@ const TableNameUser = "users"
 
 // User mapped from table <users>
 type User struct {
-	ID             int32      `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
-	UserID         string     `gorm:"column:userId;not null" json:"userId"`
-	AvatarURL      *string    `gorm:"column:avatarUrl" json:"avatarUrl"`
-	MembershipTime int64      `gorm:"column:membershipTime;not null;comment:membership expired time, a unix timestamp" json:"membershipTime"` // membership expired time, a unix timestamp
-	Gender         int32      `gorm:"column:gender;not null;comment:0 is male, 1 is female, 2 is prefer-not-to-say" json:"gender"`            // 0 is male, 1 is female, 2 is prefer-not-to-say
-	Region         string     `gorm:"column:region;not null" json:"region"`
-	Tags           *string    `gorm:"column:tags;comment:Multiple tags are separated using '|'" json:"tags"` // Multiple tags are separated using '|'
-	Birthday       *time.Time `gorm:"column:birthday" json:"birthday"`
-	CreatedAt      *time.Time `gorm:"column:createdAt;default:CURRENT_TIMESTAMP" json:"createdAt"`
-	UpdatedAt      *time.Time `gorm:"column:updatedAt;default:CURRENT_TIMESTAMP" json:"updatedAt"`
-	Username       string     `gorm:"column:username;not null" json:"username"`
-	Password       string     `gorm:"column:password;not null" json:"password"`
-	MembershipType int32      `gorm:"column:membershipType;not null;comment:0 is non-member, 1 is starter, 2 is premium" json:"membershipType"` // 0 is non-member, 1 is starter, 2 is premium
+	ID                int32      `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	UserID            string     `gorm:"column:userId;not null" json:"userId"`
+	AvatarURL         *string    `gorm:"column:avatarUrl" json:"avatarUrl"`
+	MembershipTime    int64      `gorm:"column:membershipTime;not null;comment:membership expired time, a unix timestamp" json:"membershipTime"` // membership expired time, a unix timestamp
+	Gender            int32      `gorm:"column:gender;not null;comment:0 is male, 1 is female, 2 is prefer-not-to-say" json:"gender"`            // 0 is male, 1 is female, 2 is prefer-not-to-say
+	Region            string     `gorm:"column:region;not null" json:"region"`
+	Tags              *string    `gorm:"column:tags;comment:Multiple tags are separated using '|'" json:"tags"` // Multiple tags are separated using '|'
+	Birthday          *time.Time `gorm:"column:birthday" json:"birthday"`
+	CreatedAt         *time.Time `gorm:"column:createdAt;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt         *time.Time `gorm:"column:updatedAt;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+	Username          string     `gorm:"column:username;not null" json:"username"`
+	Password          string     `gorm:"column:password;not null" json:"password"`
+	MembershipType    int32      `gorm:"column:membershipType;not null;comment:0 is non-member, 1 is starter, 2 is premium" json:"membershipType"` // 0 is non-member, 1 is starter, 2 is premium
+	Email             *string    `gorm:"column:email" json:"email"`
+	EmailVerified     bool       `gorm:"column:emailVerified;not null" json:"emailVerified"`
+	AutoRenew         bool       `gorm:"column:autoRenew;not null" json:"autoRenew"`
+	TrialUsed         bool       `gorm:"column:trialUsed;not null" json:"trialUsed"`
+	ReferralCode      *string    `gorm:"column:referralCode" json:"referralCode"`
+	ReferredBy        *string    `gorm:"column:referredBy;comment:userId of the referrer" json:"referredBy"`                                                   // userId of the referrer
+	ReferralRewarded  bool       `gorm:"column:referralRewarded;not null;comment:set once both sides have earned the referral reward" json:"referralRewarded"` // set once both sides have earned the referral reward
+	Credits           int32      `gorm:"column:credits;not null" json:"credits"`
+	ProfileVisibility int32      `gorm:"column:profileVisibility;not null;comment:0 is public, 1 is followers only, 2 is private" json:"profileVisibility"` // 0 is public, 1 is followers only, 2 is private
+	HideBirthday      bool       `gorm:"column:hideBirthday;not null" json:"hideBirthday"`
+	HideRoutes        bool       `gorm:"column:hideRoutes;not null;comment:routes of moments are only shown to the user" json:"hideRoutes"`                                       // routes of moments are only shown to the user
+	FollowPolicy      int32      `gorm:"column:followPolicy;not null;comment:0 is everyone may follow, 1 is nobody may follow, 2 is followers need approval" json:"followPolicy"` // 0 is everyone may follow, 1 is nobody may follow, 2 is followers need approval
+	FollowerCount     int32      `gorm:"column:followerCount;not null" json:"followerCount"`
+	FollowingCount    int32      `gorm:"column:followingCount;not null" json:"followingCount"`
 }
 
 // TableName User's table name
@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"context"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+)
+
+const (
+	ATTACHMENT_IMAGE = "image"
+	ATTACHMENT_VIDEO = "video"
+	ATTACHMENT_ROUTE = "route"
+)
+
+// CreateMomentWithAttachments creates the moment and its attachments in the order given,
+// the positions and the moment ID of the attachments are set here
+func CreateMomentWithAttachments(ctx context.Context, moment *model.Moment, attachments []*model.MomentAttachment) (int32, error) {
+	err := query.Use(DB).Transaction(func(tx *query.Query) error {
+		if err := tx.Moment.WithContext(ctx).Create(moment); err != nil {
+			return err
+		}
+		if len(attachments) == 0 {
+			return nil
+		}
+
+		for i, attachment := range attachments {
+			attachment.MomentID = moment.MomentID
+			attachment.Position = int32(i)
+		}
+
+		return tx.MomentAttachment.WithContext(ctx).Create(attachments...)
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return moment.ID, nil
+}
+
+// GetAttachmentsByMomentIDs returns the attachments of the moments, in order within each moment
+func GetAttachmentsByMomentIDs(ctx context.Context, momentIDs []string) ([]*model.MomentAttachment, error) {
+	if len(momentIDs) == 0 {
+		return []*model.MomentAttachment{}, nil
+	}
+
+	a := query.Use(DB).MomentAttachment
+
+	return a.WithContext(ctx).Where(a.MomentID.In(momentIDs...)).Order(a.MomentID, a.Position).Find()
+}
+
+// GetAttachmentObjects returns the names of the image and video objects of the moment
+func GetAttachmentObjects(ctx context.Context, momentID string) ([]string, error) {
+	a := query.Use(DB).MomentAttachment
+
+	var objects []string
+	err := a.WithContext(ctx).Where(a.MomentID.Eq(momentID), a.ObjectName.IsNotNull()).Pluck(a.ObjectName, &objects)
+
+	return objects, err
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"api.backend.xjco2913/dao/model"
@@ -26,6 +27,18 @@ func DeleteMomentByID(ctx context.Context, momentID int32) error {
 	return nil
 }
 
+// UpdateMomentContent replaces the text of the moment
+func UpdateMomentContent(ctx context.Context, momentID string, content string) error {
+	m := query.Use(DB).Moment
+
+	_, err := m.WithContext(ctx).Where(m.MomentID.Eq(momentID)).UpdateSimple(
+		m.Content.Value(content),
+		m.UpdatedAt.Value(time.Now()),
+	)
+
+	return err
+}
+
 func GetAllMoment(ctx context.Context) ([]*model.Moment, error) {
 	return query.Use(DB).WithContext(ctx).Moment.Find()
 }
@@ -72,3 +85,76 @@ func GetLatestMomentByUserID(ctx context.Context, userID string) (*model.Moment,
 
 	return moment, nil
 }
+
+// GetMomentsByMomentIDs returns the moments that still exist, in no particular order
+func GetMomentsByMomentIDs(ctx context.Context, momentIDs []string) ([]*model.Moment, error) {
+	if len(momentIDs) == 0 {
+		return []*model.Moment{}, nil
+	}
+
+	m := query.Use(DB).Moment
+
+	return m.WithContext(ctx).Where(m.MomentID.In(momentIDs...)).Find()
+}
+
+// GetTimelineMoments returns the moments of the user and of the users the user follows created before
+// the given time, newest first. Followed users with maxFollowers followers or more are left out,
+// unless maxFollowers is 0.
+func GetTimelineMoments(ctx context.Context, userID string, maxFollowers int32, before time.Time, limit int) ([]*model.Moment, error) {
+	return getTimelineMoments(ctx, userID, maxFollowers, "m.createdAt < ?", before, limit)
+}
+
+// GetTimelineMomentsSince is GetTimelineMoments for the moments created at or after the given time
+func GetTimelineMomentsSince(ctx context.Context, userID string, maxFollowers int32, since time.Time, limit int) ([]*model.Moment, error) {
+	return getTimelineMoments(ctx, userID, maxFollowers, "m.createdAt >= ?", since, limit)
+}
+
+func getTimelineMoments(ctx context.Context, userID string, maxFollowers int32, createdAtCond string, createdAt time.Time, limit int) ([]*model.Moment, error) {
+	popular := ""
+	args := []interface{}{createdAt, userID, userID}
+	if maxFollowers > 0 {
+		popular = "AND u.followerCount < ?"
+		args = append(args, maxFollowers)
+	}
+	args = append(args, limit)
+
+	stat := fmt.Sprintf(
+		`SELECT m.* FROM moments m
+		WHERE %s AND (m.authorId = ? OR m.authorId IN (
+			SELECT f.followingId FROM follow f
+			JOIN users u ON u.userId = f.followingId
+			WHERE f.userId = ? %s
+		))
+		ORDER BY m.createdAt DESC, m.id DESC
+		LIMIT ?`,
+		createdAtCond, popular,
+	)
+
+	var moments []*model.Moment
+	err := DB.WithContext(ctx).Raw(stat, args...).Scan(&moments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return moments, nil
+}
+
+// GetPopularMoments returns the moments created before the given time by the users the user follows
+// who have minFollowers followers or more, newest first
+func GetPopularMoments(ctx context.Context, userID string, minFollowers int32, before time.Time, limit int) ([]*model.Moment, error) {
+	var moments []*model.Moment
+	err := DB.WithContext(ctx).Raw(
+		`SELECT m.* FROM moments m
+		JOIN follow f ON f.followingId = m.authorId AND f.userId = ?
+		JOIN users u ON u.userId = m.authorId
+		WHERE u.followerCount >= ? AND m.createdAt < ?
+		ORDER BY m.createdAt DESC, m.id DESC
+		LIMIT ?`,
+		userID, minFollowers, before, limit,
+	).Scan(&moments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return moments, nil
+}
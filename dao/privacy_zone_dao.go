@@ -0,0 +1,44 @@
+package dao
+
+import (
+	"context"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+)
+
+func CreatePrivacyZone(ctx context.Context, zone *model.PrivacyZone) error {
+	return query.Use(DB).PrivacyZone.WithContext(ctx).Create(zone)
+}
+
+func GetPrivacyZonesByUserID(ctx context.Context, userID string) ([]*model.PrivacyZone, error) {
+	z := query.Use(DB).PrivacyZone
+
+	return z.WithContext(ctx).Where(z.UserID.Eq(userID)).Order(z.ID).Find()
+}
+
+func CountPrivacyZones(ctx context.Context, userID string) (int64, error) {
+	z := query.Use(DB).PrivacyZone
+
+	return z.WithContext(ctx).Where(z.UserID.Eq(userID)).Count()
+}
+
+// DeletePrivacyZone deletes the zone only if it belongs to the user
+func DeletePrivacyZone(ctx context.Context, userID string, zoneID int32) (bool, error) {
+	z := query.Use(DB).PrivacyZone
+
+	result, err := z.WithContext(ctx).Where(z.ID.Eq(zoneID), z.UserID.Eq(userID)).Delete()
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func DeletePrivacyZonesByUserID(ctx context.Context, userID string) error {
+	z := query.Use(DB).PrivacyZone
+
+	_, err := z.WithContext(ctx).Where(z.UserID.Eq(userID)).Delete()
+
+	return err
+}
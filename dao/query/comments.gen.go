@@ -33,6 +33,8 @@ func newComment(db *gorm.DB, opts ...gen.DOOption) comment {
 	_comment.Content = field.NewString(tableName, "content")
 	_comment.CreatedAt = field.NewTime(tableName, "createdAt")
 	_comment.UpdatedAt = field.NewTime(tableName, "updatedAt")
+	_comment.ParentID = field.NewString(tableName, "parentId")
+	_comment.EditedAt = field.NewTime(tableName, "editedAt")
 
 	_comment.fillFieldMap()
 
@@ -49,6 +51,8 @@ type comment struct {
 	Content   field.String
 	CreatedAt field.Time
 	UpdatedAt field.Time
+	ParentID  field.String // the top-level comment of the thread, null for top-level comments
+	EditedAt  field.Time
 
 	fieldMap map[string]field.Expr
 }
@@ -71,6 +75,8 @@ func (c *comment) updateTableName(table string) *comment {
 	c.Content = field.NewString(table, "content")
 	c.CreatedAt = field.NewTime(table, "createdAt")
 	c.UpdatedAt = field.NewTime(table, "updatedAt")
+	c.ParentID = field.NewString(table, "parentId")
+	c.EditedAt = field.NewTime(table, "editedAt")
 
 	c.fillFieldMap()
 
@@ -95,13 +101,15 @@ func (c *comment) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (c *comment) fillFieldMap() {
-	c.fieldMap = make(map[string]field.Expr, 6)
+	c.fieldMap = make(map[string]field.Expr, 8)
 	c.fieldMap["commentId"] = c.CommentID
 	c.fieldMap["authorId"] = c.AuthorID
 	c.fieldMap["momentId"] = c.MomentID
 	c.fieldMap["content"] = c.Content
 	c.fieldMap["createdAt"] = c.CreatedAt
 	c.fieldMap["updatedAt"] = c.UpdatedAt
+	c.fieldMap["parentId"] = c.ParentID
+	c.fieldMap["editedAt"] = c.EditedAt
 }
 
 func (c comment) clone(db *gorm.DB) comment {
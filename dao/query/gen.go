@@ -17,59 +17,107 @@ import (
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:           db,
-		Activity:     newActivity(db, opts...),
-		ActivityUser: newActivityUser(db, opts...),
-		Admin:        newAdmin(db, opts...),
-		Comment:      newComment(db, opts...),
-		Follow:       newFollow(db, opts...),
-		GPSRoute:     newGPSRoute(db, opts...),
-		Like:         newLike(db, opts...),
-		Log:          newLog(db, opts...),
-		Moment:       newMoment(db, opts...),
-		Notification: newNotification(db, opts...),
-		Organiser:    newOrganiser(db, opts...),
-		Tag:          newTag(db, opts...),
-		User:         newUser(db, opts...),
+		db:                db,
+		Activity:          newActivity(db, opts...),
+		ActivityUser:      newActivityUser(db, opts...),
+		Admin:             newAdmin(db, opts...),
+		Ban:               newBan(db, opts...),
+		Comment:           newComment(db, opts...),
+		CommentEdit:       newCommentEdit(db, opts...),
+		CreditLedger:      newCreditLedger(db, opts...),
+		Follow:            newFollow(db, opts...),
+		FollowRequest:     newFollowRequest(db, opts...),
+		GPSRoute:          newGPSRoute(db, opts...),
+		Like:              newLike(db, opts...),
+		Log:               newLog(db, opts...),
+		MembershipHistory: newMembershipHistory(db, opts...),
+		Moment:            newMoment(db, opts...),
+		MomentAttachment:  newMomentAttachment(db, opts...),
+		Notification:      newNotification(db, opts...),
+		Organiser:         newOrganiser(db, opts...),
+		PrivacyZone:       newPrivacyZone(db, opts...),
+		Ride:              newRide(db, opts...),
+		Setting:           newSetting(db, opts...),
+		Tag:               newTag(db, opts...),
+		TwoFactor:         newTwoFactor(db, opts...),
+		User:              newUser(db, opts...),
+		UserBadge:         newUserBadge(db, opts...),
+		UserIdentity:      newUserIdentity(db, opts...),
+		UserRelation:      newUserRelation(db, opts...),
+		UserStat:          newUserStat(db, opts...),
+		Voucher:           newVoucher(db, opts...),
+		VoucherBatch:      newVoucherBatch(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	Activity     activity
-	ActivityUser activityUser
-	Admin        admin
-	Comment      comment
-	Follow       follow
-	GPSRoute     gPSRoute
-	Like         like
-	Log          log
-	Moment       moment
-	Notification notification
-	Organiser    organiser
-	Tag          tag
-	User         user
+	Activity          activity
+	ActivityUser      activityUser
+	Admin             admin
+	Ban               ban
+	Comment           comment
+	CommentEdit       commentEdit
+	CreditLedger      creditLedger
+	Follow            follow
+	FollowRequest     followRequest
+	GPSRoute          gPSRoute
+	Like              like
+	Log               log
+	MembershipHistory membershipHistory
+	Moment            moment
+	MomentAttachment  momentAttachment
+	Notification      notification
+	Organiser         organiser
+	PrivacyZone       privacyZone
+	Ride              ride
+	Setting           setting
+	Tag               tag
+	TwoFactor         twoFactor
+	User              user
+	UserBadge         userBadge
+	UserIdentity      userIdentity
+	UserRelation      userRelation
+	UserStat          userStat
+	Voucher           voucher
+	VoucherBatch      voucherBatch
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:           db,
-		Activity:     q.Activity.clone(db),
-		ActivityUser: q.ActivityUser.clone(db),
-		Admin:        q.Admin.clone(db),
-		Comment:      q.Comment.clone(db),
-		Follow:       q.Follow.clone(db),
-		GPSRoute:     q.GPSRoute.clone(db),
-		Like:         q.Like.clone(db),
-		Log:          q.Log.clone(db),
-		Moment:       q.Moment.clone(db),
-		Notification: q.Notification.clone(db),
-		Organiser:    q.Organiser.clone(db),
-		Tag:          q.Tag.clone(db),
-		User:         q.User.clone(db),
+		db:                db,
+		Activity:          q.Activity.clone(db),
+		ActivityUser:      q.ActivityUser.clone(db),
+		Admin:             q.Admin.clone(db),
+		Ban:               q.Ban.clone(db),
+		Comment:           q.Comment.clone(db),
+		CommentEdit:       q.CommentEdit.clone(db),
+		CreditLedger:      q.CreditLedger.clone(db),
+		Follow:            q.Follow.clone(db),
+		FollowRequest:     q.FollowRequest.clone(db),
+		GPSRoute:          q.GPSRoute.clone(db),
+		Like:              q.Like.clone(db),
+		Log:               q.Log.clone(db),
+		MembershipHistory: q.MembershipHistory.clone(db),
+		Moment:            q.Moment.clone(db),
+		MomentAttachment:  q.MomentAttachment.clone(db),
+		Notification:      q.Notification.clone(db),
+		Organiser:         q.Organiser.clone(db),
+		PrivacyZone:       q.PrivacyZone.clone(db),
+		Ride:              q.Ride.clone(db),
+		Setting:           q.Setting.clone(db),
+		Tag:               q.Tag.clone(db),
+		TwoFactor:         q.TwoFactor.clone(db),
+		User:              q.User.clone(db),
+		UserBadge:         q.UserBadge.clone(db),
+		UserIdentity:      q.UserIdentity.clone(db),
+		UserRelation:      q.UserRelation.clone(db),
+		UserStat:          q.UserStat.clone(db),
+		Voucher:           q.Voucher.clone(db),
+		VoucherBatch:      q.VoucherBatch.clone(db),
 	}
 }
 
@@ -83,54 +131,102 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:           db,
-		Activity:     q.Activity.replaceDB(db),
-		ActivityUser: q.ActivityUser.replaceDB(db),
-		Admin:        q.Admin.replaceDB(db),
-		Comment:      q.Comment.replaceDB(db),
-		Follow:       q.Follow.replaceDB(db),
-		GPSRoute:     q.GPSRoute.replaceDB(db),
-		Like:         q.Like.replaceDB(db),
-		Log:          q.Log.replaceDB(db),
-		Moment:       q.Moment.replaceDB(db),
-		Notification: q.Notification.replaceDB(db),
-		Organiser:    q.Organiser.replaceDB(db),
-		Tag:          q.Tag.replaceDB(db),
-		User:         q.User.replaceDB(db),
+		db:                db,
+		Activity:          q.Activity.replaceDB(db),
+		ActivityUser:      q.ActivityUser.replaceDB(db),
+		Admin:             q.Admin.replaceDB(db),
+		Ban:               q.Ban.replaceDB(db),
+		Comment:           q.Comment.replaceDB(db),
+		CommentEdit:       q.CommentEdit.replaceDB(db),
+		CreditLedger:      q.CreditLedger.replaceDB(db),
+		Follow:            q.Follow.replaceDB(db),
+		FollowRequest:     q.FollowRequest.replaceDB(db),
+		GPSRoute:          q.GPSRoute.replaceDB(db),
+		Like:              q.Like.replaceDB(db),
+		Log:               q.Log.replaceDB(db),
+		MembershipHistory: q.MembershipHistory.replaceDB(db),
+		Moment:            q.Moment.replaceDB(db),
+		MomentAttachment:  q.MomentAttachment.replaceDB(db),
+		Notification:      q.Notification.replaceDB(db),
+		Organiser:         q.Organiser.replaceDB(db),
+		PrivacyZone:       q.PrivacyZone.replaceDB(db),
+		Ride:              q.Ride.replaceDB(db),
+		Setting:           q.Setting.replaceDB(db),
+		Tag:               q.Tag.replaceDB(db),
+		TwoFactor:         q.TwoFactor.replaceDB(db),
+		User:              q.User.replaceDB(db),
+		UserBadge:         q.UserBadge.replaceDB(db),
+		UserIdentity:      q.UserIdentity.replaceDB(db),
+		UserRelation:      q.UserRelation.replaceDB(db),
+		UserStat:          q.UserStat.replaceDB(db),
+		Voucher:           q.Voucher.replaceDB(db),
+		VoucherBatch:      q.VoucherBatch.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	Activity     *activityDo
-	ActivityUser *activityUserDo
-	Admin        *adminDo
-	Comment      *commentDo
-	Follow       *followDo
-	GPSRoute     *gPSRouteDo
-	Like         *likeDo
-	Log          *logDo
-	Moment       *momentDo
-	Notification *notificationDo
-	Organiser    *organiserDo
-	Tag          *tagDo
-	User         *userDo
+	Activity          *activityDo
+	ActivityUser      *activityUserDo
+	Admin             *adminDo
+	Ban               *banDo
+	Comment           *commentDo
+	CommentEdit       *commentEditDo
+	CreditLedger      *creditLedgerDo
+	Follow            *followDo
+	FollowRequest     *followRequestDo
+	GPSRoute          *gPSRouteDo
+	Like              *likeDo
+	Log               *logDo
+	MembershipHistory *membershipHistoryDo
+	Moment            *momentDo
+	MomentAttachment  *momentAttachmentDo
+	Notification      *notificationDo
+	Organiser         *organiserDo
+	PrivacyZone       *privacyZoneDo
+	Ride              *rideDo
+	Setting           *settingDo
+	Tag               *tagDo
+	TwoFactor         *twoFactorDo
+	User              *userDo
+	UserBadge         *userBadgeDo
+	UserIdentity      *userIdentityDo
+	UserRelation      *userRelationDo
+	UserStat          *userStatDo
+	Voucher           *voucherDo
+	VoucherBatch      *voucherBatchDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		Activity:     q.Activity.WithContext(ctx),
-		ActivityUser: q.ActivityUser.WithContext(ctx),
-		Admin:        q.Admin.WithContext(ctx),
-		Comment:      q.Comment.WithContext(ctx),
-		Follow:       q.Follow.WithContext(ctx),
-		GPSRoute:     q.GPSRoute.WithContext(ctx),
-		Like:         q.Like.WithContext(ctx),
-		Log:          q.Log.WithContext(ctx),
-		Moment:       q.Moment.WithContext(ctx),
-		Notification: q.Notification.WithContext(ctx),
-		Organiser:    q.Organiser.WithContext(ctx),
-		Tag:          q.Tag.WithContext(ctx),
-		User:         q.User.WithContext(ctx),
+		Activity:          q.Activity.WithContext(ctx),
+		ActivityUser:      q.ActivityUser.WithContext(ctx),
+		Admin:             q.Admin.WithContext(ctx),
+		Ban:               q.Ban.WithContext(ctx),
+		Comment:           q.Comment.WithContext(ctx),
+		CommentEdit:       q.CommentEdit.WithContext(ctx),
+		CreditLedger:      q.CreditLedger.WithContext(ctx),
+		Follow:            q.Follow.WithContext(ctx),
+		FollowRequest:     q.FollowRequest.WithContext(ctx),
+		GPSRoute:          q.GPSRoute.WithContext(ctx),
+		Like:              q.Like.WithContext(ctx),
+		Log:               q.Log.WithContext(ctx),
+		MembershipHistory: q.MembershipHistory.WithContext(ctx),
+		Moment:            q.Moment.WithContext(ctx),
+		MomentAttachment:  q.MomentAttachment.WithContext(ctx),
+		Notification:      q.Notification.WithContext(ctx),
+		Organiser:         q.Organiser.WithContext(ctx),
+		PrivacyZone:       q.PrivacyZone.WithContext(ctx),
+		Ride:              q.Ride.WithContext(ctx),
+		Setting:           q.Setting.WithContext(ctx),
+		Tag:               q.Tag.WithContext(ctx),
+		TwoFactor:         q.TwoFactor.WithContext(ctx),
+		User:              q.User.WithContext(ctx),
+		UserBadge:         q.UserBadge.WithContext(ctx),
+		UserIdentity:      q.UserIdentity.WithContext(ctx),
+		UserRelation:      q.UserRelation.WithContext(ctx),
+		UserStat:          q.UserStat.WithContext(ctx),
+		Voucher:           q.Voucher.WithContext(ctx),
+		VoucherBatch:      q.VoucherBatch.WithContext(ctx),
 	}
 }
 
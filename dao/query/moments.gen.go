@@ -30,8 +30,6 @@ func newMoment(db *gorm.DB, opts ...gen.DOOption) moment {
 	_moment.ID = field.NewInt32(tableName, "id")
 	_moment.AuthorID = field.NewString(tableName, "authorId")
 	_moment.Content = field.NewString(tableName, "content")
-	_moment.ImageURL = field.NewString(tableName, "imageUrl")
-	_moment.VideoURL = field.NewString(tableName, "videoUrl")
 	_moment.RouteID = field.NewInt32(tableName, "routeId")
 	_moment.CreatedAt = field.NewTime(tableName, "createdAt")
 	_moment.UpdatedAt = field.NewTime(tableName, "updatedAt")
@@ -49,8 +47,6 @@ type moment struct {
 	ID        field.Int32
 	AuthorID  field.String
 	Content   field.String
-	ImageURL  field.String
-	VideoURL  field.String
 	RouteID   field.Int32
 	CreatedAt field.Time
 	UpdatedAt field.Time
@@ -74,8 +70,6 @@ func (m *moment) updateTableName(table string) *moment {
 	m.ID = field.NewInt32(table, "id")
 	m.AuthorID = field.NewString(table, "authorId")
 	m.Content = field.NewString(table, "content")
-	m.ImageURL = field.NewString(table, "imageUrl")
-	m.VideoURL = field.NewString(table, "videoUrl")
 	m.RouteID = field.NewInt32(table, "routeId")
 	m.CreatedAt = field.NewTime(table, "createdAt")
 	m.UpdatedAt = field.NewTime(table, "updatedAt")
@@ -104,12 +98,10 @@ func (m *moment) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (m *moment) fillFieldMap() {
-	m.fieldMap = make(map[string]field.Expr, 9)
+	m.fieldMap = make(map[string]field.Expr, 7)
 	m.fieldMap["id"] = m.ID
 	m.fieldMap["authorId"] = m.AuthorID
 	m.fieldMap["content"] = m.Content
-	m.fieldMap["imageUrl"] = m.ImageURL
-	m.fieldMap["videoUrl"] = m.VideoURL
 	m.fieldMap["routeId"] = m.RouteID
 	m.fieldMap["createdAt"] = m.CreatedAt
 	m.fieldMap["updatedAt"] = m.UpdatedAt
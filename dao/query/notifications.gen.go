@@ -36,6 +36,8 @@ func newNotification(db *gorm.DB, opts ...gen.DOOption) notification {
 	_notification.CreatedAt = field.NewTime(tableName, "createdAt")
 	_notification.UpdatedAt = field.NewTime(tableName, "updatedAt")
 	_notification.OrgResult = field.NewInt32(tableName, "orgResult")
+	_notification.Content = field.NewString(tableName, "content")
+	_notification.RouteOwnerID = field.NewString(tableName, "routeOwnerId")
 
 	_notification.fillFieldMap()
 
@@ -50,11 +52,13 @@ type notification struct {
 	ReceiverID     field.String
 	SenderID       field.String
 	RouteID        field.Int32
-	Type           field.Int32 // 1 is admin notification, 2 is route notification
+	Type           field.Int32 // 1 is admin notification, 2 is route notification, 3 is system notification
 	Status         field.Int32 // -1 is unread, 1 is read
 	CreatedAt      field.Time
 	UpdatedAt      field.Time
 	OrgResult      field.Int32 // -1 is refused, 1 is agreed
+	Content        field.String
+	RouteOwnerID   field.String // the user whose route is shared, null if unknown
 
 	fieldMap map[string]field.Expr
 }
@@ -80,6 +84,8 @@ func (n *notification) updateTableName(table string) *notification {
 	n.CreatedAt = field.NewTime(table, "createdAt")
 	n.UpdatedAt = field.NewTime(table, "updatedAt")
 	n.OrgResult = field.NewInt32(table, "orgResult")
+	n.Content = field.NewString(table, "content")
+	n.RouteOwnerID = field.NewString(table, "routeOwnerId")
 
 	n.fillFieldMap()
 
@@ -108,7 +114,7 @@ func (n *notification) GetFieldByName(fieldName string) (field.OrderExpr, bool)
 }
 
 func (n *notification) fillFieldMap() {
-	n.fieldMap = make(map[string]field.Expr, 9)
+	n.fieldMap = make(map[string]field.Expr, 11)
 	n.fieldMap["notificationId"] = n.NotificationID
 	n.fieldMap["receiverId"] = n.ReceiverID
 	n.fieldMap["senderId"] = n.SenderID
@@ -118,6 +124,8 @@ func (n *notification) fillFieldMap() {
 	n.fieldMap["createdAt"] = n.CreatedAt
 	n.fieldMap["updatedAt"] = n.UpdatedAt
 	n.fieldMap["orgResult"] = n.OrgResult
+	n.fieldMap["content"] = n.Content
+	n.fieldMap["routeOwnerId"] = n.RouteOwnerID
 }
 
 func (n notification) clone(db *gorm.DB) notification {
@@ -40,6 +40,20 @@ func newUser(db *gorm.DB, opts ...gen.DOOption) user {
 	_user.Username = field.NewString(tableName, "username")
 	_user.Password = field.NewString(tableName, "password")
 	_user.MembershipType = field.NewInt32(tableName, "membershipType")
+	_user.Email = field.NewString(tableName, "email")
+	_user.EmailVerified = field.NewBool(tableName, "emailVerified")
+	_user.AutoRenew = field.NewBool(tableName, "autoRenew")
+	_user.TrialUsed = field.NewBool(tableName, "trialUsed")
+	_user.ReferralCode = field.NewString(tableName, "referralCode")
+	_user.ReferredBy = field.NewString(tableName, "referredBy")
+	_user.ReferralRewarded = field.NewBool(tableName, "referralRewarded")
+	_user.Credits = field.NewInt32(tableName, "credits")
+	_user.ProfileVisibility = field.NewInt32(tableName, "profileVisibility")
+	_user.HideBirthday = field.NewBool(tableName, "hideBirthday")
+	_user.HideRoutes = field.NewBool(tableName, "hideRoutes")
+	_user.FollowPolicy = field.NewInt32(tableName, "followPolicy")
+	_user.FollowerCount = field.NewInt32(tableName, "followerCount")
+	_user.FollowingCount = field.NewInt32(tableName, "followingCount")
 
 	_user.fillFieldMap()
 
@@ -49,20 +63,34 @@ func newUser(db *gorm.DB, opts ...gen.DOOption) user {
 type user struct {
 	userDo userDo
 
-	ALL            field.Asterisk
-	ID             field.Int32
-	UserID         field.String
-	AvatarURL      field.String
-	MembershipTime field.Int64 // membership expired time, a unix timestamp
-	Gender         field.Int32 // 0 is male, 1 is female, 2 is prefer-not-to-say
-	Region         field.String
-	Tags           field.String // Multiple tags are separated using '|'
-	Birthday       field.Time
-	CreatedAt      field.Time
-	UpdatedAt      field.Time
-	Username       field.String
-	Password       field.String
-	MembershipType field.Int32 // 0 is non-member, 1 is starter, 2 is premium
+	ALL               field.Asterisk
+	ID                field.Int32
+	UserID            field.String
+	AvatarURL         field.String
+	MembershipTime    field.Int64 // membership expired time, a unix timestamp
+	Gender            field.Int32 // 0 is male, 1 is female, 2 is prefer-not-to-say
+	Region            field.String
+	Tags              field.String // Multiple tags are separated using '|'
+	Birthday          field.Time
+	CreatedAt         field.Time
+	UpdatedAt         field.Time
+	Username          field.String
+	Password          field.String
+	MembershipType    field.Int32 // 0 is non-member, 1 is starter, 2 is premium
+	Email             field.String
+	EmailVerified     field.Bool
+	AutoRenew         field.Bool
+	TrialUsed         field.Bool
+	ReferralCode      field.String
+	ReferredBy        field.String // userId of the referrer
+	ReferralRewarded  field.Bool   // set once both sides have earned the referral reward
+	Credits           field.Int32
+	ProfileVisibility field.Int32 // 0 is public, 1 is followers only, 2 is private
+	HideBirthday      field.Bool
+	HideRoutes        field.Bool  // routes of moments are only shown to the user
+	FollowPolicy      field.Int32 // 0 is everyone may follow, 1 is nobody may follow, 2 is followers need approval
+	FollowerCount     field.Int32
+	FollowingCount    field.Int32
 
 	fieldMap map[string]field.Expr
 }
@@ -92,6 +120,20 @@ func (u *user) updateTableName(table string) *user {
 	u.Username = field.NewString(table, "username")
 	u.Password = field.NewString(table, "password")
 	u.MembershipType = field.NewInt32(table, "membershipType")
+	u.Email = field.NewString(table, "email")
+	u.EmailVerified = field.NewBool(table, "emailVerified")
+	u.AutoRenew = field.NewBool(table, "autoRenew")
+	u.TrialUsed = field.NewBool(table, "trialUsed")
+	u.ReferralCode = field.NewString(table, "referralCode")
+	u.ReferredBy = field.NewString(table, "referredBy")
+	u.ReferralRewarded = field.NewBool(table, "referralRewarded")
+	u.Credits = field.NewInt32(table, "credits")
+	u.ProfileVisibility = field.NewInt32(table, "profileVisibility")
+	u.HideBirthday = field.NewBool(table, "hideBirthday")
+	u.HideRoutes = field.NewBool(table, "hideRoutes")
+	u.FollowPolicy = field.NewInt32(table, "followPolicy")
+	u.FollowerCount = field.NewInt32(table, "followerCount")
+	u.FollowingCount = field.NewInt32(table, "followingCount")
 
 	u.fillFieldMap()
 
@@ -116,7 +158,7 @@ func (u *user) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (u *user) fillFieldMap() {
-	u.fieldMap = make(map[string]field.Expr, 13)
+	u.fieldMap = make(map[string]field.Expr, 27)
 	u.fieldMap["id"] = u.ID
 	u.fieldMap["userId"] = u.UserID
 	u.fieldMap["avatarUrl"] = u.AvatarURL
@@ -130,6 +172,20 @@ func (u *user) fillFieldMap() {
 	u.fieldMap["username"] = u.Username
 	u.fieldMap["password"] = u.Password
 	u.fieldMap["membershipType"] = u.MembershipType
+	u.fieldMap["email"] = u.Email
+	u.fieldMap["emailVerified"] = u.EmailVerified
+	u.fieldMap["autoRenew"] = u.AutoRenew
+	u.fieldMap["trialUsed"] = u.TrialUsed
+	u.fieldMap["referralCode"] = u.ReferralCode
+	u.fieldMap["referredBy"] = u.ReferredBy
+	u.fieldMap["referralRewarded"] = u.ReferralRewarded
+	u.fieldMap["credits"] = u.Credits
+	u.fieldMap["profileVisibility"] = u.ProfileVisibility
+	u.fieldMap["hideBirthday"] = u.HideBirthday
+	u.fieldMap["hideRoutes"] = u.HideRoutes
+	u.fieldMap["followPolicy"] = u.FollowPolicy
+	u.fieldMap["followerCount"] = u.FollowerCount
+	u.fieldMap["followingCount"] = u.FollowingCount
 }
 
 func (u user) clone(db *gorm.DB) user {
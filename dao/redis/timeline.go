@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// A home timeline is a sorted set of moment IDs scored by the creation time of the moments
+// in milliseconds. A timeline that exists holds every recent moment it should, moments are
+// pushed only to existing timelines and a missing one is built again from the database.
+const (
+	timelineKeyFmt = "timeline:%s"
+	// keeps the timeline of a user without any moment to show until the first one is pushed
+	timelineEmptyMember = "-"
+	// timelines of users who stop reading expire
+	timelineTTL = 14 * 24 * time.Hour
+)
+
+// pushes the moment and trims the timeline to the newest ARGV[3] moments
+var pushTimelineScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+	redis.call('ZREM', KEYS[1], ARGV[4])
+	redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -(tonumber(ARGV[3]) + 1))
+end
+return 0
+`)
+
+func timelineKey(userID string) string {
+	return fmt.Sprintf(timelineKeyFmt, userID)
+}
+
+// TimelineExists reports whether the timeline of the user is built
+func TimelineExists(ctx context.Context, userID string) (bool, error) {
+	n, err := rdb.Exists(ctx, timelineKey(userID)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// BuildTimeline replaces the timeline of the user with the given moments, momentIDs and
+// createdAt are of the same length
+func BuildTimeline(ctx context.Context, userID string, momentIDs []string, createdAt []time.Time) error {
+	key := timelineKey(userID)
+
+	members := make([]*redis.Z, len(momentIDs))
+	for i, id := range momentIDs {
+		members[i] = &redis.Z{Score: float64(createdAt[i].UnixMilli()), Member: id}
+	}
+	if len(members) == 0 {
+		members = append(members, &redis.Z{Score: 0, Member: timelineEmptyMember})
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.ZAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, timelineTTL)
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+// PushToTimelines adds the moment to the built timelines of the users, keeping the newest
+// length moments of each
+func PushToTimelines(ctx context.Context, userIDs []string, momentID string, createdAt time.Time, length int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	pipe := rdb.Pipeline()
+	for _, userID := range userIDs {
+		pushTimelineScript.Eval(ctx, pipe, []string{timelineKey(userID)}, createdAt.UnixMilli(), momentID, length, timelineEmptyMember)
+	}
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+// ReadTimeline returns up to limit moment IDs created before the given time, newest first,
+// together with the number of moments in the timeline
+func ReadTimeline(ctx context.Context, userID string, before time.Time, limit int) ([]string, int64, error) {
+	key := timelineKey(userID)
+
+	pipe := rdb.Pipeline()
+	ids := pipe.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Max:   "(" + strconv.FormatInt(before.UnixMilli(), 10),
+		Min:   "(0",
+		Count: int64(limit),
+	})
+	count := pipe.ZCount(ctx, key, "(0", "+inf")
+	pipe.Expire(ctx, key, timelineTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	return ids.Val(), count.Val(), nil
+}
+
+// DropTimelines deletes the timelines of the users, they are built again when read
+func DropTimelines(ctx context.Context, userIDs ...string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = timelineKey(userID)
+	}
+
+	return rdb.Del(ctx, keys...).Err()
+}
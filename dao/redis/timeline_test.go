@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	ctx := context.Background()
+	userID := "testTimelineUser"
+	base := time.UnixMilli(1700000000000)
+	defer DropTimelines(ctx, userID)
+
+	// moments are only pushed to built timelines
+	if err := PushToTimelines(ctx, []string{userID}, "m0", base, 2); err != nil {
+		t.Fatalf("Failed to push moment: %v", err)
+	}
+	if exists, err := TimelineExists(ctx, userID); err != nil || exists {
+		t.Fatalf("Expected no timeline, got %v, %v", exists, err)
+	}
+
+	if err := BuildTimeline(ctx, userID, nil, nil); err != nil {
+		t.Fatalf("Failed to build timeline: %v", err)
+	}
+	for i, id := range []string{"m1", "m2", "m3"} {
+		if err := PushToTimelines(ctx, []string{userID}, id, base.Add(time.Duration(i+1)*time.Second), 2); err != nil {
+			t.Fatalf("Failed to push moment: %v", err)
+		}
+	}
+
+	ids, count, err := ReadTimeline(ctx, userID, base.Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("Failed to read timeline: %v", err)
+	}
+	if want := []string{"m3", "m2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected %v, got %v", want, ids)
+	}
+	if count != 2 {
+		t.Fatalf("Expected the timeline to keep 2 moments, got %d", count)
+	}
+
+	// the cursor is exclusive
+	ids, _, err = ReadTimeline(ctx, userID, base.Add(3*time.Second), 10)
+	if err != nil {
+		t.Fatalf("Failed to read timeline: %v", err)
+	}
+	if want := []string{"m2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected %v, got %v", want, ids)
+	}
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Trending items are kept in sorted sets, one for everywhere and one per region. Scores are in
+// log2 space and grow by one every half-life: a signal of weight w at time t adds
+// w * 2^(t / halfLife), so older signals count half as much after every half-life without
+// ever rewriting them.
+const (
+	trendingKeyFmt       = "trending:%s"
+	trendingRegionKeyFmt = "trending:%s:region:%s"
+	// kind, item, signal and user
+	trendingSignalKeyFmt = "trending:%s:%s:signal:%s:%s"
+)
+
+// adds 2^ARGV[2] to the score of ARGV[1] in every set, all in log2 space, and keeps the top ARGV[3]
+var bumpTrendingScript = redis.NewScript(`
+local add = tonumber(ARGV[2])
+for _, key in ipairs(KEYS) do
+	local score = add
+	local current = redis.call('ZSCORE', key, ARGV[1])
+	if current then
+		current = tonumber(current)
+		local high, low = math.max(current, add), math.min(current, add)
+		score = high + math.log(1 + 2 ^ (low - high)) / math.log(2)
+	end
+	redis.call('ZADD', key, score, ARGV[1])
+	redis.call('ZREMRANGEBYRANK', key, 0, -(tonumber(ARGV[3]) + 1))
+end
+return 0
+`)
+
+func trendingKey(kind, region string) string {
+	if region == "" {
+		return fmt.Sprintf(trendingKeyFmt, kind)
+	}
+
+	return fmt.Sprintf(trendingRegionKeyFmt, kind, region)
+}
+
+// BumpTrending adds 2^logWeight to the score of the item everywhere and in its region if it has one,
+// each set keeps its length best items
+func BumpTrending(ctx context.Context, kind, region, member string, logWeight float64, length int) error {
+	keys := []string{trendingKey(kind, "")}
+	if region != "" {
+		keys = append(keys, trendingKey(kind, region))
+	}
+
+	return bumpTrendingScript.Run(ctx, rdb, keys, member, logWeight, length).Err()
+}
+
+// TopTrending returns up to limit items with the best scores, everywhere if region is empty
+func TopTrending(ctx context.Context, kind, region string, limit int) ([]string, error) {
+	return rdb.ZRevRange(ctx, trendingKey(kind, region), 0, int64(limit-1)).Result()
+}
+
+// MarkTrendingSignal records that the user gave the signal to the item, false if the user did so
+// already within ttl
+func MarkTrendingSignal(ctx context.Context, kind, member, signal, userID string, ttl time.Duration) (bool, error) {
+	return rdb.SetNX(ctx, fmt.Sprintf(trendingSignalKeyFmt, kind, member, signal, userID), 1, ttl).Result()
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBumpTrending(t *testing.T) {
+	ctx := context.Background()
+	kind := "testTrending"
+	defer rdb.Del(ctx, trendingKey(kind, ""), trendingKey(kind, "Leeds"))
+
+	// two signals of weight 1 now count as much as one of weight 2
+	for i := 0; i < 2; i++ {
+		if err := BumpTrending(ctx, kind, "Leeds", "a", 10, 10); err != nil {
+			t.Fatalf("Failed to bump: %v", err)
+		}
+	}
+	score, err := rdb.ZScore(ctx, trendingKey(kind, ""), "a").Result()
+	if err != nil {
+		t.Fatalf("Failed to get score: %v", err)
+	}
+	if math.Abs(score-11) > 1e-9 {
+		t.Fatalf("Expected score 11, got %v", score)
+	}
+
+	// b counts more than a
+	if err := BumpTrending(ctx, kind, "", "b", 11.5, 10); err != nil {
+		t.Fatalf("Failed to bump: %v", err)
+	}
+
+	ids, err := TopTrending(ctx, kind, "", 10)
+	if err != nil {
+		t.Fatalf("Failed to get top items: %v", err)
+	}
+	if want := []string{"b", "a"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected %v, got %v", want, ids)
+	}
+
+	ids, err = TopTrending(ctx, kind, "Leeds", 10)
+	if err != nil {
+		t.Fatalf("Failed to get top items: %v", err)
+	}
+	if want := []string{"a"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected %v, got %v", want, ids)
+	}
+}
+
+func TestMarkTrendingSignal(t *testing.T) {
+	ctx := context.Background()
+	kind := "testTrending"
+	defer rdb.Del(ctx, fmt.Sprintf(trendingSignalKeyFmt, kind, "a", "like", "u1"))
+
+	for i, want := range []bool{true, false} {
+		marked, err := MarkTrendingSignal(ctx, kind, "a", "like", "u1", time.Minute)
+		if err != nil {
+			t.Fatalf("Failed to mark signal: %v", err)
+		}
+		if marked != want {
+			t.Fatalf("Mark %d: expected %v, got %v", i+1, want, marked)
+		}
+	}
+}
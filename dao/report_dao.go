@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"api.backend.xjco2913/util"
+)
+
+// Dimensions a revenue report can be grouped by besides time
+const (
+	REVENUE_GROUP_NONE      = ""
+	REVENUE_GROUP_TAG       = "tag"
+	REVENUE_GROUP_ORGANISER = "organiser"
+)
+
+type RevenueRow struct {
+	BucketIdx int    `gorm:"column:bucketIdx"`
+	GroupKey  string `gorm:"column:groupKey"`
+	GroupName string `gorm:"column:groupName"`
+	Revenue   int64  `gorm:"column:revenue"`
+	Signups   int64  `gorm:"column:signups"`
+}
+
+// SumRevenueByBuckets aggregates activity_user.finalFee per bucket (and per group) in one query.
+// Revenue is attributed to the bucket containing the activity end date, buckets are passed
+// in as a derived table so that calendar boundaries are computed by the caller in its own timezone.
+func SumRevenueByBuckets(ctx context.Context, buckets []util.TimeBucket, groupBy string) ([]*RevenueRow, error) {
+	if len(buckets) == 0 {
+		return []*RevenueRow{}, nil
+	}
+
+	selects := make([]string, len(buckets))
+	args := make([]interface{}, 0, len(buckets)*3)
+	for i, bucket := range buckets {
+		selects[i] = "SELECT ? AS idx, ? AS startAt, ? AS endAt"
+		args = append(args, i, bucket.Start, bucket.End)
+	}
+	bucketTable := strings.Join(selects, " UNION ALL ")
+
+	var stat string
+	switch groupBy {
+	case REVENUE_GROUP_NONE:
+		stat = fmt.Sprintf(
+			`SELECT b.idx AS bucketIdx, COALESCE(SUM(au.finalFee), 0) AS revenue, COUNT(au.userId) AS signups
+			FROM (%s) b
+			LEFT JOIN activities a ON a.endDate >= b.startAt AND a.endDate < b.endAt
+			LEFT JOIN activity_user au ON au.activityId = a.activityId
+			GROUP BY b.idx
+			ORDER BY b.idx`,
+			bucketTable,
+		)
+	case REVENUE_GROUP_TAG:
+		stat = fmt.Sprintf(
+			`SELECT b.idx AS bucketIdx, CAST(t.id AS CHAR) AS groupKey, t.name AS groupName,
+				COALESCE(SUM(au.finalFee), 0) AS revenue, COUNT(au.userId) AS signups
+			FROM (%s) b
+			JOIN activities a ON a.endDate >= b.startAt AND a.endDate < b.endAt
+			JOIN tags t ON FIND_IN_SET(t.id, REPLACE(a.tags, '|', ',')) > 0
+			JOIN activity_user au ON au.activityId = a.activityId
+			GROUP BY b.idx, t.id, t.name
+			ORDER BY b.idx, t.id`,
+			bucketTable,
+		)
+	case REVENUE_GROUP_ORGANISER:
+		stat = fmt.Sprintf(
+			`SELECT b.idx AS bucketIdx, a.creatorID AS groupKey, COALESCE(MAX(u.username), '') AS groupName,
+				COALESCE(SUM(au.finalFee), 0) AS revenue, COUNT(au.userId) AS signups
+			FROM (%s) b
+			JOIN activities a ON a.endDate >= b.startAt AND a.endDate < b.endAt
+			JOIN activity_user au ON au.activityId = a.activityId
+			LEFT JOIN users u ON u.userId = a.creatorID
+			GROUP BY b.idx, a.creatorID
+			ORDER BY b.idx, a.creatorID`,
+			bucketTable,
+		)
+	default:
+		return nil, fmt.Errorf("unsupported revenue group: %s", groupBy)
+	}
+
+	var rows []*RevenueRow
+	err := DB.WithContext(ctx).Raw(stat, args...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+	"api.backend.xjco2913/util"
+)
+
+const (
+	RIDE_MOMENT   = "moment"
+	RIDE_ACTIVITY = "activity"
+)
+
+type RideRow struct {
+	BucketIdx     int     `gorm:"column:bucketIdx"`
+	Distance      float64 `gorm:"column:distance"`
+	ElevationGain float64 `gorm:"column:elevationGain"`
+	Rides         int64   `gorm:"column:rides"`
+}
+
+// SaveRide records a ride, replacing the earlier ride of the same moment or activity,
+// and refreshes the totals of the user
+func SaveRide(ctx context.Context, ride *model.Ride) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		r := tx.Ride
+
+		_, err := r.WithContext(ctx).Where(
+			r.UserID.Eq(ride.UserID),
+			r.Source.Eq(ride.Source),
+			r.RefID.Eq(ride.RefID),
+		).Delete()
+		if err != nil {
+			return err
+		}
+		if err := r.WithContext(ctx).Create(ride); err != nil {
+			return err
+		}
+
+		return refreshUserStats(ctx, tx, ride.UserID)
+	})
+}
+
+// BackfillRides records the rides of routes uploaded before the statistics existed,
+// rides that are recorded already are kept. The totals are created even without rides.
+func BackfillRides(ctx context.Context, userID string, rides []*model.Ride) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		r := tx.Ride
+
+		existing, err := r.WithContext(ctx).Where(r.UserID.Eq(userID)).Find()
+		if err != nil {
+			return err
+		}
+		recorded := make(map[string]bool, len(existing))
+		for _, ride := range existing {
+			recorded[ride.Source+"|"+ride.RefID] = true
+		}
+
+		for _, ride := range rides {
+			if recorded[ride.Source+"|"+ride.RefID] {
+				continue
+			}
+			if err := r.WithContext(ctx).Create(ride); err != nil {
+				return err
+			}
+		}
+
+		return refreshUserStats(ctx, tx, userID)
+	})
+}
+
+// refreshUserStats sums the recorded rides of the user into user_stats
+func refreshUserStats(ctx context.Context, tx *query.Query, userID string) error {
+	return tx.UserStat.WithContext(ctx).UnderlyingDB().Exec(
+		`INSERT INTO user_stats (userId, distance, elevationGain, rideCount, longestRide)
+		SELECT * FROM (
+			SELECT ? AS userId, COALESCE(SUM(distance), 0) AS distance, COALESCE(SUM(elevationGain), 0) AS elevationGain,
+				COUNT(*) AS rideCount, COALESCE(MAX(distance), 0) AS longestRide
+			FROM rides WHERE userId = ?
+		) AS t
+		ON DUPLICATE KEY UPDATE distance = t.distance, elevationGain = t.elevationGain,
+			rideCount = t.rideCount, longestRide = t.longestRide`,
+		userID, userID,
+	).Error
+}
+
+func GetUserStats(ctx context.Context, userID string) (*model.UserStat, error) {
+	s := query.Use(DB).UserStat
+
+	return s.WithContext(ctx).Where(s.UserID.Eq(userID)).First()
+}
+
+// SumRidesByBuckets aggregates the rides of the user per bucket, like SumRevenueByBuckets
+// the buckets are passed in as a derived table
+func SumRidesByBuckets(ctx context.Context, userID string, buckets []util.TimeBucket) ([]*RideRow, error) {
+	if len(buckets) == 0 {
+		return []*RideRow{}, nil
+	}
+
+	selects := make([]string, len(buckets))
+	args := make([]interface{}, 0, len(buckets)*3+1)
+	for i, bucket := range buckets {
+		selects[i] = "SELECT ? AS idx, ? AS startAt, ? AS endAt"
+		args = append(args, i, bucket.Start, bucket.End)
+	}
+	args = append(args, userID)
+
+	stat := fmt.Sprintf(
+		`SELECT b.idx AS bucketIdx, COALESCE(SUM(r.distance), 0) AS distance,
+			COALESCE(SUM(r.elevationGain), 0) AS elevationGain, COUNT(r.id) AS rides
+		FROM (%s) b
+		LEFT JOIN rides r ON r.userId = ? AND r.rodeAt >= b.startAt AND r.rodeAt < b.endAt
+		GROUP BY b.idx
+		ORDER BY b.idx`,
+		strings.Join(selects, " UNION ALL "),
+	)
+
+	var rows []*RideRow
+	err := DB.WithContext(ctx).Raw(stat, args...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// DeleteRidesByUserID deletes the rides and the totals of the user
+func DeleteRidesByUserID(ctx context.Context, userID string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		if _, err := tx.Ride.WithContext(ctx).Where(tx.Ride.UserID.Eq(userID)).Delete(); err != nil {
+			return err
+		}
+		_, err := tx.UserStat.WithContext(ctx).Where(tx.UserStat.UserID.Eq(userID)).Delete()
+
+		return err
+	})
+}
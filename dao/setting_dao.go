@@ -0,0 +1,24 @@
+package dao
+
+import (
+	"context"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+)
+
+const (
+	// "1" while every admin has to use 2FA
+	SETTING_ADMIN_2FA_ENFORCED = "admin2faEnforced"
+)
+
+func GetSetting(ctx context.Context, name string) (*model.Setting, error) {
+	s := query.Use(DB).Setting
+
+	return s.WithContext(ctx).Where(s.Name.Eq(name)).First()
+}
+
+// SaveSetting inserts the setting or replaces its value
+func SaveSetting(ctx context.Context, name string, value string) error {
+	return query.Use(DB).Setting.WithContext(ctx).Save(&model.Setting{Name: name, Value: value})
+}
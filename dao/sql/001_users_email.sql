@@ -0,0 +1,3 @@
+-- Contact address used to deliver password reset mails
+ALTER TABLE `users`
+    ADD COLUMN `email` VARCHAR(255) NULL DEFAULT NULL;
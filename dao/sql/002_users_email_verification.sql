@@ -0,0 +1,4 @@
+-- Emails identify accounts from now on, existing duplicates must be cleaned up before running this
+ALTER TABLE `users`
+    ADD COLUMN `emailVerified` TINYINT(1) NOT NULL DEFAULT 0,
+    ADD UNIQUE INDEX `uk_users_email` (`email`);
@@ -0,0 +1,11 @@
+-- TOTP enrolment of users and admins, ownerId is a users.userId or an admins.id
+CREATE TABLE `two_factors` (
+    `ownerId`       VARCHAR(64)  NOT NULL,
+    `ownerType`     VARCHAR(16)  NOT NULL COMMENT 'user or admin',
+    `secret`        VARCHAR(64)  NOT NULL COMMENT 'base32 encoded TOTP secret',
+    `enabled`       TINYINT(1)   NOT NULL DEFAULT 0 COMMENT 'set once the first code has been confirmed',
+    `recoveryCodes` TEXT         NULL COMMENT 'sha256 hashes of unused recovery codes separated by ''|''',
+    `createdAt`     DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    `updatedAt`     DATETIME     NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`ownerId`)
+);
@@ -0,0 +1,13 @@
+-- External identities (OpenID Connect) linked to users, a provider subject belongs to one user only
+CREATE TABLE `user_identities` (
+    `id`          INT          NOT NULL AUTO_INCREMENT,
+    `userId`      VARCHAR(64)  NOT NULL,
+    `provider`    VARCHAR(32)  NOT NULL COMMENT 'name of the provider in config.yml',
+    `subject`     VARCHAR(255) NOT NULL COMMENT 'sub claim of the id token',
+    `email`       VARCHAR(255) NULL COMMENT 'email claimed by the provider at the last login',
+    `createdAt`   DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    `lastLoginAt` DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_user_identities_provider_subject` (`provider`, `subject`),
+    KEY `idx_user_identities_userId` (`userId`)
+);
@@ -0,0 +1,16 @@
+-- Ban history of users, the active bans are mirrored into redis as ban:<userId> => id
+CREATE TABLE `bans` (
+    `id`         INT          NOT NULL AUTO_INCREMENT,
+    `userId`     VARCHAR(64)  NOT NULL,
+    `adminId`    VARCHAR(64)  NOT NULL COMMENT 'admin who issued the ban',
+    `reason`     VARCHAR(512) NOT NULL,
+    `startAt`    DATETIME     NOT NULL,
+    `endAt`      DATETIME     NULL COMMENT 'NULL for a permanent ban',
+    `liftedAt`   DATETIME     NULL COMMENT 'set when an admin lifts the ban before it ends',
+    `liftedBy`   VARCHAR(64)  NULL,
+    `appealNote` TEXT         NULL,
+    `createdAt`  DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    `updatedAt`  DATETIME     NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_bans_userId` (`userId`)
+);
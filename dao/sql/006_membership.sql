@@ -0,0 +1,22 @@
+-- Members opting in are renewed by the expiry sweep instead of being downgraded
+ALTER TABLE `users`
+    ADD COLUMN `autoRenew` TINYINT(1) NOT NULL DEFAULT 0;
+
+-- Every change of a membership, amount is what was charged, negative for refunds
+CREATE TABLE `membership_histories` (
+    `id`        INT         NOT NULL AUTO_INCREMENT,
+    `userId`    VARCHAR(64) NOT NULL,
+    `action`    VARCHAR(16) NOT NULL COMMENT 'subscribe, renew, upgrade, downgrade, cancel or expire',
+    `fromType`  INT         NOT NULL,
+    `toType`    INT         NOT NULL,
+    `amount`    INT         NOT NULL DEFAULT 0,
+    `expiresAt` BIGINT      NOT NULL COMMENT 'membership expired time after the change, a unix timestamp',
+    `createdAt` DATETIME    NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_membership_histories_userId` (`userId`)
+);
+
+-- Text of notifications sent by the system, e.g. membership reminders
+ALTER TABLE `notifications`
+    ADD COLUMN `content` VARCHAR(512) NULL DEFAULT NULL,
+    MODIFY COLUMN `type` INT NOT NULL DEFAULT 1 COMMENT '1 is admin notification, 2 is route notification, 3 is system notification';
@@ -0,0 +1,34 @@
+-- Every account can start one free trial
+ALTER TABLE `users`
+    ADD COLUMN `trialUsed` TINYINT(1) NOT NULL DEFAULT 0;
+
+ALTER TABLE `membership_histories`
+    MODIFY COLUMN `action` VARCHAR(16) NOT NULL COMMENT 'subscribe, renew, upgrade, downgrade, cancel, expire, voucher or trial';
+
+-- Batches of voucher codes generated by admins, each code grants membershipType for days
+CREATE TABLE `voucher_batches` (
+    `id`             INT          NOT NULL AUTO_INCREMENT,
+    `name`           VARCHAR(128) NOT NULL,
+    `membershipType` INT          NOT NULL,
+    `days`           INT          NOT NULL,
+    `quantity`       INT          NOT NULL,
+    `redeemBy`       DATETIME     NULL COMMENT 'NULL if the codes do not expire',
+    `createdBy`      VARCHAR(64)  NOT NULL COMMENT 'admin who generated the batch',
+    `revokedAt`      DATETIME     NULL COMMENT 'set when an admin revokes the unredeemed codes',
+    `createdAt`      DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+);
+
+-- Only the sha256 of a code is stored, the codes are shown once when the batch is generated
+CREATE TABLE `vouchers` (
+    `id`         INT         NOT NULL AUTO_INCREMENT,
+    `batchId`    INT         NOT NULL,
+    `codeHash`   VARCHAR(64) NOT NULL,
+    `redeemedBy` VARCHAR(64) NULL,
+    `redeemedAt` DATETIME    NULL,
+    `createdAt`  DATETIME    NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_vouchers_codeHash` (`codeHash`),
+    KEY `idx_vouchers_batchId` (`batchId`),
+    KEY `idx_vouchers_redeemedBy` (`redeemedBy`)
+);
@@ -0,0 +1,20 @@
+-- Referral programme, credits is the balance of the credit_ledgers entries of the user
+ALTER TABLE `users`
+    ADD COLUMN `referralCode`     VARCHAR(16) NULL DEFAULT NULL,
+    ADD COLUMN `referredBy`       VARCHAR(64) NULL DEFAULT NULL COMMENT 'userId of the referrer',
+    ADD COLUMN `referralRewarded` TINYINT(1)  NOT NULL DEFAULT 0 COMMENT 'set once both sides have earned the referral reward',
+    ADD COLUMN `credits`          INT         NOT NULL DEFAULT 0,
+    ADD UNIQUE KEY `uk_users_referralCode` (`referralCode`),
+    ADD KEY `idx_users_referredBy` (`referredBy`);
+
+-- Every change of the credits of a user, amount is negative when credits are spent
+CREATE TABLE `credit_ledgers` (
+    `id`        INT         NOT NULL AUTO_INCREMENT,
+    `userId`    VARCHAR(64) NOT NULL,
+    `amount`    INT         NOT NULL,
+    `reason`    VARCHAR(16) NOT NULL COMMENT 'referrer, referred or activity',
+    `refId`     VARCHAR(64) NOT NULL COMMENT 'referred user for referrer, referrer for referred, activity for activity',
+    `createdAt` DATETIME    NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_credit_ledgers_userId` (`userId`)
+);
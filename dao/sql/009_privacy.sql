@@ -0,0 +1,6 @@
+-- Privacy settings of a profile, enforced for everyone but the user and admins
+ALTER TABLE `users`
+    ADD COLUMN `profileVisibility` INT        NOT NULL DEFAULT 0 COMMENT '0 is public, 1 is followers only, 2 is private',
+    ADD COLUMN `hideBirthday`      TINYINT(1) NOT NULL DEFAULT 0,
+    ADD COLUMN `hideRoutes`        TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'routes of moments are only shown to the user',
+    ADD COLUMN `followPolicy`      INT        NOT NULL DEFAULT 0 COMMENT '0 is everyone may follow, 1 is nobody may follow';
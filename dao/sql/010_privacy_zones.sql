@@ -0,0 +1,12 @@
+-- Circles around places such as home, points of routes inside them are only shown to the user
+CREATE TABLE `privacy_zones` (
+    `id`        INT          NOT NULL AUTO_INCREMENT,
+    `userId`    VARCHAR(64)  NOT NULL,
+    `name`      VARCHAR(64)  NOT NULL,
+    `latitude`  DOUBLE       NOT NULL,
+    `longitude` DOUBLE       NOT NULL,
+    `radius`    INT          NOT NULL COMMENT 'in meters',
+    `createdAt` DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_privacy_zones_userId` (`userId`)
+);
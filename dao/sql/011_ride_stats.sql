@@ -0,0 +1,26 @@
+-- One row per route a user rode, measured once when the route is uploaded
+CREATE TABLE `rides` (
+    `id`            INT         NOT NULL AUTO_INCREMENT,
+    `userId`        VARCHAR(64) NOT NULL,
+    `source`        VARCHAR(16) NOT NULL COMMENT 'moment or activity',
+    `refId`         VARCHAR(64) NOT NULL COMMENT 'momentId or activityId',
+    `routeId`       INT         NOT NULL,
+    `distance`      DOUBLE      NOT NULL COMMENT 'in meters',
+    `elevationGain` DOUBLE      NOT NULL COMMENT 'in meters, 0 for routes without elevation',
+    `rodeAt`        DATETIME    NOT NULL,
+    `createdAt`     DATETIME    NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_rides_ref` (`userId`, `source`, `refId`),
+    KEY `idx_rides_userId_rodeAt` (`userId`, `rodeAt`)
+);
+
+-- Lifetime totals of the rides of a user, refreshed whenever a ride is added or removed
+CREATE TABLE `user_stats` (
+    `userId`        VARCHAR(64) NOT NULL,
+    `distance`      DOUBLE      NOT NULL DEFAULT 0 COMMENT 'in meters',
+    `elevationGain` DOUBLE      NOT NULL DEFAULT 0 COMMENT 'in meters',
+    `rideCount`     INT         NOT NULL DEFAULT 0,
+    `longestRide`   DOUBLE      NOT NULL DEFAULT 0 COMMENT 'in meters',
+    `updatedAt`     DATETIME    NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`userId`)
+);
@@ -0,0 +1,13 @@
+ALTER TABLE `rides`
+    ADD COLUMN `best10k` INT NULL DEFAULT NULL COMMENT 'fastest 10 km in seconds, null if the ride is shorter or has no timestamps' AFTER `elevationGain`;
+
+-- Badges earned by a user, each badge is awarded once
+CREATE TABLE `user_badges` (
+    `id`        INT         NOT NULL AUTO_INCREMENT,
+    `userId`    VARCHAR(64) NOT NULL,
+    `badge`     VARCHAR(32) NOT NULL,
+    `rideId`    INT         NULL DEFAULT NULL COMMENT 'ride that earned the badge',
+    `awardedAt` DATETIME    NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_user_badges_badge` (`userId`, `badge`)
+);
@@ -0,0 +1,38 @@
+-- A user follows another at most once, earlier duplicates are merged into one row
+CREATE TEMPORARY TABLE `follow_dedup` AS
+SELECT `userId`, `followingId`, MIN(`createdAt`) AS `createdAt`, MAX(`updatedAt`) AS `updatedAt`
+FROM `follow`
+GROUP BY `userId`, `followingId`;
+
+DELETE FROM `follow`;
+
+INSERT INTO `follow` (`userId`, `followingId`, `createdAt`, `updatedAt`)
+SELECT `userId`, `followingId`, `createdAt`, `updatedAt` FROM `follow_dedup`;
+
+DROP TEMPORARY TABLE `follow_dedup`;
+
+ALTER TABLE `follow`
+    ADD UNIQUE KEY `uk_follow_pair` (`userId`, `followingId`);
+
+-- Follow counts kept on the user, changed together with the follow rows
+ALTER TABLE `users`
+    MODIFY COLUMN `followPolicy` INT NOT NULL DEFAULT 0 COMMENT '0 is everyone may follow, 1 is nobody may follow, 2 is followers need approval',
+    ADD COLUMN `followerCount`  INT NOT NULL DEFAULT 0,
+    ADD COLUMN `followingCount` INT NOT NULL DEFAULT 0;
+
+UPDATE `users` u SET
+    u.`followerCount`  = (SELECT COUNT(*) FROM `follow` f WHERE f.`followingId` = u.`userId`),
+    u.`followingCount` = (SELECT COUNT(*) FROM `follow` f WHERE f.`userId` = u.`userId`);
+
+-- Requests to follow users whose followers need approval, a declined request can be sent again
+CREATE TABLE `follow_requests` (
+    `id`          INT         NOT NULL AUTO_INCREMENT,
+    `requesterId` VARCHAR(64) NOT NULL,
+    `targetId`    VARCHAR(64) NOT NULL,
+    `status`      VARCHAR(16) NOT NULL COMMENT 'pending, accepted or declined',
+    `createdAt`   DATETIME    NULL DEFAULT CURRENT_TIMESTAMP,
+    `decidedAt`   DATETIME    NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_follow_requests_pair` (`requesterId`, `targetId`),
+    KEY `idx_follow_requests_targetId_status` (`targetId`, `status`)
+);
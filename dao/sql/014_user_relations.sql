@@ -0,0 +1,12 @@
+-- Blocks and mutes between users. A block hides the two users from each other and stops
+-- them from interacting, a mute only hides the moments of the target from the feed of the user.
+CREATE TABLE `user_relations` (
+    `id`        INT         NOT NULL AUTO_INCREMENT,
+    `userId`    VARCHAR(64) NOT NULL,
+    `targetId`  VARCHAR(64) NOT NULL,
+    `kind`      VARCHAR(16) NOT NULL COMMENT 'block or mute',
+    `createdAt` DATETIME    NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_user_relations` (`userId`, `targetId`, `kind`),
+    KEY `idx_user_relations_targetId_kind` (`targetId`, `kind`)
+);
@@ -0,0 +1,16 @@
+-- Replies hang on a top-level comment, a reply to a reply joins the thread of its parent
+ALTER TABLE `comments`
+    ADD COLUMN `parentId` VARCHAR(64) NULL DEFAULT NULL COMMENT 'the top-level comment of the thread, null for top-level comments',
+    ADD COLUMN `editedAt` DATETIME    NULL DEFAULT NULL,
+    ADD KEY `idx_comments_momentId_parentId` (`momentId`, `parentId`, `createdAt`),
+    ADD KEY `idx_comments_parentId` (`parentId`);
+
+-- Earlier versions of edited comments, newest last
+CREATE TABLE `comment_edits` (
+    `id`        INT          NOT NULL AUTO_INCREMENT,
+    `commentId` VARCHAR(64)  NOT NULL,
+    `content`   TEXT         NOT NULL COMMENT 'the content before the edit',
+    `editedAt`  DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_comment_edits_commentId` (`commentId`)
+);
@@ -0,0 +1,36 @@
+-- Moments carry an ordered list of attachments: images, a video and a route.
+-- routeId stays on moments, the ride of a moment is measured from it, the route attachment
+-- points at the same route to place it among the others.
+CREATE TABLE `moment_attachments` (
+    `id`          INT          NOT NULL AUTO_INCREMENT,
+    `momentId`    VARCHAR(64)  NOT NULL,
+    `position`    INT          NOT NULL COMMENT 'order of the attachment in the moment, from 0',
+    `kind`        VARCHAR(16)  NOT NULL COMMENT 'image, video or route',
+    `objectName`  VARCHAR(255) NULL DEFAULT NULL COMMENT 'the object in the user-moment bucket, null for routes',
+    `routeId`     INT          NULL DEFAULT NULL,
+    `contentType` VARCHAR(64)  NULL DEFAULT NULL,
+    `width`       INT          NULL DEFAULT NULL,
+    `height`      INT          NULL DEFAULT NULL,
+    `createdAt`   DATETIME     NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_moment_attachments` (`momentId`, `position`)
+);
+
+-- Earlier moments had at most one image, video or route, so it goes at position 0. Should a row
+-- hold more than one, they follow each other. The dimensions of earlier images are unknown.
+INSERT INTO `moment_attachments` (`momentId`, `position`, `kind`, `objectName`, `contentType`, `createdAt`)
+SELECT `momentId`, 0, 'image', `imageUrl`, 'image/jpeg', `createdAt` FROM `moments`
+WHERE `imageUrl` IS NOT NULL AND `imageUrl` <> '';
+
+INSERT INTO `moment_attachments` (`momentId`, `position`, `kind`, `objectName`, `contentType`, `createdAt`)
+SELECT `momentId`, (`imageUrl` IS NOT NULL AND `imageUrl` <> ''), 'video', `videoUrl`, 'video/mp4', `createdAt` FROM `moments`
+WHERE `videoUrl` IS NOT NULL AND `videoUrl` <> '';
+
+INSERT INTO `moment_attachments` (`momentId`, `position`, `kind`, `routeId`, `createdAt`)
+SELECT `momentId`, (`imageUrl` IS NOT NULL AND `imageUrl` <> '') + (`videoUrl` IS NOT NULL AND `videoUrl` <> ''),
+    'route', `routeId`, `createdAt` FROM `moments`
+WHERE `routeId` IS NOT NULL;
+
+ALTER TABLE `moments`
+    DROP COLUMN `imageUrl`,
+    DROP COLUMN `videoUrl`;
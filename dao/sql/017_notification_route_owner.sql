@@ -0,0 +1,4 @@
+-- The rider whose route a route notification carries, their privacy zones are trimmed from it.
+-- Routes were shared in the name of the system user before, their owner is unknown.
+ALTER TABLE `notifications`
+    ADD COLUMN `routeOwnerId` VARCHAR(64) NULL DEFAULT NULL COMMENT 'the user whose route is shared, null if unknown';
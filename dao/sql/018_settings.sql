@@ -0,0 +1,7 @@
+-- Settings admins change at runtime, by name. Redis only caches them.
+CREATE TABLE `settings` (
+    `name`      VARCHAR(64)  NOT NULL,
+    `value`     VARCHAR(255) NOT NULL,
+    `updatedAt` DATETIME     NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`name`)
+);
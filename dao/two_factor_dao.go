@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"context"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+)
+
+func GetTwoFactorByOwnerID(ctx context.Context, ownerID string) (*model.TwoFactor, error) {
+	t := query.Use(DB).TwoFactor
+
+	twoFactor, err := t.WithContext(ctx).Where(t.OwnerID.Eq(ownerID)).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return twoFactor, nil
+}
+
+// SaveTwoFactor inserts the enrolment or replaces the existing one of the owner
+func SaveTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error {
+	return query.Use(DB).TwoFactor.WithContext(ctx).Save(twoFactor)
+}
+
+func UpdateTwoFactor(ctx context.Context, ownerID string, updates map[string]interface{}) error {
+	t := query.Use(DB).TwoFactor
+
+	_, err := t.WithContext(ctx).Where(t.OwnerID.Eq(ownerID)).Updates(updates)
+
+	return err
+}
+
+func DeleteTwoFactor(ctx context.Context, ownerID string) error {
+	t := query.Use(DB).TwoFactor
+
+	_, err := t.WithContext(ctx).Where(t.OwnerID.Eq(ownerID)).Delete()
+
+	return err
+}
+
+// ReplaceRecoveryCodes swaps the recovery codes only if they still equal previous,
+// so that two requests cannot spend the same code
+func ReplaceRecoveryCodes(ctx context.Context, ownerID string, previous string, codes string) (bool, error) {
+	t := query.Use(DB).TwoFactor
+
+	result, err := t.WithContext(ctx).Where(t.OwnerID.Eq(ownerID), t.RecoveryCodes.Eq(previous)).Update(t.RecoveryCodes, codes)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
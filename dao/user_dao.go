@@ -28,6 +28,17 @@ func FindUserByUsername(ctx context.Context, username string) (*model.User, erro
 	return user, nil
 }
 
+func FindUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	u := query.Use(DB).User
+
+	user, err := u.WithContext(ctx).Where(u.Email.Eq(email)).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 func GetAllUsers(ctx context.Context) ([]*model.User, error) {
 	u := query.Use(DB).User
 
@@ -69,6 +80,10 @@ func DeleteUsersByID(ctx context.Context, userIDs string) ([]string, []string, e
 		}
 
 		if result.RowsAffected > 0 {
+			// a login through the provider must not resolve to a deleted user
+			if err := DeleteIdentitiesByUserID(ctx, id); err != nil {
+				return nil, nil, err
+			}
 			deletedIDs = append(deletedIDs, id)
 		} else {
 			notFoundIDs = append(notFoundIDs, id)
@@ -131,4 +146,15 @@ func IsUserExisted(ctx context.Context, userId string) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
+
+// GetUsersByUsernames returns the users with any of the usernames, unknown usernames are left out
+func GetUsersByUsernames(ctx context.Context, usernames []string) ([]*model.User, error) {
+	if len(usernames) == 0 {
+		return []*model.User{}, nil
+	}
+
+	u := query.Use(DB).User
+
+	return u.WithContext(ctx).Where(u.Username.In(usernames...)).Find()
+}
@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+)
+
+func FindIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	i := query.Use(DB).UserIdentity
+
+	identity, err := i.WithContext(ctx).Where(i.Provider.Eq(provider), i.Subject.Eq(subject)).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func GetIdentitiesByUserID(ctx context.Context, userID string) ([]*model.UserIdentity, error) {
+	i := query.Use(DB).UserIdentity
+
+	identities, err := i.WithContext(ctx).Where(i.UserID.Eq(userID)).Order(i.CreatedAt).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	return query.Use(DB).UserIdentity.WithContext(ctx).Create(identity)
+}
+
+// CreateUserWithIdentity creates a user signing up through a provider together with its identity
+func CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		if err := tx.User.WithContext(ctx).Create(user); err != nil {
+			return err
+		}
+
+		identity.UserID = user.UserID
+		return tx.UserIdentity.WithContext(ctx).Create(identity)
+	})
+}
+
+func TouchIdentity(ctx context.Context, identityID int32, email *string) error {
+	i := query.Use(DB).UserIdentity
+
+	_, err := i.WithContext(ctx).Where(i.ID.Eq(identityID)).Updates(map[string]interface{}{
+		"email":       email,
+		"lastLoginAt": time.Now(),
+	})
+
+	return err
+}
+
+func DeleteIdentity(ctx context.Context, userID string, provider string) (bool, error) {
+	i := query.Use(DB).UserIdentity
+
+	result, err := i.WithContext(ctx).Where(i.UserID.Eq(userID), i.Provider.Eq(provider)).Delete()
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func DeleteIdentitiesByUserID(ctx context.Context, userID string) error {
+	i := query.Use(DB).UserIdentity
+
+	_, err := i.WithContext(ctx).Where(i.UserID.Eq(userID)).Delete()
+
+	return err
+}
@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/query"
+)
+
+// CreateVoucherBatch creates the batch together with a voucher for every code hash
+func CreateVoucherBatch(ctx context.Context, batch *model.VoucherBatch, codeHashes []string) error {
+	return query.Use(DB).Transaction(func(tx *query.Query) error {
+		if err := tx.VoucherBatch.WithContext(ctx).Create(batch); err != nil {
+			return err
+		}
+
+		vouchers := make([]*model.Voucher, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			vouchers = append(vouchers, &model.Voucher{
+				BatchID:  batch.ID,
+				CodeHash: codeHash,
+			})
+		}
+
+		return tx.Voucher.WithContext(ctx).CreateInBatches(vouchers, 100)
+	})
+}
+
+func GetVoucherBatches(ctx context.Context) ([]*model.VoucherBatch, error) {
+	b := query.Use(DB).VoucherBatch
+
+	return b.WithContext(ctx).Order(b.CreatedAt.Desc(), b.ID.Desc()).Find()
+}
+
+func GetVoucherBatchByID(ctx context.Context, batchID int32) (*model.VoucherBatch, error) {
+	b := query.Use(DB).VoucherBatch
+
+	return b.WithContext(ctx).Where(b.ID.Eq(batchID)).First()
+}
+
+// CountRedeemedVouchers returns the number of redeemed vouchers of every batch
+func CountRedeemedVouchers(ctx context.Context) (map[int32]int64, error) {
+	v := query.Use(DB).Voucher
+
+	var rows []struct {
+		BatchID  int32
+		Redeemed int64
+	}
+	err := v.WithContext(ctx).Select(v.BatchID, v.ID.Count().As("redeemed")).
+		Where(v.RedeemedBy.IsNotNull()).Group(v.BatchID).Scan(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[int32]int64, len(rows))
+	for _, row := range rows {
+		res[row.BatchID] = row.Redeemed
+	}
+
+	return res, nil
+}
+
+// RevokeVoucherBatch stops the codes of the batch that have not been redeemed yet
+func RevokeVoucherBatch(ctx context.Context, batchID int32, now time.Time) (bool, error) {
+	b := query.Use(DB).VoucherBatch
+
+	result, err := b.WithContext(ctx).Where(b.ID.Eq(batchID), b.RevokedAt.IsNull()).Update(b.RevokedAt, now)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func GetVoucherByCodeHash(ctx context.Context, codeHash string) (*model.Voucher, error) {
+	v := query.Use(DB).Voucher
+
+	return v.WithContext(ctx).Where(v.CodeHash.Eq(codeHash)).First()
+}
+
+// AnonymiseVouchers keeps the vouchers redeemed by a deleted user as redeemed by placeholder
+func AnonymiseVouchers(ctx context.Context, userID string, placeholder string) error {
+	v := query.Use(DB).Voucher
+
+	_, err := v.WithContext(ctx).Where(v.RedeemedBy.Eq(userID)).Update(v.RedeemedBy, placeholder)
+
+	return err
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,31 +9,40 @@ import (
 	"time"
 
 	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/service/sdto/errorx"
 	"api.backend.xjco2913/util"
 	"api.backend.xjco2913/util/config"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func VerifyToken() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		if ctx.Request.URL.Path == "/api/user/login" || ctx.Request.URL.Path == "/api/user/register" {
-			// login and register no need to auth token
-			ctx.Next()
-			return
-		}
+// Paths reachable without an access token
+var publicPaths = map[string]bool{
+	"/api/user/login":            true,
+	"/api/user/register":         true,
+	"/api/user/refresh":          true,
+	"/api/user/password/forgot":  true,
+	"/api/user/password/reset":   true,
+	"/api/user/email/verify":     true,
+	"/api/user/login/2fa":        true,
+	"/api/admin/login":           true,
+	"/api/admin/login/2fa":       true,
+	"/api/admin/login/2fa/setup": true,
+	"/api/admin/refresh":         true,
+	"/api/sso/providers":         true,
+	"/api/sso/login":             true,
+	"/api/sso/callback":          true,
+	"/api/mock/shareList":        true,
+}
 
-		if ctx.Request.URL.Path == "/api/admin/login" {
-			ctx.Next()
-			return
-		}
+// TokenValidator checks the server side state (revocation, session) of a token
+// whose signature and expiry have already been verified
+type TokenValidator func(ctx context.Context, claims jwt.MapClaims) *errorx.ServiceErr
 
-		if ctx.Request.URL.Path == "/api/notify/route" {
-			ctx.Next()
-			return
-		}
-
-		if ctx.Request.URL.Path == "/api/mock/shareList" {
+func VerifyToken(validate TokenValidator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if publicPaths[ctx.Request.URL.Path] {
+			// login, register and refresh no need to auth token
 			ctx.Next()
 			return
 		}
@@ -104,9 +114,18 @@ func VerifyToken() gin.HandlerFunc {
 			return
 		}
 
+		if sErr := validate(ctx.Request.Context(), claims); sErr != nil {
+			ctx.AbortWithStatusJSON(sErr.Code(), dto.CommonRes{
+				StatusCode: -1,
+				StatusMsg:  sErr.Error(),
+			})
+			return
+		}
+
 		// register token payload into context
 		var (
 			userID         string
+			adminID        string
 			isAdmin        bool
 			isOrganiser    bool
 			membershipType float64
@@ -117,13 +136,18 @@ func VerifyToken() gin.HandlerFunc {
 		} else {
 			userID = ""
 		}
-		
+
 		if val, ok := claims["isAdmin"]; ok {
 			isAdmin = val.(bool)
 		} else {
 			isAdmin = false
 		}
 
+		// only tokens issued by the admin login carry adminID
+		if val, ok := claims["adminID"]; ok && isAdmin {
+			adminID, _ = val.(string)
+		}
+
 		if val, ok := claims["isOrganiser"]; ok {
 			isOrganiser = val.(bool)
 		} else {
@@ -138,8 +162,12 @@ func VerifyToken() gin.HandlerFunc {
 
 		ctx.Set("userID", userID)
 		ctx.Set("isAdmin", isAdmin)
+		ctx.Set("adminID", adminID)
 		ctx.Set("isOrganiser", isOrganiser)
 		ctx.Set("membershipType", membershipType)
+		ctx.Set("sessionID", claims["sid"])
+		ctx.Set("tokenID", claims["jti"])
+		ctx.Set("tokenExp", expTime)
 
 		ctx.Next()
 	}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"net/http"
+
+	"api.backend.xjco2913/controller/dto"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"github.com/gin-gonic/gin"
+)
+
+// Roles derived from the claims registered by VerifyToken
+const (
+	ROLE_USER      = "user"
+	ROLE_MEMBER    = "member"
+	ROLE_ORGANISER = "organiser"
+	ROLE_ADMIN     = "admin"
+)
+
+type Permission string
+
+const (
+	PERM_USER_MANAGE     Permission = "user:manage"
+	PERM_ORG_REVIEW      Permission = "org:review"
+	PERM_ACTIVITY_CREATE Permission = "activity:create"
+	PERM_ACTIVITY_MANAGE Permission = "activity:manage"
+	PERM_REPORT_VIEW     Permission = "report:view"
+	PERM_VOUCHER_MANAGE  Permission = "voucher:manage"
+)
+
+// Permissions granted to each role, admin is granted every permission
+var rolePermissions = map[string][]Permission{
+	ROLE_USER:      {},
+	ROLE_MEMBER:    {},
+	ROLE_ORGANISER: {PERM_ACTIVITY_CREATE},
+	ROLE_ADMIN: {
+		PERM_USER_MANAGE,
+		PERM_ORG_REVIEW,
+		PERM_ACTIVITY_CREATE,
+		PERM_ACTIVITY_MANAGE,
+		PERM_REPORT_VIEW,
+		PERM_VOUCHER_MANAGE,
+	},
+}
+
+// OwnershipCheck reports whether userID owns the resource addressed by the request
+type OwnershipCheck func(c *gin.Context, userID string) (bool, *errorx.ServiceErr)
+
+// Roles returns the roles of the requester, it must run after VerifyToken
+func Roles(c *gin.Context) []string {
+	if c.GetBool("isAdmin") {
+		return []string{ROLE_ADMIN}
+	}
+
+	roles := []string{ROLE_USER}
+	if c.GetFloat64("membershipType") > 0 {
+		roles = append(roles, ROLE_MEMBER)
+	}
+	if c.GetBool("isOrganiser") {
+		roles = append(roles, ROLE_ORGANISER)
+	}
+
+	return roles
+}
+
+func HasRole(c *gin.Context, role string) bool {
+	for _, r := range Roles(c) {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+func HasPermission(c *gin.Context, perm Permission) bool {
+	for _, role := range Roles(c) {
+		for _, p := range rolePermissions[role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Viewer describes the requester to services enforcing privacy settings,
+// holders of PERM_USER_MANAGE see every profile
+func Viewer(c *gin.Context) *sdto.Viewer {
+	return &sdto.Viewer{
+		UserID:  c.GetString("userID"),
+		IsAdmin: HasPermission(c, PERM_USER_MANAGE),
+	}
+}
+
+// RequireRole only lets requesters holding at least one of roles through
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, role := range roles {
+			if HasRole(c, role) {
+				c.Next()
+				return
+			}
+		}
+
+		forbid(c, "Forbidden: Your role cannot access this resource")
+	}
+}
+
+// RequirePermission only lets requesters holding all of perms through
+func RequirePermission(perms ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, perm := range perms {
+			if !HasPermission(c, perm) {
+				forbid(c, "Forbidden: Missing permission "+string(perm))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireOwnership lets the owner of the resource through, requesters holding
+// bypass skip the check. An empty bypass means only the owner is allowed.
+func RequireOwnership(check OwnershipCheck, bypass Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if bypass != "" && HasPermission(c, bypass) {
+			c.Next()
+			return
+		}
+
+		userID := c.GetString("userID")
+		if userID == "" {
+			forbid(c, "Forbidden: User ID does not exist")
+			return
+		}
+
+		isOwner, sErr := check(c, userID)
+		if sErr != nil {
+			c.AbortWithStatusJSON(sErr.Code(), dto.CommonRes{
+				StatusCode: -1,
+				StatusMsg:  sErr.Error(),
+			})
+			return
+		}
+
+		if !isOwner {
+			forbid(c, "Forbidden: You are not the owner of this resource")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// QueryOwner treats the query parameter key as the id of the owning user
+func QueryOwner(key string) OwnershipCheck {
+	return func(c *gin.Context, userID string) (bool, *errorx.ServiceErr) {
+		return c.Query(key) == userID, nil
+	}
+}
+
+// FormOwner treats the form field key as the id of the owning user
+func FormOwner(key string) OwnershipCheck {
+	return func(c *gin.Context, userID string) (bool, *errorx.ServiceErr) {
+		return c.PostForm(key) == userID, nil
+	}
+}
+
+func forbid(c *gin.Context, msg string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, dto.CommonRes{
+		StatusCode: -1,
+		StatusMsg:  msg,
+	})
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newClaimsContext(isAdmin, isOrganiser bool, membershipType float64, userID string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?userID=u1", nil)
+
+	c.Set("userID", userID)
+	c.Set("isAdmin", isAdmin)
+	c.Set("isOrganiser", isOrganiser)
+	c.Set("membershipType", membershipType)
+
+	return c, w
+}
+
+func TestRoles(t *testing.T) {
+	testCases := []struct {
+		name        string
+		isAdmin     bool
+		isOrganiser bool
+		membership  float64
+		expected    []string
+	}{
+		{"plain user", false, false, 0, []string{ROLE_USER}},
+		{"member organiser", false, true, 2, []string{ROLE_USER, ROLE_MEMBER, ROLE_ORGANISER}},
+		{"admin", true, false, 0, []string{ROLE_ADMIN}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := newClaimsContext(tc.isAdmin, tc.isOrganiser, tc.membership, "u1")
+			actual := Roles(c)
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("Roles() = %v; expected %v", actual, tc.expected)
+			}
+			for i := range actual {
+				if actual[i] != tc.expected[i] {
+					t.Errorf("Roles() = %v; expected %v", actual, tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	c, w := newClaimsContext(false, true, 0, "u1")
+	RequirePermission(PERM_ACTIVITY_CREATE)(c)
+	if c.IsAborted() {
+		t.Errorf("organiser should be allowed to create activities, got %d", w.Code)
+	}
+
+	c, w = newClaimsContext(false, true, 0, "u1")
+	RequirePermission(PERM_USER_MANAGE)(c)
+	if !c.IsAborted() || w.Code != http.StatusForbidden {
+		t.Errorf("organiser should not manage users, got %d", w.Code)
+	}
+
+	c, _ = newClaimsContext(true, false, 0, "a1")
+	RequirePermission(PERM_USER_MANAGE, PERM_REPORT_VIEW)(c)
+	if c.IsAborted() {
+		t.Errorf("admin should hold every permission")
+	}
+}
+
+func TestRequireOwnership(t *testing.T) {
+	c, _ := newClaimsContext(false, false, 0, "u1")
+	RequireOwnership(QueryOwner("userID"), PERM_USER_MANAGE)(c)
+	if c.IsAborted() {
+		t.Errorf("owner should be allowed")
+	}
+
+	c, w := newClaimsContext(false, false, 0, "u2")
+	RequireOwnership(QueryOwner("userID"), PERM_USER_MANAGE)(c)
+	if !c.IsAborted() || w.Code != http.StatusForbidden {
+		t.Errorf("non-owner should be rejected, got %d", w.Code)
+	}
+
+	c, _ = newClaimsContext(true, false, 0, "a1")
+	RequireOwnership(QueryOwner("userID"), PERM_USER_MANAGE)(c)
+	if c.IsAborted() {
+		t.Errorf("admin should bypass the ownership check")
+	}
+
+	c, _ = newClaimsContext(true, false, 0, "a1")
+	RequireOwnership(QueryOwner("userID"), "")(c)
+	if !c.IsAborted() {
+		t.Errorf("an empty bypass should only allow the owner")
+	}
+}
+
+func TestViewer(t *testing.T) {
+	c, _ := newClaimsContext(false, false, 1, "u1")
+	if viewer := Viewer(c); viewer.UserID != "u1" || viewer.IsAdmin {
+		t.Errorf("Viewer() = %+v; expected user u1 without admin rights", viewer)
+	}
+
+	c, _ = newClaimsContext(true, false, 0, "")
+	if viewer := Viewer(c); !viewer.IsAdmin {
+		t.Errorf("Viewer() = %+v; expected an admin", viewer)
+	}
+}
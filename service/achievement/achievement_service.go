@@ -0,0 +1,284 @@
+package achievement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/notify"
+	"api.backend.xjco2913/service/privacy"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Badges are earned once, personal records are the best rides of a user and can be beaten.
+// Both are evaluated whenever a ride is recorded.
+const (
+	BADGE_FIRST_RIDE    = "first_ride"
+	BADGE_CENTURY       = "century"
+	BADGE_1000_KM       = "distance_1000"
+	BADGE_10_ACTIVITIES = "activities_10"
+	BADGE_STREAK_30     = "streak_30"
+)
+
+const (
+	RECORD_LONGEST_RIDE  = "longest_ride"
+	RECORD_FASTEST_10K   = "fastest_10k"
+	RECORD_BIGGEST_CLIMB = "biggest_climb"
+)
+
+// progress is what the badge rules look at
+type progress struct {
+	totals           *model.UserStat
+	activitiesJoined int64
+	// longest run of consecutive days with a ride
+	streak int
+}
+
+type rule struct {
+	badge  string
+	title  string
+	earned func(p *progress) bool
+}
+
+var rules = []rule{
+	{BADGE_FIRST_RIDE, "First ride", func(p *progress) bool {
+		return p.totals.RideCount >= 1
+	}},
+	{BADGE_CENTURY, "First 100 km ride", func(p *progress) bool {
+		return p.totals.LongestRide >= 100000
+	}},
+	{BADGE_1000_KM, "1,000 km ridden", func(p *progress) bool {
+		return p.totals.Distance >= 1000000
+	}},
+	{BADGE_10_ACTIVITIES, "10 activities joined", func(p *progress) bool {
+		return p.activitiesJoined >= 10
+	}},
+	{BADGE_STREAK_30, "30-day streak", func(p *progress) bool {
+		return p.streak >= 30
+	}},
+}
+
+type record struct {
+	name  string
+	title string
+	// best returns the best ride of the user other than excludeID
+	best func(ctx context.Context, userID string, excludeID int32) (*model.Ride, error)
+	// value returns false if the ride does not count for the record
+	value  func(ride *model.Ride) (float64, bool)
+	better func(a, b float64) bool
+	format func(value float64) string
+}
+
+var records = []record{
+	{
+		name:  RECORD_LONGEST_RIDE,
+		title: "Longest ride",
+		best:  dao.GetLongestRide,
+		value: func(ride *model.Ride) (float64, bool) {
+			return ride.Distance, ride.Distance > 0
+		},
+		better: func(a, b float64) bool { return a > b },
+		format: func(value float64) string {
+			return fmt.Sprintf("%.1f km", value/1000)
+		},
+	},
+	{
+		name:  RECORD_FASTEST_10K,
+		title: "Fastest 10 km",
+		best:  dao.GetFastest10K,
+		value: func(ride *model.Ride) (float64, bool) {
+			if ride.Best10k == nil {
+				return 0, false
+			}
+			return float64(*ride.Best10k), true
+		},
+		better: func(a, b float64) bool { return a < b },
+		format: func(value float64) string {
+			return (time.Duration(value) * time.Second).String()
+		},
+	},
+	{
+		name:  RECORD_BIGGEST_CLIMB,
+		title: "Biggest climb",
+		best:  dao.GetBiggestClimb,
+		value: func(ride *model.Ride) (float64, bool) {
+			return ride.ElevationGain, ride.ElevationGain > 0
+		},
+		better: func(a, b float64) bool { return a > b },
+		format: func(value float64) string {
+			return fmt.Sprintf("%.0f m", value)
+		},
+	},
+}
+
+type AchievementService struct{}
+
+var (
+	achievementService AchievementService
+)
+
+func Service() *AchievementService {
+	return &achievementService
+}
+
+// Evaluate awards the badges the user has earned and tells the user about them and about
+// the personal records the ride beat. Failures are logged, the ride is recorded anyway.
+func (a *AchievementService) Evaluate(ctx context.Context, ride *model.Ride) {
+	p, err := loadProgress(ctx, ride.UserID)
+	if err != nil {
+		zlog.Error("Failed to load achievement progress", zap.String("userID", ride.UserID), zap.Error(err))
+		return
+	}
+
+	for _, rule := range rules {
+		if !rule.earned(p) {
+			continue
+		}
+
+		awarded, err := dao.AwardBadge(ctx, &model.UserBadge{
+			UserID: ride.UserID,
+			Badge:  rule.badge,
+			RideID: &ride.ID,
+		})
+		if err != nil {
+			zlog.Error("Failed to award badge", zap.String("userID", ride.UserID), zap.String("badge", rule.badge), zap.Error(err))
+			continue
+		}
+		if awarded {
+			zlog.Info("Badge awarded", zap.String("userID", ride.UserID), zap.String("badge", rule.badge))
+			notify.Service().System(ctx, ride.UserID, fmt.Sprintf("You earned the %q badge.", rule.title))
+		}
+	}
+
+	for _, record := range records {
+		value, ok := record.value(ride)
+		if !ok {
+			continue
+		}
+
+		previous, err := record.best(ctx, ride.UserID, ride.ID)
+		if err != nil {
+			// the first ride that counts sets the record without a notification
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				zlog.Error("Failed to get personal record", zap.String("userID", ride.UserID), zap.String("record", record.name), zap.Error(err))
+			}
+			continue
+		}
+
+		previousValue, _ := record.value(previous)
+		if record.better(value, previousValue) {
+			notify.Service().System(ctx, ride.UserID, fmt.Sprintf("New personal record, %s: %s (was %s).",
+				record.title, record.format(value), record.format(previousValue)))
+		}
+	}
+}
+
+// Get returns the badges and personal records of any user whose profile the viewer may see
+func (a *AchievementService) Get(ctx context.Context, viewer *sdto.Viewer, userID string) (*sdto.AchievementsOutput, *errorx.ServiceErr) {
+	if _, sErr := privacy.Service().CanViewByID(ctx, viewer, userID); sErr != nil {
+		return nil, sErr
+	}
+
+	badges, sErr := a.Badges(ctx, userID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	res := &sdto.AchievementsOutput{
+		Badges:  badges,
+		Records: []*sdto.PersonalRecord{},
+	}
+	for _, record := range records {
+		best, err := record.best(ctx, userID, 0)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			zlog.Error("Failed to get personal record", zap.String("userID", userID), zap.String("record", record.name), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+
+		value, _ := record.value(best)
+		res.Records = append(res.Records, &sdto.PersonalRecord{
+			Record: record.name,
+			Title:  record.title,
+			Value:  value,
+			Source: best.Source,
+			RefID:  best.RefID,
+			RodeAt: best.RodeAt.Unix(),
+		})
+	}
+
+	return res, nil
+}
+
+// Badges returns the badges of the user in the order they were earned
+func (a *AchievementService) Badges(ctx context.Context, userID string) ([]*sdto.Badge, *errorx.ServiceErr) {
+	badges, err := dao.GetBadgesByUserID(ctx, userID)
+	if err != nil {
+		zlog.Error("Failed to get badges", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := make([]*sdto.Badge, 0, len(badges))
+	for _, badge := range badges {
+		record := &sdto.Badge{
+			Badge: badge.Badge,
+			Title: badgeTitle(badge.Badge),
+		}
+		if badge.AwardedAt != nil {
+			record.AwardedAt = badge.AwardedAt.Unix()
+		}
+		res = append(res, record)
+	}
+
+	return res, nil
+}
+
+func loadProgress(ctx context.Context, userID string) (*progress, error) {
+	totals, err := dao.GetUserStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	joined, err := dao.CountActivityUsersByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	times, err := dao.GetRideTimes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// days begin at midnight in the timezone of the reports
+	loc, err := util.LoadLocation(config.Get("report.timezone"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress{
+		totals:           totals,
+		activitiesJoined: joined,
+		streak:           util.LongestDayStreak(times, loc),
+	}, nil
+}
+
+func badgeTitle(badge string) string {
+	for _, rule := range rules {
+		if rule.badge == badge {
+			return rule.title
+		}
+	}
+
+	return badge
+}
@@ -1,9 +1,12 @@
 package activity
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,18 +14,27 @@ import (
 	"api.backend.xjco2913/dao"
 	"api.backend.xjco2913/dao/minio"
 	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/block"
 	"api.backend.xjco2913/service/gpx"
+	"api.backend.xjco2913/service/privacy"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/stats"
+	"api.backend.xjco2913/service/trending"
+	"api.backend.xjco2913/service/user"
 	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
 	"api.backend.xjco2913/util/zlog"
 	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 const (
-	ACTIVITY_FEED_LIMIT = 3
+	ACTIVITY_FEED_LIMIT     = 3
+	ACTIVITY_TRENDING_LIMIT = 10
+	REPORT_DATE_LAYOUT      = "2006-01-02"
 )
 
 type ActivityService struct{}
@@ -225,7 +237,30 @@ func (s *ActivityService) GetAll(ctx context.Context) ([]*sdto.GetAllActivityOut
 	return activityDtos, nil
 }
 
-func (s *ActivityService) GetByID(ctx context.Context, activityID string) (*sdto.GetActivityByIDOutput, *errorx.ServiceErr) {
+// IsCreator reports whether userID created every activity in activityIDs (separated by |).
+// IDs that do not exist are skipped, there is nothing to protect for them.
+func (s *ActivityService) IsCreator(ctx context.Context, activityIDs string, userID string) (bool, *errorx.ServiceErr) {
+	for _, id := range strings.Split(activityIDs, "|") {
+		activity, err := dao.GetActivityByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+
+			zlog.Error("Failed to retrieve activity by activity ID", zap.String("activityID", id), zap.Error(err))
+			return false, errorx.NewInternalErr()
+		}
+
+		if activity.CreatorID != userID {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// GetByID returns the activity with its participants, leaving out the ones blocked in either direction by the viewer
+func (s *ActivityService) GetByID(ctx context.Context, activityID string, viewer *sdto.Viewer) (*sdto.GetActivityByIDOutput, *errorx.ServiceErr) {
 	activity, err := dao.GetActivityByID(ctx, activityID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -278,8 +313,17 @@ func (s *ActivityService) GetByID(ctx context.Context, activityID string) (*sdto
 		return nil, errorx.NewInternalErr()
 	}
 
+	blocked, sErr := block.Service().Blocked(ctx, viewer.UserID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
 	var participantInfos []sdto.ParticipantInfo
 	for _, user := range participants {
+		if blocked.Hides(user.UserID) {
+			continue
+		}
+
 		// get avatar url from minio
 		avatarURL := ""
 		if user.AvatarURL != nil || !util.IsEmpty(user.AvatarURL) {
@@ -289,17 +333,26 @@ func (s *ActivityService) GetByID(ctx context.Context, activityID string) (*sdto
 			}
 		}
 
+		// the rest of the profile is shown like UserService.GetByID shows it
 		participantInfo := sdto.ParticipantInfo{
-			UserID:         user.UserID,
-			Username:       user.Username,
-			Gender:         user.Gender,
-			Region:         user.Region,
-			MembershipTime: user.MembershipTime,
-			AvatarURL:      avatarURL,
-			MembershipType: user.MembershipType,
+			UserID:    user.UserID,
+			Username:  user.Username,
+			AvatarURL: avatarURL,
+		}
+		visible, sErr := privacy.Service().CanView(ctx, viewer, user)
+		if sErr != nil {
+			return nil, sErr
 		}
-		if user.Birthday != nil {
-			participantInfo.Birthday = user.Birthday.Format(time.RFC822)
+		if visible {
+			participantInfo.Gender = user.Gender
+			participantInfo.Region = user.Region
+			if user.Birthday != nil && (privacy.IsSelf(viewer, user) || !user.HideBirthday) {
+				participantInfo.Birthday = user.Birthday.Format(time.RFC822)
+			}
+		}
+		if privacy.IsSelf(viewer, user) {
+			participantInfo.MembershipTime = user.MembershipTime
+			participantInfo.MembershipType = user.MembershipType
 		}
 
 		participantInfos = append(participantInfos, participantInfo)
@@ -370,6 +423,34 @@ func (s *ActivityService) Feed(ctx context.Context) (*sdto.ActivityFeedOutput, *
 		return nil, errorx.NewInternalErr()
 	}
 
+	return feedActivities(ctx, activitiesModels)
+}
+
+// Trending returns the activities trending everywhere, or in the region if one is given, best first
+func (s *ActivityService) Trending(ctx context.Context, region string) (*sdto.ActivityFeedOutput, *errorx.ServiceErr) {
+	ids, sErr := trending.Service().Top(ctx, trending.KIND_ACTIVITY, region, ACTIVITY_TRENDING_LIMIT)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	// in the order of the ranking, deleted activities are skipped
+	var activitiesModels []*model.Activity
+	for _, id := range ids {
+		activity, err := dao.GetActivityByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			zlog.Error("Error while get trending activity", zap.String("activityID", id), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+		activitiesModels = append(activitiesModels, activity)
+	}
+
+	return feedActivities(ctx, activitiesModels)
+}
+
+func feedActivities(ctx context.Context, activitiesModels []*model.Activity) (*sdto.ActivityFeedOutput, *errorx.ServiceErr) {
 	activities := make([]*sdto.ActivityFeed, len(activitiesModels))
 	for i, activity := range activitiesModels {
 		activities[i] = &sdto.ActivityFeed{
@@ -417,22 +498,49 @@ func (s *ActivityService) SignUpByActivityID(ctx context.Context, input *sdto.Si
 		return errorx.NewServicerErr(errorx.ErrExternal, "Ordinary user cannot sign up for paid activities", nil)
 	}
 
+	var userModel *model.User
+	if activity.Fee > 0 {
+		userModel, err = dao.GetUserByID(ctx, input.UserID)
+		if err != nil {
+			zlog.Error("Failed to retrieve user by ID", zap.String("userID", input.UserID), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+
+		if sErr := user.RequireVerifiedEmail(userModel); sErr != nil {
+			return sErr
+		}
+	}
+
 	finalFee := CalculateFinalFee(activity.Fee, input.MembershipType)
 	if finalFee == -1 {
 		zlog.Error("Invalid membership type or failed to calculate fee", zap.String("userID", input.UserID), zap.String("activityID", input.ActivityID))
 		return errorx.NewInternalErr()
 	}
 
+	var credits int32
+	if input.UseCredits && userModel != nil {
+		credits = min(userModel.Credits, finalFee)
+	}
+
 	newUserActivity := &model.ActivityUser{
 		ActivityID: input.ActivityID,
 		UserID:     input.UserID,
-		FinalFee:   finalFee,
+		FinalFee:   finalFee - credits,
 	}
-	err = dao.CreateActivityUser(ctx, newUserActivity)
+	created, err := dao.CreateActivityUserWithCredits(ctx, newUserActivity, credits)
 	if err != nil {
 		zlog.Error("Failed to create activity-user association", zap.String("userID", input.UserID), zap.String("activityID", input.ActivityID), zap.Error(err))
 		return errorx.NewInternalErr()
 	}
+	if !created {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Credits changed in the meantime, please try again", nil)
+	}
+	trending.Service().Activity(ctx, input.UserID, activity, trending.SIGNAL_SIGNUP)
+
+	// nothing was paid if credits or the membership discount covered the fee
+	if newUserActivity.FinalFee > 0 {
+		user.Service().RewardReferral(ctx, input.UserID)
+	}
 
 	return nil
 }
@@ -693,121 +801,281 @@ func (s *ActivityService) GetAllCounts(ctx context.Context) (*sdto.GetAllCountsO
 }
 
 func (s *ActivityService) GetProfitWithOption(ctx context.Context, op string) (*sdto.GetProfitOutput, *errorx.ServiceErr) {
-	profits := []int{}
-	dates := []string{}
-
+	// week => next 7 days, month => next 4 calendar weeks, year => next 12 calendar months
+	var (
+		interval string
+		count    int
+	)
 	switch op {
 	case "week":
-		now := time.Now()
-		for i := 0; i < 7; i++ {
-			endActivities, err := dao.GetActivitiesByEndDate(ctx, now)
-			if err != nil {
-				zlog.Error("Error while get activities by end date", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
+		interval, count = util.INTERVAL_DAY, 7
+	case "month":
+		interval, count = util.INTERVAL_WEEK, 4
+	case "year":
+		interval, count = util.INTERVAL_MONTH, 12
+	default:
+		return nil, errorx.NewServicerErr(
+			400,
+			"Invalid option",
+			nil,
+		)
+	}
 
-			totalProfit := 0
-			for _, endActivity := range endActivities {
-				activityUsers, err := dao.GetFinalFeesByActivityId(ctx, endActivity.ActivityID)
-				if err != nil {
-					zlog.Error("Error while get final fees by activity id", zap.Error(err))
-					return nil, errorx.NewInternalErr()
-				}
-
-				for _, au := range activityUsers {
-					totalProfit += int(au.FinalFee)
-				}
-			}
+	loc, sErr := reportLocation("")
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	start, err := util.StartOfInterval(time.Now(), interval, loc)
+	if err != nil {
+		zlog.Error("Error while get start of interval", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	end := start
+	for i := 0; i < count; i++ {
+		end, _ = util.NextInterval(end, interval)
+	}
 
-			profits = append(profits, totalProfit)
-			dates = append(dates, fmt.Sprintf("%s %d", now.Month(), now.Day()))
-			now = now.Add(24 * time.Hour)
+	buckets, err := util.CalendarBuckets(start, end, interval, loc)
+	if err != nil {
+		zlog.Error("Error while build calendar buckets", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	rows, err := dao.SumRevenueByBuckets(ctx, buckets, dao.REVENUE_GROUP_NONE)
+	if err != nil {
+		zlog.Error("Error while sum revenue by buckets", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	profits := make([]int, len(buckets))
+	for _, row := range rows {
+		profits[row.BucketIdx] = int(row.Revenue)
+	}
+
+	dates := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		if interval == util.INTERVAL_WEEK {
+			dates[i] = fmt.Sprintf("week %d", i+1)
+		} else {
+			dates[i] = bucketLabel(bucket, interval)
 		}
+	}
 
-	case "month":
-		now := time.Now()
-		for i := 0; i < 4; i++ {
-			startOfWeek := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-			endOfWeek := time.Date(
-				now.Add(7*24*time.Hour).Year(),
-				now.Add(7*24*time.Hour).Month(),
-				now.Add(7*24*time.Hour).Day(),
-				23, 59, 59, 999999999,
-				now.Add(7*24*time.Hour).Location(),
-			)
-
-			oneWeekActivities, err := dao.GetActivitiesWithinDateRange(ctx, startOfWeek, endOfWeek)
-			if err != nil {
-				zlog.Error("Error while get activities of the week", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
+	return &sdto.GetProfitOutput{
+		Profits: profits,
+		Dates:   dates,
+	}, nil
+}
 
-			totalProfit := 0
-			for _, activity := range oneWeekActivities {
-				aus, err := dao.GetFinalFeesByActivityId(ctx, activity.ActivityID)
-				if err != nil {
-					zlog.Error("Error while get final fees by activity id", zap.Error(err))
-					return nil, errorx.NewInternalErr()
-				}
-
-				for _, au := range aus {
-					totalProfit += int(au.FinalFee)
-				}
-			}
+func (s *ActivityService) RevenueReport(ctx context.Context, in *sdto.RevenueReportInput) (*sdto.RevenueReportOutput, *errorx.ServiceErr) {
+	loc, sErr := reportLocation(in.Timezone)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	start, err := time.ParseInLocation(REPORT_DATE_LAYOUT, in.StartDate, loc)
+	if err != nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid startDate, expected format "+REPORT_DATE_LAYOUT, nil)
+	}
+	end, err := time.ParseInLocation(REPORT_DATE_LAYOUT, in.EndDate, loc)
+	if err != nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid endDate, expected format "+REPORT_DATE_LAYOUT, nil)
+	}
+	// endDate is inclusive
+	end = end.AddDate(0, 0, 1)
+
+	buckets, err := util.CalendarBuckets(start, end, in.Interval, loc)
+	if err != nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, err.Error(), nil)
+	}
+
+	rows, err := dao.SumRevenueByBuckets(ctx, buckets, in.GroupBy)
+	if err != nil {
+		zlog.Error("Error while sum revenue by buckets", zap.Error(err), zap.String("groupBy", in.GroupBy))
+		return nil, errorx.NewInternalErr()
+	}
+	totals := rows
+	if in.GroupBy != dao.REVENUE_GROUP_NONE {
+		// an activity is in the group of each of its tags, the grand total counts it once
+		totals, err = dao.SumRevenueByBuckets(ctx, buckets, dao.REVENUE_GROUP_NONE)
+		if err != nil {
+			zlog.Error("Error while sum revenue by buckets", zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+	}
 
-			profits = append(profits, totalProfit)
-			dates = append(dates, fmt.Sprintf("week %d", i+1))
-			now = endOfWeek
+	res := &sdto.RevenueReportOutput{
+		Interval: in.Interval,
+		GroupBy:  in.GroupBy,
+		Timezone: loc.String(),
+		Buckets:  make([]*sdto.RevenueBucket, len(buckets)),
+	}
+	for i, bucket := range buckets {
+		res.Buckets[i] = &sdto.RevenueBucket{
+			Start: bucket.Start,
+			End:   bucket.End,
+			Label: bucketLabel(bucket, in.Interval),
 		}
+	}
+	res.Series, res.Total, res.Signups = revenueSeries(len(buckets), in.GroupBy, rows, totals)
 
-	case "year":
-		now := time.Now()
-		for i := 0; i < 12; i++ {
-			startOfMonth := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-			endOfMonth := time.Date(
-				now.Add(30*24*time.Hour).Year(),
-				now.Add(30*24*time.Hour).Month(),
-				now.Day(),
-				0, 0, 0, 0,
-				now.Add(30*24*time.Hour).Location(),
-			)
-
-			oneYearActivities, err := dao.GetActivitiesWithinDateRange(ctx, startOfMonth, endOfMonth)
-			if err != nil {
-				zlog.Error("Error while get activities of the week", zap.Error(err))
-				return nil, errorx.NewInternalErr()
+	return res, nil
+}
+
+// revenueSeries arranges the rows into one series per group, biggest earners first. The grand
+// totals are summed from the ungrouped totals rows, a group may share revenue with other groups.
+func revenueSeries(buckets int, groupBy string, rows, totals []*dao.RevenueRow) ([]*sdto.RevenueSeries, int64, int64) {
+	res := []*sdto.RevenueSeries{}
+	seriesMap := make(map[string]*sdto.RevenueSeries)
+	newSeries := func(key, name string) *sdto.RevenueSeries {
+		series := &sdto.RevenueSeries{
+			Key:     key,
+			Name:    name,
+			Revenue: make([]int64, buckets),
+			Signups: make([]int64, buckets),
+		}
+		seriesMap[key] = series
+		res = append(res, series)
+		return series
+	}
+	if groupBy == dao.REVENUE_GROUP_NONE {
+		newSeries("total", "Total")
+	}
+
+	for _, row := range rows {
+		key := row.GroupKey
+		if groupBy == dao.REVENUE_GROUP_NONE {
+			key = "total"
+		}
+
+		series, ok := seriesMap[key]
+		if !ok {
+			series = newSeries(key, row.GroupName)
+		}
+
+		series.Revenue[row.BucketIdx] += row.Revenue
+		series.Signups[row.BucketIdx] += row.Signups
+		series.Total += row.Revenue
+	}
+
+	// biggest earners first
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Total > res[j].Total
+	})
+
+	var total, signups int64
+	for _, row := range totals {
+		total += row.Revenue
+		signups += row.Signups
+	}
+
+	return res, total, signups
+}
+
+func (s *ActivityService) ExportRevenueReport(ctx context.Context, in *sdto.RevenueReportInput, format string) (*sdto.ExportRevenueReportOutput, *errorx.ServiceErr) {
+	report, sErr := s.RevenueReport(ctx, in)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	header := []interface{}{"Period Start", "Period End", "Period", "Group", "Group Name", "Signups", "Revenue"}
+	rows := [][]interface{}{}
+	for _, series := range report.Series {
+		for i, bucket := range report.Buckets {
+			rows = append(rows, []interface{}{
+				bucket.Start.Format(REPORT_DATE_LAYOUT),
+				bucket.End.AddDate(0, 0, -1).Format(REPORT_DATE_LAYOUT),
+				bucket.Label,
+				series.Key,
+				series.Name,
+				series.Signups[i],
+				series.Revenue[i],
+			})
+		}
+	}
+
+	fileName := fmt.Sprintf("revenue_%s_%s_%s.%s", in.StartDate, in.EndDate, report.Interval, format)
+	buf := bytes.NewBuffer(nil)
+
+	switch format {
+	case "csv":
+		w := csv.NewWriter(buf)
+		for _, row := range append([][]interface{}{header}, rows...) {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				record[i] = fmt.Sprint(cell)
 			}
+			w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			zlog.Error("Error while write revenue csv", zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
 
-			totalProfit := 0
-			for _, activity := range oneYearActivities {
-				aus, err := dao.GetFinalFeesByActivityId(ctx, activity.ActivityID)
-				if err != nil {
-					zlog.Error("Error while get final fees by activity id", zap.Error(err))
-					return nil, errorx.NewInternalErr()
-				}
-
-				for _, au := range aus {
-					totalProfit += int(au.FinalFee)
-				}
+		return &sdto.ExportRevenueReportOutput{
+			FileName:    fileName,
+			ContentType: "text/csv",
+			Data:        buf.Bytes(),
+		}, nil
+
+	case "xlsx":
+		f := excelize.NewFile()
+		defer f.Close()
+
+		sheet := "Revenue"
+		f.SetSheetName("Sheet1", sheet)
+		// numbers are kept numeric so finance can sum them in the sheet
+		for i, row := range append([][]interface{}{header}, rows...) {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+				zlog.Error("Error while write revenue xlsx row", zap.Error(err))
+				return nil, errorx.NewInternalErr()
 			}
+		}
 
-			profits = append(profits, totalProfit)
-			dates = append(dates, fmt.Sprintf("%v %v", now.Month(), now.Day()))
-			now = endOfMonth
+		if err := f.Write(buf); err != nil {
+			zlog.Error("Error while write revenue xlsx", zap.Error(err))
+			return nil, errorx.NewInternalErr()
 		}
 
+		return &sdto.ExportRevenueReportOutput{
+			FileName:    fileName,
+			ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			Data:        buf.Bytes(),
+		}, nil
+
 	default:
-		return nil, errorx.NewServicerErr(
-			400,
-			"Invalid option",
-			nil,
-		)
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Unsupported export format", nil)
 	}
+}
 
-	return &sdto.GetProfitOutput{
-		Profits: profits,
-		Dates:   dates,
-	}, nil
+// reportLocation returns the requested timezone, or the one configured by report.timezone
+func reportLocation(name string) (*time.Location, *errorx.ServiceErr) {
+	if util.IsEmpty(name) {
+		name = config.Get("report.timezone")
+	}
+
+	loc, err := util.LoadLocation(name)
+	if err != nil {
+		zlog.Warn("Unknown report timezone", zap.String("timezone", name))
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Unknown timezone: "+name, nil)
+	}
+
+	return loc, nil
+}
+
+func bucketLabel(bucket util.TimeBucket, interval string) string {
+	switch interval {
+	case util.INTERVAL_DAY:
+		return fmt.Sprintf("%s %d", bucket.Start.Month(), bucket.Start.Day())
+	case util.INTERVAL_WEEK:
+		last := bucket.End.AddDate(0, 0, -1)
+		return fmt.Sprintf("%s %d - %s %d", bucket.Start.Month(), bucket.Start.Day(), last.Month(), last.Day())
+	default:
+		return fmt.Sprintf("%s %d", bucket.Start.Month(), bucket.Start.Year())
+	}
 }
 
 func (s *ActivityService) UploadRoute(ctx context.Context, input *sdto.UploadRouteInput) *errorx.ServiceErr {
@@ -841,6 +1109,10 @@ func (s *ActivityService) UploadRoute(ctx context.Context, input *sdto.UploadRou
 		return errorx.NewInternalErr()
 	}
 
+	if sErr := stats.Service().RecordRide(ctx, input.UserID, dao.RIDE_ACTIVITY, input.ActivityID, parsedData); sErr != nil {
+		return sErr
+	}
+
 	return nil
 }
 
@@ -883,8 +1155,11 @@ func (s *ActivityService) GetRouteByIDs(ctx context.Context, input *sdto.GetRout
 		return nil, errorx.NewInternalErr()
 	}
 
-	// Convert the path text to 2D string slice
-	gpxRouteText := util.GPXStrTo2DString(pathText)
+	// Convert the path text to 2D string slice, without the privacy zones of the user
+	gpxRouteText, sErr := privacy.Service().TrimRoute(ctx, input.Viewer, input.UserID, util.GPXStrTo2DString(pathText))
+	if sErr != nil {
+		return nil, sErr
+	}
 
 	// Get user avatar url
 	var avatarUrl string
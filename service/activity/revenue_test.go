@@ -0,0 +1,48 @@
+package activity
+
+import (
+	"testing"
+
+	"api.backend.xjco2913/dao"
+)
+
+func TestRevenueSeriesMultiTagActivity(t *testing.T) {
+	// one activity tagged both road and gravel with two signups of 100 in the first bucket,
+	// the tag join returns it once per tag
+	rows := []*dao.RevenueRow{
+		{BucketIdx: 0, GroupKey: "1", GroupName: "road", Revenue: 200, Signups: 2},
+		{BucketIdx: 0, GroupKey: "2", GroupName: "gravel", Revenue: 200, Signups: 2},
+	}
+	totals := []*dao.RevenueRow{
+		{BucketIdx: 0, Revenue: 200, Signups: 2},
+		{BucketIdx: 1, Revenue: 0, Signups: 0},
+	}
+
+	series, total, signups := revenueSeries(2, dao.REVENUE_GROUP_TAG, rows, totals)
+	if total != 200 || signups != 2 {
+		t.Errorf("revenueSeries total = %d, %d signups; expected 200, 2 signups", total, signups)
+	}
+	if len(series) != 2 {
+		t.Fatalf("revenueSeries returned %d series; expected 2", len(series))
+	}
+	for _, s := range series {
+		if s.Total != 200 || s.Revenue[0] != 200 || s.Signups[0] != 2 {
+			t.Errorf("series %s = %d total, %v revenue, %v signups; expected 200 in the first bucket", s.Name, s.Total, s.Revenue, s.Signups)
+		}
+	}
+}
+
+func TestRevenueSeriesUngrouped(t *testing.T) {
+	rows := []*dao.RevenueRow{
+		{BucketIdx: 0, Revenue: 300, Signups: 3},
+		{BucketIdx: 1, Revenue: 100, Signups: 1},
+	}
+
+	series, total, signups := revenueSeries(2, dao.REVENUE_GROUP_NONE, rows, rows)
+	if total != 400 || signups != 4 {
+		t.Errorf("revenueSeries total = %d, %d signups; expected 400, 4 signups", total, signups)
+	}
+	if len(series) != 1 || series[0].Key != "total" || series[0].Total != 400 {
+		t.Errorf("revenueSeries = %+v; expected one total series of 400", series)
+	}
+}
@@ -2,15 +2,20 @@ package admin
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/redis"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	"api.backend.xjco2913/service/twofactor"
 	"api.backend.xjco2913/util"
 	"api.backend.xjco2913/util/zlog"
-	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -21,6 +26,11 @@ var (
 	localAdminService AdminService
 )
 
+const (
+	maxLoginAttempts = 5
+	lockDuration     = 3 * time.Minute
+)
+
 func Service() *AdminService {
 	return &localAdminService
 }
@@ -35,31 +45,136 @@ func (a *AdminService) Authenticate(ctx context.Context, in *sdto.AdminAuthentic
 				nil,
 			)
 		} else {
-			zlog.Error("Error while finding user by username", zap.String("admin-name", in.Name), zap.Error(err))
+			zlog.Error("Error while finding admin by name", zap.String("admin-name", in.Name), zap.Error(err))
 			return nil, errorx.NewInternalErr()
 		}
 	}
 
-	// verify password
-	if in.Password != admin.Password {
-		return nil, errorx.NewServicerErr(errorx.ErrExternal, "wrong password", nil)
+	// Admin keys are prefixed so that an admin and a user sharing a name do not lock each other out
+	attemptKey := fmt.Sprintf("WrongPwd:admin:%s", in.Name)
+	lockKey := fmt.Sprintf("lock:admin:%s", in.Name)
+
+	// Check if the admin is locked
+	lockedUntilStr, err := redis.RDB().Get(ctx, lockKey).Result()
+	if err == nil {
+		lockedUntil, err := time.Parse(time.RFC3339, lockedUntilStr)
+		if err == nil && time.Now().Before(lockedUntil) {
+			return nil, errorx.NewServicerErr(
+				errorx.ErrExternal,
+				fmt.Sprintf("Account is locked until %v", lockedUntil),
+				map[string]any{
+					"remaining_attempts": 0,
+					"lock_expires":       lockedUntil,
+				},
+			)
+		}
+	}
+
+	if a.verifyPassword(ctx, admin.ID, admin.Password, in.Password) {
+		redis.RDB().Del(ctx, attemptKey)
+		redis.RDB().Del(ctx, lockKey)
+	} else {
+		// Increment login attempt and check for lock condition
+		attempts, err := redis.RDB().Incr(ctx, attemptKey).Result()
+		if err != nil {
+			zlog.Error("Error incrementing admin login attempts", zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+		redis.RDB().Expire(ctx, attemptKey, lockDuration)
+
+		if attempts >= maxLoginAttempts {
+			lockExpiration := time.Now().Add(lockDuration)
+			err := redis.RDB().Set(ctx, lockKey, lockExpiration.Format(time.RFC3339), lockDuration).Err()
+			if err != nil {
+				zlog.Error("Error while set admin lock key", zap.Error(err))
+				return nil, errorx.NewInternalErr()
+			}
+
+			zlog.Warn("Admin account locked due to too many failed login attempts", zap.String("admin-name", in.Name))
+			return nil, errorx.NewServicerErr(
+				errorx.ErrExternal,
+				fmt.Sprintf("Account is locked until %v", lockExpiration),
+				map[string]any{
+					"remaining_attempts": 0,
+					"lock_expires":       lockExpiration,
+				},
+			)
+		}
+
+		zlog.Info("Invalid admin login attempt", zap.String("admin-name", in.Name))
+		return nil, errorx.NewServicerErr(
+			errorx.ErrExternal,
+			fmt.Sprintf("Invalid password, %d attempts remaining", maxLoginAttempts-attempts),
+			map[string]any{
+				"remaining_attempts": maxLoginAttempts - attempts,
+			},
+		)
 	}
 
-	// sign token
-	claims := jwt.MapClaims{
-		"userID": admin.ID,
-		"isAdmin": true,
-		"exp": time.Now().Add(24 * time.Hour).Unix(),
+	challenge, sErr := twofactor.Service().BeginLogin(ctx, admin.ID, true, &in.Device)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if challenge != nil {
+		return &sdto.AdminAuthenticateOuput{
+			AdminId:   admin.ID,
+			Name:      admin.Username,
+			Challenge: challenge,
+		}, nil
 	}
 
-	tokenStr, err := util.GenerateJWTToken(claims)
-	if err != nil {
-		return nil, errorx.NewInternalErr()
+	tokens, sErr := token.Service().Issue(ctx, admin.ID, true, &in.Device)
+	if sErr != nil {
+		return nil, sErr
 	}
 
 	return &sdto.AdminAuthenticateOuput{
-		Token: tokenStr,
-		AdminId: admin.ID,
-		Name: admin.Username,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		AdminId:      admin.ID,
+		Name:         admin.Username,
 	}, nil
-} 
\ No newline at end of file
+}
+
+func (a *AdminService) RefreshToken(ctx context.Context, refreshToken string, device *sdto.SessionDevice) (*sdto.RefreshTokenOutput, *errorx.ServiceErr) {
+	tokens, sErr := token.Service().Refresh(ctx, refreshToken, true, device)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return &sdto.RefreshTokenOutput{
+		NewToken:        tokens.AccessToken,
+		NewRefreshToken: tokens.RefreshToken,
+		ExpiresAt:       tokens.ExpiresAt,
+	}, nil
+}
+
+func (a *AdminService) Logout(ctx context.Context, adminID string, sessionID string, tokenID string, tokenExp time.Time) *errorx.ServiceErr {
+	return token.Service().Logout(ctx, adminID, sessionID, tokenID, tokenExp)
+}
+
+// verifyPassword checks the password against the stored bcrypt hash.
+// Rows created before hashing was introduced still hold the plaintext password,
+// those are compared in constant time once and upgraded to a hash on success.
+func (a *AdminService) verifyPassword(ctx context.Context, adminID, stored, password string) bool {
+	if strings.HasPrefix(stored, "$2") {
+		return util.VerifyPassword(stored, password)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(password)) != 1 {
+		return false
+	}
+
+	hashed, err := util.EncryptPassword(password)
+	if err != nil {
+		zlog.Error("Error while hashing legacy admin password", zap.String("adminID", adminID), zap.Error(err))
+		return true
+	}
+
+	if err := dao.UpdateAdminPassword(ctx, adminID, hashed); err != nil {
+		zlog.Error("Error while upgrading legacy admin password", zap.String("adminID", adminID), zap.Error(err))
+	}
+
+	return true
+}
@@ -0,0 +1,210 @@
+package block
+
+import (
+	"context"
+	"errors"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/minio"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/timeline"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Every service asks this one whether two users may interact and whose content to leave out,
+// the rules of blocks and mutes live only here:
+//   - a block hides the two users from each other, in both directions, and stops follows,
+//     likes, comments and route shares between them
+//   - a mute only hides the moments of the target from the feed of the user
+type BlockService struct{}
+
+var (
+	blockService BlockService
+)
+
+func Service() *BlockService {
+	return &blockService
+}
+
+// Filter holds the users whose content is left out for a user
+type Filter map[string]bool
+
+// Hides reports whether the content of the user is left out
+func (f Filter) Hides(userID string) bool {
+	return f[userID]
+}
+
+func (b *BlockService) Block(ctx context.Context, userID, targetID string) *errorx.ServiceErr {
+	if sErr := checkTarget(ctx, userID, targetID); sErr != nil {
+		return sErr
+	}
+
+	blocked, err := dao.BlockUser(ctx, userID, targetID)
+	if err != nil {
+		zlog.Error("Failed to block user", zap.String("userID", userID), zap.String("targetID", targetID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !blocked {
+		return errorx.NewServicerErr(errorx.ErrExternal, "User is already blocked", nil)
+	}
+
+	// the follows between them are gone
+	timeline.Service().Reset(ctx, userID, targetID)
+
+	zlog.Info("User blocked", zap.String("userID", userID), zap.String("targetID", targetID))
+
+	return nil
+}
+
+func (b *BlockService) Unblock(ctx context.Context, userID, targetID string) *errorx.ServiceErr {
+	return removeRelation(ctx, userID, targetID, dao.RELATION_BLOCK, "User is not blocked")
+}
+
+func (b *BlockService) Mute(ctx context.Context, userID, targetID string) *errorx.ServiceErr {
+	if sErr := checkTarget(ctx, userID, targetID); sErr != nil {
+		return sErr
+	}
+
+	muted, err := dao.MuteUser(ctx, userID, targetID)
+	if err != nil {
+		zlog.Error("Failed to mute user", zap.String("userID", userID), zap.String("targetID", targetID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !muted {
+		return errorx.NewServicerErr(errorx.ErrExternal, "User is already muted", nil)
+	}
+
+	return nil
+}
+
+func (b *BlockService) Unmute(ctx context.Context, userID, targetID string) *errorx.ServiceErr {
+	return removeRelation(ctx, userID, targetID, dao.RELATION_MUTE, "User is not muted")
+}
+
+// Blocks returns the users the user blocked, newest first
+func (b *BlockService) Blocks(ctx context.Context, userID string) ([]*sdto.RelatedUser, *errorx.ServiceErr) {
+	return list(ctx, userID, dao.RELATION_BLOCK)
+}
+
+// Mutes returns the users the user muted, newest first
+func (b *BlockService) Mutes(ctx context.Context, userID string) ([]*sdto.RelatedUser, *errorx.ServiceErr) {
+	return list(ctx, userID, dao.RELATION_MUTE)
+}
+
+func list(ctx context.Context, userID, kind string) ([]*sdto.RelatedUser, *errorx.ServiceErr) {
+	rows, err := dao.GetRelatedUsers(ctx, userID, kind)
+	if err != nil {
+		zlog.Error("Failed to get related users", zap.String("userID", userID), zap.String("kind", kind), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := make([]*sdto.RelatedUser, len(rows))
+	for i, row := range rows {
+		res[i] = &sdto.RelatedUser{
+			UserID:   row.UserID,
+			Username: row.Username,
+		}
+		if row.AvatarURL != nil && *row.AvatarURL != "" {
+			res[i].AvatarUrl, err = minio.GetUserAvatarUrl(ctx, *row.AvatarURL)
+			if err != nil {
+				zlog.Error("Error while get user avatar", zap.Error(err))
+				return nil, errorx.NewInternalErr()
+			}
+		}
+		if row.CreatedAt != nil {
+			res[i].CreatedAt = row.CreatedAt.Unix()
+		}
+	}
+
+	return res, nil
+}
+
+// Check returns a 403 error if either user blocked the other, an empty user is never blocked
+func (b *BlockService) Check(ctx context.Context, userID, otherID string) *errorx.ServiceErr {
+	blocked, sErr := b.IsBlocked(ctx, userID, otherID)
+	if sErr != nil {
+		return sErr
+	}
+	if blocked {
+		return errorx.NewServicerErr(403, "You cannot interact with this user", nil)
+	}
+
+	return nil
+}
+
+// IsBlocked reports whether either user blocked the other
+func (b *BlockService) IsBlocked(ctx context.Context, userID, otherID string) (bool, *errorx.ServiceErr) {
+	if userID == "" || otherID == "" || userID == otherID {
+		return false, nil
+	}
+
+	blocked, err := dao.IsBlockedBetween(ctx, userID, otherID)
+	if err != nil {
+		zlog.Error("Failed to check block", zap.String("userID", userID), zap.String("otherID", otherID), zap.Error(err))
+		return false, errorx.NewInternalErr()
+	}
+
+	return blocked, nil
+}
+
+// Blocked returns the users hidden from the user by blocks
+func (b *BlockService) Blocked(ctx context.Context, userID string) (Filter, *errorx.ServiceErr) {
+	return filter(ctx, userID, false)
+}
+
+// Hidden returns the users whose moments are left out of the feed of the user, by blocks and mutes
+func (b *BlockService) Hidden(ctx context.Context, userID string) (Filter, *errorx.ServiceErr) {
+	return filter(ctx, userID, true)
+}
+
+func filter(ctx context.Context, userID string, withMutes bool) (Filter, *errorx.ServiceErr) {
+	// admins see everything
+	if userID == "" {
+		return Filter{}, nil
+	}
+
+	ids, err := dao.GetHiddenUserIDs(ctx, userID, withMutes)
+	if err != nil {
+		zlog.Error("Failed to get hidden users", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := make(Filter, len(ids))
+	for _, id := range ids {
+		res[id] = true
+	}
+
+	return res, nil
+}
+
+func checkTarget(ctx context.Context, userID, targetID string) *errorx.ServiceErr {
+	if userID == targetID {
+		return errorx.NewServicerErr(errorx.ErrExternal, "You cannot block or mute yourself", nil)
+	}
+
+	if _, err := dao.GetUserByID(ctx, targetID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", targetID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+func removeRelation(ctx context.Context, userID, targetID, kind, notFound string) *errorx.ServiceErr {
+	removed, err := dao.DeleteRelation(ctx, userID, targetID, kind)
+	if err != nil {
+		zlog.Error("Failed to remove relation", zap.String("userID", userID), zap.String("targetID", targetID), zap.String("kind", kind), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !removed {
+		return errorx.NewServicerErr(errorx.ErrExternal, notFound, nil)
+	}
+
+	return nil
+}
@@ -3,17 +3,29 @@ package comment
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"api.backend.xjco2913/dao"
 	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/block"
+	"api.backend.xjco2913/service/notify"
+	"api.backend.xjco2913/service/privacy"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/trending"
+	"api.backend.xjco2913/util"
 	"api.backend.xjco2913/util/zlog"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// Replies are kept one level deep, a reply to a reply joins the thread of the top-level comment
+const (
+	// at most this many users are notified of the mentions in one comment
+	maxMentions = 10
+)
+
 type CommentService struct{}
 
 var (
@@ -25,7 +37,7 @@ func Service() *CommentService {
 }
 
 func (s *CommentService) Create(ctx context.Context, input *sdto.CreateCommentInput) *errorx.ServiceErr {
-	_, err := dao.GetMomentByID(ctx, input.MomentID)
+	moment, err := dao.GetMomentByID(ctx, input.MomentID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			zlog.Warn("Moment not found by moment ID", zap.String("momentID", input.MomentID))
@@ -36,6 +48,29 @@ func (s *CommentService) Create(ctx context.Context, input *sdto.CreateCommentIn
 		}
 	}
 
+	if sErr := block.Service().Check(ctx, input.AuthorID, moment.AuthorID); sErr != nil {
+		return sErr
+	}
+
+	var parentID *string
+	if !util.IsEmpty(input.ParentID) {
+		parent, sErr := getComment(ctx, input.ParentID)
+		if sErr != nil {
+			return sErr
+		}
+		if parent.MomentID != input.MomentID {
+			return errorx.NewServicerErr(errorx.ErrExternal, "The parent comment belongs to another moment", nil)
+		}
+		if sErr := block.Service().Check(ctx, input.AuthorID, parent.AuthorID); sErr != nil {
+			return sErr
+		}
+
+		parentID = &parent.CommentID
+		if parent.ParentID != nil {
+			parentID = parent.ParentID
+		}
+	}
+
 	// Generate a uuid for the new comment
 	uuid, err := uuid.NewUUID()
 	if err != nil {
@@ -49,11 +84,158 @@ func (s *CommentService) Create(ctx context.Context, input *sdto.CreateCommentIn
 		MomentID:  input.MomentID,
 		Content:   input.Content,
 		CommentID: commentID,
+		ParentID:  parentID,
 	})
 	if err != nil {
 		zlog.Error("Error while create new comment", zap.String("authorID", input.AuthorID), zap.String("momentID", input.MomentID), zap.Error(err))
 		return errorx.NewInternalErr()
 	}
+	trending.Service().Moment(ctx, input.AuthorID, moment, trending.SIGNAL_COMMENT)
+	s.notifyMentions(ctx, input.AuthorID, input.Content, nil)
+
+	return nil
+}
+
+// Edit replaces the content of a comment of the user, the earlier content is kept in its history.
+// Users mentioned for the first time are notified.
+func (s *CommentService) Edit(ctx context.Context, input *sdto.EditCommentInput) *errorx.ServiceErr {
+	comment, sErr := getComment(ctx, input.CommentID)
+	if sErr != nil {
+		return sErr
+	}
+	if comment.AuthorID != input.UserID {
+		return errorx.NewServicerErr(403, "Only the author can edit the comment", nil)
+	}
+	if comment.Content == input.Content {
+		return nil
+	}
+
+	if err := dao.EditComment(ctx, comment, input.Content); err != nil {
+		zlog.Error("Error while edit comment", zap.String("commentID", input.CommentID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	s.notifyMentions(ctx, input.UserID, input.Content, util.ParseMentions(comment.Content))
+
+	return nil
+}
+
+// History returns the earlier versions of a comment, oldest first, if the viewer may see the
+// profile of the author of the moment
+func (s *CommentService) History(ctx context.Context, viewer *sdto.Viewer, commentID string) ([]*sdto.CommentEdit, *errorx.ServiceErr) {
+	comment, sErr := getComment(ctx, commentID)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if sErr := block.Service().Check(ctx, viewer.UserID, comment.AuthorID); sErr != nil {
+		return nil, sErr
+	}
+
+	moment, err := dao.GetMomentByID(ctx, comment.MomentID)
+	if err != nil {
+		zlog.Error("Failed to retrieve moment by moment ID", zap.String("momentID", comment.MomentID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if _, sErr := privacy.Service().CanViewByID(ctx, viewer, moment.AuthorID); sErr != nil {
+		return nil, sErr
+	}
+
+	edits, err := dao.GetCommentEdits(ctx, commentID)
+	if err != nil {
+		zlog.Error("Error while get comment edits", zap.String("commentID", commentID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := make([]*sdto.CommentEdit, len(edits))
+	for i, edit := range edits {
+		res[i] = &sdto.CommentEdit{
+			Content:  edit.Content,
+			EditedAt: edit.EditedAt,
+		}
+	}
+
+	return res, nil
+}
+
+// Delete removes a comment, deleting a top-level comment removes its replies as well.
+// The author, the author of the moment and admins may delete a comment.
+func (s *CommentService) Delete(ctx context.Context, viewer *sdto.Viewer, commentID string) *errorx.ServiceErr {
+	comment, sErr := getComment(ctx, commentID)
+	if sErr != nil {
+		return sErr
+	}
+
+	if !viewer.IsAdmin && comment.AuthorID != viewer.UserID {
+		moment, err := dao.GetMomentByID(ctx, comment.MomentID)
+		if err != nil {
+			zlog.Error("Failed to retrieve moment by moment ID", zap.String("momentID", comment.MomentID), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+		if moment.AuthorID != viewer.UserID {
+			return errorx.NewServicerErr(403, "Only the author of the comment or the moment can delete the comment", nil)
+		}
+	}
+
+	if err := dao.DeleteCommentThread(ctx, comment); err != nil {
+		zlog.Error("Error while delete comment", zap.String("commentID", commentID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
 
 	return nil
 }
+
+// notifyMentions tells the users mentioned in the content, except the ones in notified,
+// the author and users blocked either way. Failures are logged, the comment is kept anyway.
+func (s *CommentService) notifyMentions(ctx context.Context, authorID string, content string, notified []string) {
+	names := []string{}
+	skip := map[string]bool{}
+	for _, name := range notified {
+		skip[name] = true
+	}
+	for _, name := range util.ParseMentions(content) {
+		if !skip[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) > maxMentions {
+		names = names[:maxMentions]
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	author, err := dao.GetUserByID(ctx, authorID)
+	if err != nil {
+		zlog.Error("Error while get comment author", zap.String("authorID", authorID), zap.Error(err))
+		return
+	}
+	users, err := dao.GetUsersByUsernames(ctx, names)
+	if err != nil {
+		zlog.Error("Error while get mentioned users", zap.Strings("usernames", names), zap.Error(err))
+		return
+	}
+
+	for _, user := range users {
+		if user.UserID == authorID {
+			continue
+		}
+		if blocked, sErr := block.Service().IsBlocked(ctx, authorID, user.UserID); sErr != nil || blocked {
+			continue
+		}
+
+		notify.Service().System(ctx, user.UserID, fmt.Sprintf("%s mentioned you in a comment.", author.Username))
+	}
+}
+
+func getComment(ctx context.Context, commentID string) (*model.Comment, *errorx.ServiceErr) {
+	comment, err := dao.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "Comment not found", nil)
+		}
+
+		zlog.Error("Error while get comment", zap.String("commentID", commentID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return comment, nil
+}
@@ -2,13 +2,31 @@ package friend
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"api.backend.xjco2913/dao"
 	"api.backend.xjco2913/dao/minio"
+	"api.backend.xjco2913/service/block"
+	"api.backend.xjco2913/service/notify"
+	"api.backend.xjco2913/service/privacy"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/timeline"
 	"api.backend.xjco2913/util/zlog"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	FOLLOW_STATUS_FOLLOWING = "following"
+	FOLLOW_STATUS_REQUESTED = "requested"
+)
+
+// Follow lists are paged, pageSize defaults to defaultPageSize
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
 )
 
 type FriendService struct{}
@@ -21,138 +39,260 @@ func Service() *FriendService {
 	return &friendService
 }
 
-func (f *FriendService) Follow(ctx context.Context, in *sdto.FollowInput) *errorx.ServiceErr {
+// Follow follows the user, or asks the user to approve if the user approves followers
+func (f *FriendService) Follow(ctx context.Context, in *sdto.FollowInput) (*sdto.FollowOutput, *errorx.ServiceErr) {
+	if in.FollowerId == in.FollowingId {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "You cannot follow yourself", nil)
+	}
+
 	// Check if the user to follow exist or not
-	if !dao.IsUserExisted(ctx, in.FollowingId) {
-		return errorx.NewServicerErr(
-			errorx.ErrExternal,
-			"Following user is not found",
-			nil,
-		)
+	following, err := dao.GetUserByID(ctx, in.FollowingId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.NewServicerErr(
+				errorx.ErrExternal,
+				"Following user is not found",
+				nil,
+			)
+		}
+		zlog.Error("Error while get following user", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	if sErr := block.Service().Check(ctx, in.FollowerId, in.FollowingId); sErr != nil {
+		return nil, sErr
+	}
+
+	switch following.FollowPolicy {
+	case privacy.FOLLOW_NOBODY:
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "This user does not accept followers", nil)
+	case privacy.FOLLOW_APPROVAL:
+		return f.requestFollow(ctx, in)
 	}
 
 	// Follow
-	err := dao.FollowById(ctx, in.FollowerId, in.FollowingId)
+	followed, err := dao.FollowById(ctx, in.FollowerId, in.FollowingId)
 	if err != nil {
 		zlog.Error("Error while store new follow relation", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if !followed {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Already following this user", nil)
+	}
+	timeline.Service().Reset(ctx, in.FollowerId)
+
+	return &sdto.FollowOutput{Status: FOLLOW_STATUS_FOLLOWING}, nil
+}
+
+func (f *FriendService) requestFollow(ctx context.Context, in *sdto.FollowInput) (*sdto.FollowOutput, *errorx.ServiceErr) {
+	isFollowed, err := dao.CheckIsFollowed(ctx, in.FollowerId, in.FollowingId)
+	if err != nil {
+		zlog.Error("Error while check is followed", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if isFollowed {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Already following this user", nil)
+	}
+
+	_, created, err := dao.RequestFollow(ctx, in.FollowerId, in.FollowingId)
+	if err != nil {
+		zlog.Error("Error while store follow request", zap.String("userID", in.FollowerId), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if !created {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Follow request already sent", nil)
+	}
+
+	follower, err := dao.GetUserByID(ctx, in.FollowerId)
+	if err != nil {
+		zlog.Error("Error while get follower", zap.String("userID", in.FollowerId), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	notify.Service().System(ctx, in.FollowingId, fmt.Sprintf("%s asked to follow you.", follower.Username))
+
+	return &sdto.FollowOutput{Status: FOLLOW_STATUS_REQUESTED}, nil
+}
+
+// Unfollow stops following the user, or withdraws the request to follow
+func (f *FriendService) Unfollow(ctx context.Context, in *sdto.FollowInput) *errorx.ServiceErr {
+	unfollowed, err := dao.UnfollowById(ctx, in.FollowerId, in.FollowingId)
+	if err != nil {
+		zlog.Error("Error while delete follow relation", zap.Error(err))
 		return errorx.NewInternalErr()
 	}
+	if unfollowed {
+		timeline.Service().Reset(ctx, in.FollowerId)
+		return nil
+	}
+
+	cancelled, err := dao.CancelFollowRequest(ctx, in.FollowerId, in.FollowingId)
+	if err != nil {
+		zlog.Error("Error while cancel follow request", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !cancelled {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Not following this user", nil)
+	}
 
 	return nil
 }
 
-func (f *FriendService) GetAllFollower(ctx context.Context, userId string) (*sdto.GetAllFollowerOutput, *errorx.ServiceErr) {
-	followers, err := dao.GetFollowersByUserID(ctx, userId)
+// FollowRequests returns the requests waiting for the user to decide, newest first
+func (f *FriendService) FollowRequests(ctx context.Context, userId string, page, pageSize int) ([]*sdto.FollowRequest, *errorx.ServiceErr) {
+	offset, limit := pageBounds(page, pageSize)
+
+	rows, err := dao.GetPendingFollowRequests(ctx, userId, offset, limit)
 	if err != nil {
-		zlog.Error("Error while get all followers by user ID", zap.Error(err))
+		zlog.Error("Error while get follow requests", zap.String("userID", userId), zap.Error(err))
 		return nil, errorx.NewInternalErr()
 	}
 
-	// Get avatar
-	res := make([]*sdto.Follower, len(followers))
-	for i, follower := range followers {
-		var avatarUrl string
-		if follower.AvatarURL != nil && *follower.AvatarURL != "" {
-			avatarUrl, err = minio.GetUserAvatarUrl(ctx, *follower.AvatarURL)
-			if err != nil {
-				zlog.Error("Error while get user avatar", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
+	res := make([]*sdto.FollowRequest, len(rows))
+	for i, row := range rows {
+		avatarUrl, sErr := avatar(ctx, row.AvatarURL)
+		if sErr != nil {
+			return nil, sErr
 		}
 
-		// Check if isFollowed
-		isFollowed, err := dao.CheckIsFollowed(ctx, userId, follower.UserID)
-		if err != nil {
-			zlog.Error("Error while check if follow follower or not", zap.Error(err))
-			return nil, errorx.NewInternalErr()
+		res[i] = &sdto.FollowRequest{
+			RequestID: row.RequestID,
+			UserID:    row.UserID,
+			AvatarUrl: avatarUrl,
+			Username:  row.Username,
+			Region:    row.Region,
+		}
+		if row.RequestedAt != nil {
+			res[i].RequestedAt = row.RequestedAt.Unix()
 		}
-
-		res[i] = &sdto.Follower{}
-		res[i].UserID = follower.UserID
-		res[i].Username = follower.Username
-		res[i].AvatarUrl = avatarUrl
-		res[i].Region = follower.Region
-		res[i].IsFollowed = isFollowed
 	}
 
-	return &sdto.GetAllFollowerOutput{
-		Followers: res,
-	}, nil
+	return res, nil
 }
 
-func (f *FriendService) GetAllFollowing(ctx context.Context, userId string) (*sdto.GetAllFollowingOutput, *errorx.ServiceErr) {
-	followings, err := dao.GetFollowingsByUserID(ctx, userId)
+// AcceptFollowRequest lets the requester follow the user and tells the requester
+func (f *FriendService) AcceptFollowRequest(ctx context.Context, userId string, requestId int32) *errorx.ServiceErr {
+	return f.decide(ctx, userId, requestId, dao.FOLLOW_REQUEST_ACCEPTED)
+}
+
+// DeclineFollowRequest turns the request down, the requester may ask again
+func (f *FriendService) DeclineFollowRequest(ctx context.Context, userId string, requestId int32) *errorx.ServiceErr {
+	return f.decide(ctx, userId, requestId, dao.FOLLOW_REQUEST_DECLINED)
+}
+
+func (f *FriendService) decide(ctx context.Context, userId string, requestId int32, status string) *errorx.ServiceErr {
+	request, err := dao.GetFollowRequestByID(ctx, requestId)
 	if err != nil {
-		zlog.Error("Error while get followings by user id", zap.Error(err))
-		return nil, errorx.NewInternalErr()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Follow request not found", nil)
+		}
+		zlog.Error("Error while get follow request", zap.Int32("requestID", requestId), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	// requests for other users are not found either
+	if request.TargetID != userId {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Follow request not found", nil)
 	}
 
-	// Get avatar
-	for _, following := range followings {
-		if following.AvatarURL == nil || *following.AvatarURL == "" {
-			continue
-		}
-		avatarUrl, err := minio.GetUserAvatarUrl(ctx, *following.AvatarURL)
+	decided, err := dao.DecideFollowRequest(ctx, request, status)
+	if err != nil {
+		zlog.Error("Error while decide follow request", zap.Int32("requestID", requestId), zap.String("status", status), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !decided {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Follow request is already decided", nil)
+	}
+
+	if status == dao.FOLLOW_REQUEST_ACCEPTED {
+		timeline.Service().Reset(ctx, request.RequesterID)
+
+		target, err := dao.GetUserByID(ctx, userId)
 		if err != nil {
-			zlog.Error("Error while get user avatar", zap.Error(err))
-			return nil, errorx.NewInternalErr()
+			zlog.Error("Error while get user", zap.String("userID", userId), zap.Error(err))
+			return errorx.NewInternalErr()
 		}
+		notify.Service().System(ctx, request.RequesterID, fmt.Sprintf("%s accepted your follow request.", target.Username))
+	}
+
+	return nil
+}
 
-		following.AvatarURL = &avatarUrl
+// GetAllFollower returns a page of the followers of the user if the viewer may see the profile
+// of the user, isFollowed tells whether the viewer follows them
+func (f *FriendService) GetAllFollower(ctx context.Context, in *sdto.FollowPageInput) (*sdto.GetAllFollowerOutput, *errorx.ServiceErr) {
+	res, sErr := f.page(ctx, in, dao.GetFollowersPage)
+	if sErr != nil {
+		return nil, sErr
 	}
 
-	return &sdto.GetAllFollowingOutput{
-		Followings: followings,
+	return &sdto.GetAllFollowerOutput{
+		Followers: res,
 	}, nil
 }
 
-func (f *FriendService) GetAll(ctx context.Context, userId string) (*sdto.GetAllFriendsOutput, *errorx.ServiceErr) {
-	friends, err := dao.GetFriendsByUserID(ctx, userId)
-	if err != nil {
-		zlog.Error("Error while get user friends", zap.Error(err))
-		return nil, errorx.NewInternalErr()
+// GetAllFollowing returns a page of who the user follows if the viewer may see the profile of the user
+func (f *FriendService) GetAllFollowing(ctx context.Context, in *sdto.FollowPageInput) (*sdto.GetAllFollowingOutput, *errorx.ServiceErr) {
+	res, sErr := f.page(ctx, in, dao.GetFollowingsPage)
+	if sErr != nil {
+		return nil, sErr
 	}
 
-	// Get avatar
-	for _, friend := range friends {
-		if friend.AvatarURL == nil || *friend.AvatarURL == "" {
-			continue
-		}
-		avatarUrl, err := minio.GetUserAvatarUrl(ctx, *friend.AvatarURL)
-		if err != nil {
-			zlog.Error("Error while get user avatar", zap.Error(err))
-			return nil, errorx.NewInternalErr()
-		}
+	return &sdto.GetAllFollowingOutput{
+		Followings: res,
+	}, nil
+}
 
-		friend.AvatarURL = &avatarUrl
+// GetAll returns a page of the friends of the user, the users who follow each other with the user
+func (f *FriendService) GetAll(ctx context.Context, in *sdto.FollowPageInput) (*sdto.GetAllFriendsOutput, *errorx.ServiceErr) {
+	res, sErr := f.page(ctx, in, dao.GetFriendsPage)
+	if sErr != nil {
+		return nil, sErr
 	}
 
 	return &sdto.GetAllFriendsOutput{
-		Friends: friends,
+		Friends: res,
 	}, nil
 }
 
+func (f *FriendService) page(
+	ctx context.Context,
+	in *sdto.FollowPageInput,
+	list func(ctx context.Context, userId, viewerId string, offset, limit int) ([]*dao.FollowRow, error),
+) ([]*sdto.Follower, *errorx.ServiceErr) {
+	if _, sErr := privacy.Service().CanViewByID(ctx, in.Viewer, in.UserID); sErr != nil {
+		return nil, sErr
+	}
+
+	offset, limit := pageBounds(in.Page, in.PageSize)
+	rows, err := list(ctx, in.UserID, viewerOrOwner(in.Viewer, in.UserID), offset, limit)
+	if err != nil {
+		zlog.Error("Error while get follow list", zap.String("userID", in.UserID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return toFollowers(ctx, rows)
+}
+
 func (f *FriendService) GetFollowerCount(ctx context.Context, userID string) (*sdto.FollowerCountOutput, *errorx.ServiceErr) {
-	followers, err := dao.GetFollowersByUserID(ctx, userID)
+	user, err := dao.GetUserByID(ctx, userID)
 	if err != nil {
-		zlog.Error("Failed to retrieve followers", zap.String("userID", userID), zap.Error(err))
+		zlog.Error("Failed to retrieve follower count", zap.String("userID", userID), zap.Error(err))
 		return nil, errorx.NewInternalErr()
 	}
 
 	return &sdto.FollowerCountOutput{
-		Count: len(followers),
+		Count: int(user.FollowerCount),
 	}, nil
 }
 
 func (f *FriendService) GetFollowingCount(ctx context.Context, userID string) (*sdto.FollowingCountOutput, *errorx.ServiceErr) {
-	followings, err := dao.GetFollowingsByUserID(ctx, userID)
+	user, err := dao.GetUserByID(ctx, userID)
 	if err != nil {
-		zlog.Error("Failed to retrieve followings", zap.String("userID", userID), zap.Error(err))
+		zlog.Error("Failed to retrieve following count", zap.String("userID", userID), zap.Error(err))
 		return nil, errorx.NewInternalErr()
 	}
 
 	return &sdto.FollowingCountOutput{
-		Count: len(followings),
+		Count: int(user.FollowingCount),
 	}, nil
 }
 
@@ -164,4 +304,66 @@ func (f *FriendService) IsFollowed(ctx context.Context, userId, otherId string)
 	}
 
 	return isFollowed, nil
-}
\ No newline at end of file
+}
+
+// viewerOrOwner returns whose follows decide isFollowed, admins see the ones of the owner
+func viewerOrOwner(viewer *sdto.Viewer, ownerID string) string {
+	if viewer.UserID != "" {
+		return viewer.UserID
+	}
+
+	return ownerID
+}
+
+// pageBounds turns a page counted from 1 into an offset and a limit
+func pageBounds(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	return (page - 1) * pageSize, pageSize
+}
+
+// toFollowers keeps what any user may see of users in a follow list
+func toFollowers(ctx context.Context, rows []*dao.FollowRow) ([]*sdto.Follower, *errorx.ServiceErr) {
+	res := make([]*sdto.Follower, len(rows))
+	for i, row := range rows {
+		avatarUrl, sErr := avatar(ctx, row.AvatarURL)
+		if sErr != nil {
+			return nil, sErr
+		}
+
+		res[i] = &sdto.Follower{
+			UserID:     row.UserID,
+			Username:   row.Username,
+			AvatarUrl:  avatarUrl,
+			Region:     row.Region,
+			IsFollowed: row.IsFollowed,
+		}
+		if row.FollowedAt != nil {
+			res[i].FollowedAt = row.FollowedAt.Unix()
+		}
+	}
+
+	return res, nil
+}
+
+func avatar(ctx context.Context, key *string) (string, *errorx.ServiceErr) {
+	if key == nil || *key == "" {
+		return "", nil
+	}
+
+	url, err := minio.GetUserAvatarUrl(ctx, *key)
+	if err != nil {
+		zlog.Error("Error while get user avatar", zap.Error(err))
+		return "", errorx.NewInternalErr()
+	}
+
+	return url, nil
+}
@@ -35,6 +35,12 @@ func (g *GPXService) ParseGPXData(ctx context.Context, in *sdto.ParseGPXDataInpu
 		)
 	}
 
+	// measured now, the stored linestring keeps no elevation
+	summary, err := util.GPXSummary(in.GPXData)
+	if err != nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid gpx format", nil)
+	}
+
 	linestring := gpxLonLatData[0]
 	for i := 1; i < len(gpxLonLatData); i++ {
 		linestring += ", "
@@ -60,7 +66,11 @@ func (g *GPXService) ParseGPXData(ctx context.Context, in *sdto.ParseGPXDataInpu
 	}
 
 	return &sdto.ParseGPXDataOutput{
-		RouteID: lastGPXRoute.ID,
+		RouteID:       lastGPXRoute.ID,
+		Distance:      summary.Distance,
+		ElevationGain: summary.ElevationGain,
+		StartTime:     summary.StartTime,
+		Best10K:       summary.Best10K,
 	}, nil
 }
 
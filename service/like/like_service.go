@@ -6,8 +6,10 @@ import (
 
 	"api.backend.xjco2913/dao"
 	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/block"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/trending"
 	"api.backend.xjco2913/util/zlog"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -24,7 +26,7 @@ func Service() *LikeService {
 }
 
 func (s *LikeService) Create(ctx context.Context, input *sdto.CreateLikeInput) *errorx.ServiceErr {
-	_, err := dao.GetMomentByID(ctx, input.MomentID)
+	moment, err := dao.GetMomentByID(ctx, input.MomentID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			zlog.Warn("Moment not found by moment ID", zap.String("momentID", input.MomentID))
@@ -35,6 +37,10 @@ func (s *LikeService) Create(ctx context.Context, input *sdto.CreateLikeInput) *
 		}
 	}
 
+	if sErr := block.Service().Check(ctx, input.UserID, moment.AuthorID); sErr != nil {
+		return sErr
+	}
+
 	_, err = dao.GetLikeByIDs(ctx, input.UserID, input.MomentID)
 	if err != gorm.ErrRecordNotFound {
 		zlog.Error("User already liked this moment", zap.String("userID", input.UserID), zap.String("momentID", input.MomentID))
@@ -53,6 +59,7 @@ func (s *LikeService) Create(ctx context.Context, input *sdto.CreateLikeInput) *
 		zlog.Error("Error while create new like", zap.String("userID", input.UserID), zap.String("momentID", input.MomentID), zap.Error(err))
 		return errorx.NewInternalErr()
 	}
+	trending.Service().Moment(ctx, input.UserID, moment, trending.SIGNAL_LIKE)
 
 	return nil
 }
@@ -0,0 +1,548 @@
+package membership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/notify"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	userService "api.backend.xjco2913/service/user"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/mailer"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	ACTION_SUBSCRIBE = "subscribe"
+	ACTION_RENEW     = "renew"
+	ACTION_UPGRADE   = "upgrade"
+	ACTION_DOWNGRADE = "downgrade"
+	ACTION_CANCEL    = "cancel"
+	ACTION_EXPIRE    = "expire"
+	ACTION_VOUCHER   = "voucher"
+	ACTION_TRIAL     = "trial"
+)
+
+const (
+	// unused time is refunded if the membership is cancelled within this after a payment
+	refundWindow = 7 * 24 * time.Hour
+	// period of membership types missing from the config
+	defaultPeriod = 30 * 24 * time.Hour
+
+	sweepBatchSize = 100
+
+	// membershipReminder:<userID>:<membershipTime> => set once the reminder has been sent
+	reminderKeyFmt = "membershipReminder:%s:%d"
+)
+
+type plan struct {
+	name           string
+	membershipType int32
+	price          int32
+	period         time.Duration
+}
+
+type MembershipService struct{}
+
+var (
+	membershipService MembershipService
+
+	plansOnce sync.Once
+	plans     map[int32]*plan
+)
+
+func Service() *MembershipService {
+	return &membershipService
+}
+
+// loadPlans reads membership.plans from the config once
+func loadPlans() map[int32]*plan {
+	plansOnce.Do(func() {
+		plans = make(map[int32]*plan)
+
+		for _, name := range config.Keys("membership.plans") {
+			prefix := "membership.plans." + name
+
+			membershipType, err1 := strconv.Atoi(config.Get(prefix + ".type"))
+			price, err2 := strconv.Atoi(config.Get(prefix + ".price"))
+			days, err3 := strconv.Atoi(config.Get(prefix + ".period"))
+			if err := errors.Join(err1, err2, err3); err != nil || membershipType <= 0 || days <= 0 {
+				zlog.Error("Invalid membership plan in config", zap.String("plan", name), zap.Error(err))
+				continue
+			}
+
+			plans[int32(membershipType)] = &plan{
+				name:           name,
+				membershipType: int32(membershipType),
+				price:          int32(price),
+				period:         time.Duration(days) * 24 * time.Hour,
+			}
+		}
+	})
+
+	return plans
+}
+
+// planOf returns the plan of a membership type, types missing from the config are free
+func planOf(membershipType int32) *plan {
+	if p, ok := loadPlans()[membershipType]; ok {
+		return p
+	}
+
+	return &plan{
+		name:           fmt.Sprintf("type %d", membershipType),
+		membershipType: membershipType,
+		period:         defaultPeriod,
+	}
+}
+
+func (m *MembershipService) Plans() []*sdto.MembershipPlan {
+	res := make([]*sdto.MembershipPlan, 0, len(loadPlans()))
+	for _, p := range loadPlans() {
+		res = append(res, &sdto.MembershipPlan{
+			Name:           p.name,
+			MembershipType: p.membershipType,
+			Price:          p.price,
+			PeriodDays:     int(p.period / (24 * time.Hour)),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].MembershipType < res[j].MembershipType
+	})
+
+	return res
+}
+
+// Subscribe starts a membership, renews it when it is of the same type and otherwise
+// switches the plan for the rest of the period, charging or refunding the difference
+func (m *MembershipService) Subscribe(ctx context.Context, userID string, membershipType int32) (*sdto.MembershipRecord, *errorx.ServiceErr) {
+	target, ok := loadPlans()[membershipType]
+	if !ok {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Unknown membership type", nil)
+	}
+
+	user, sErr := getUser(ctx, userID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if sErr := userService.RequireVerifiedEmail(user); sErr != nil {
+		return nil, sErr
+	}
+
+	now := time.Now()
+	history := &model.MembershipHistory{
+		UserID:   userID,
+		FromType: user.MembershipType,
+		ToType:   membershipType,
+	}
+	var updates map[string]interface{}
+
+	switch {
+	case !active(user, now):
+		history.Action = ACTION_SUBSCRIBE
+		history.Amount = target.price
+		updates, history.ExpiresAt = grant(user, target, target.period, now)
+
+	case user.MembershipType == membershipType:
+		history.Action = ACTION_RENEW
+		history.Amount = target.price
+		updates, history.ExpiresAt = grant(user, target, target.period, now)
+
+	default:
+		current := planOf(user.MembershipType)
+		remaining := time.Unix(user.MembershipTime, 0).Sub(now)
+
+		history.Action = ACTION_UPGRADE
+		if target.price < current.price {
+			history.Action = ACTION_DOWNGRADE
+		}
+		history.Amount = util.Prorate(target.price, remaining, target.period) - util.Prorate(current.price, remaining, current.period)
+		history.ExpiresAt = user.MembershipTime
+		updates = map[string]interface{}{"membershipType": membershipType}
+	}
+
+	if sErr := m.change(ctx, user, updates, history); sErr != nil {
+		return nil, sErr
+	}
+
+	return toMembershipRecord(history), nil
+}
+
+// Cancel ends the membership right away, the unused paid time is refunded within the refund
+// window. A membership of only voucher or trial time can be cancelled any time, without refund.
+func (m *MembershipService) Cancel(ctx context.Context, userID string) (*sdto.MembershipRecord, *errorx.ServiceErr) {
+	user, sErr := getUser(ctx, userID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	now := time.Now()
+	if !active(user, now) {
+		zlog.Warn("User has not subscribed", zap.String("userID", userID))
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "User has not subscribed", nil)
+	}
+
+	histories, err := dao.GetMembershipHistory(ctx, userID)
+	if err != nil {
+		zlog.Error("Failed to get membership history", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	// No-reason refund (7 days after the last payment)
+	amount, ok := refund(histories, now)
+	if !ok {
+		zlog.Warn("Cancellation period has expired", zap.String("userID", userID))
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Cancellation period has expired", nil)
+	}
+
+	history := &model.MembershipHistory{
+		UserID:    userID,
+		Action:    ACTION_CANCEL,
+		FromType:  user.MembershipType,
+		ToType:    0,
+		Amount:    -amount,
+		ExpiresAt: 0,
+	}
+	updates := map[string]interface{}{
+		"membershipType": 0,
+		"membershipTime": 0,
+		"autoRenew":      false,
+	}
+
+	if sErr := m.change(ctx, user, updates, history); sErr != nil {
+		return nil, sErr
+	}
+
+	return toMembershipRecord(history), nil
+}
+
+func (m *MembershipService) SetAutoRenew(ctx context.Context, userID string, autoRenew bool) *errorx.ServiceErr {
+	user, sErr := getUser(ctx, userID)
+	if sErr != nil {
+		return sErr
+	}
+
+	if autoRenew && !active(user, time.Now()) {
+		return errorx.NewServicerErr(errorx.ErrExternal, "User has not subscribed", nil)
+	}
+
+	if err := dao.UpdateUserByID(ctx, userID, map[string]interface{}{"autoRenew": autoRenew}); err != nil {
+		zlog.Error("Failed to update auto renew", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+func (m *MembershipService) Get(ctx context.Context, userID string) (*sdto.MembershipOutput, *errorx.ServiceErr) {
+	user, sErr := getUser(ctx, userID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	histories, err := dao.GetMembershipHistory(ctx, userID)
+	if err != nil {
+		zlog.Error("Failed to get membership history", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := &sdto.MembershipOutput{
+		MembershipType: user.MembershipType,
+		ExpiresAt:      user.MembershipTime,
+		AutoRenew:      user.AutoRenew,
+		TrialAvailable: trialAvailable(user, time.Now()),
+		History:        make([]*sdto.MembershipRecord, 0, len(histories)),
+	}
+	for _, history := range histories {
+		res.History = append(res.History, toMembershipRecord(history))
+	}
+
+	return res, nil
+}
+
+// Sweep renews or ends the memberships that have expired, rewards the referrals of payments
+// that can no longer be refunded and reminds members whose membership is about to end
+func (m *MembershipService) Sweep(ctx context.Context) error {
+	now := time.Now()
+
+	for {
+		users, err := dao.GetExpiredMembers(ctx, now.Unix(), sweepBatchSize)
+		if err != nil {
+			return err
+		}
+
+		changed := 0
+		for _, user := range users {
+			if m.expire(ctx, user, now) {
+				changed++
+			}
+		}
+
+		// a full batch that could not be changed would be returned again
+		if len(users) < sweepBatchSize || changed == 0 {
+			break
+		}
+	}
+
+	if err := m.rewardReferrals(ctx, now); err != nil {
+		return err
+	}
+
+	return m.remind(ctx, now)
+}
+
+// rewardReferrals rewards the referral of users whose payment is past the refund window, a
+// payment refunded by a cancellation does not count
+func (m *MembershipService) rewardReferrals(ctx context.Context, now time.Time) error {
+	userIDs, err := dao.GetReferralsDueForMembership(ctx, now.Add(-refundWindow), refundWindow,
+		[]string{ACTION_SUBSCRIBE, ACTION_RENEW, ACTION_UPGRADE}, ACTION_CANCEL, sweepBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		userService.Service().RewardReferral(ctx, userID)
+	}
+
+	return nil
+}
+
+// expire renews the membership of a user who opted in and downgrades everyone else
+func (m *MembershipService) expire(ctx context.Context, user *model.User, now time.Time) bool {
+	current := planOf(user.MembershipType)
+
+	history := &model.MembershipHistory{
+		UserID:   user.UserID,
+		FromType: user.MembershipType,
+	}
+	updates := make(map[string]interface{})
+
+	if user.AutoRenew {
+		// the period starts now if the sweep did not run for a while
+		start := time.Unix(user.MembershipTime, 0)
+		if start.Add(current.period).Before(now) {
+			start = now
+		}
+
+		history.Action = ACTION_RENEW
+		history.ToType = user.MembershipType
+		history.Amount = current.price
+		history.ExpiresAt = start.Add(current.period).Unix()
+		updates["membershipTime"] = history.ExpiresAt
+	} else {
+		history.Action = ACTION_EXPIRE
+		history.ToType = 0
+		history.ExpiresAt = user.MembershipTime
+		updates["membershipType"] = 0
+	}
+
+	if sErr := m.change(ctx, user, updates, history); sErr != nil {
+		return false
+	}
+
+	content := fmt.Sprintf("Your %s membership has ended.", current.name)
+	if history.Action == ACTION_RENEW {
+		content = fmt.Sprintf("Your %s membership has been renewed until %s.", current.name, time.Unix(history.ExpiresAt, 0).Format(time.DateOnly))
+	}
+	m.notify(ctx, user, content)
+
+	return true
+}
+
+// remind notifies every member once before the membership ends
+func (m *MembershipService) remind(ctx context.Context, now time.Time) error {
+	days, err := strconv.Atoi(config.Get("membership.reminderDays"))
+	if err != nil || days <= 0 {
+		return nil
+	}
+	window := time.Duration(days) * 24 * time.Hour
+
+	users, err := dao.GetMembersExpiringBetween(ctx, now.Unix(), now.Add(window).Unix())
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		key := fmt.Sprintf(reminderKeyFmt, user.UserID, user.MembershipTime)
+		first, err := redis.RDB().SetNX(ctx, key, now.Unix(), window+24*time.Hour).Result()
+		if err != nil {
+			return err
+		}
+		if !first {
+			continue
+		}
+
+		current := planOf(user.MembershipType)
+		endsAt := time.Unix(user.MembershipTime, 0).Format(time.DateOnly)
+
+		content := fmt.Sprintf("Your %s membership ends on %s, renew it to keep your benefits.", current.name, endsAt)
+		if user.AutoRenew {
+			content = fmt.Sprintf("Your %s membership will be renewed on %s for %d.", current.name, endsAt, current.price)
+		}
+		m.notify(ctx, user, content)
+	}
+
+	return nil
+}
+
+// active reports whether the user is a member at now
+func active(user *model.User, now time.Time) bool {
+	return user.MembershipType != 0 && user.MembershipTime > now.Unix()
+}
+
+// refund returns the unused part of the payments of the current membership made within the refund
+// window, histories are newest first. Voucher and trial time is free and not refunded. false if
+// the membership has been paid for but the last payment is older than the refund window.
+func refund(histories []*model.MembershipHistory, now time.Time) (int32, bool) {
+	var amount int32
+	paid := false
+
+	for _, history := range histories {
+		if history.Action == ACTION_CANCEL || history.Action == ACTION_EXPIRE {
+			// the current membership started after this
+			break
+		}
+		if history.Amount == 0 || history.CreatedAt == nil {
+			continue
+		}
+
+		if now.After(history.CreatedAt.Add(refundWindow)) {
+			if paid {
+				break
+			}
+			if history.Amount > 0 {
+				return 0, false
+			}
+			continue
+		}
+
+		// subscriptions and renewals pay for the last period until they expire, plan changes
+		// charge or refund the difference for the time left
+		start, end := history.CreatedAt.Unix(), history.ExpiresAt
+		if history.Action == ACTION_SUBSCRIBE || history.Action == ACTION_RENEW {
+			start = end - int64(planOf(history.ToType).period/time.Second)
+		}
+		if end <= start {
+			continue
+		}
+
+		unused := time.Duration(end-max(now.Unix(), start)) * time.Second
+		amount += util.Prorate(history.Amount, unused, time.Duration(end-start)*time.Second)
+		if history.Amount > 0 {
+			paid = true
+		}
+	}
+
+	return max(amount, 0), true
+}
+
+// grant returns the updates giving target for duration on top of the current membership.
+// Time left of another membership type is converted to target by the price of both plans.
+func grant(user *model.User, target *plan, duration time.Duration, now time.Time) (map[string]interface{}, int64) {
+	start := now
+	if active(user, now) {
+		remaining := time.Unix(user.MembershipTime, 0).Sub(now)
+		if user.MembershipType != target.membershipType {
+			current := planOf(user.MembershipType)
+			remaining = util.ConvertPeriod(remaining, current.price, current.period, target.price, target.period)
+		}
+		start = now.Add(remaining)
+	}
+
+	expiresAt := start.Add(duration).Unix()
+
+	return map[string]interface{}{
+		"membershipType": target.membershipType,
+		"membershipTime": expiresAt,
+	}, expiresAt
+}
+
+// change applies a membership change and logs the user out of the old membership type
+func (m *MembershipService) change(ctx context.Context, user *model.User, updates map[string]interface{}, history *model.MembershipHistory) *errorx.ServiceErr {
+	return m.commit(ctx, user, history, func() (bool, error) {
+		return dao.ChangeMembership(ctx, user.UserID, user.MembershipType, user.MembershipTime, updates, history)
+	})
+}
+
+// commit runs apply, which changes the membership only if it is still the one of user
+func (m *MembershipService) commit(ctx context.Context, user *model.User, history *model.MembershipHistory, apply func() (bool, error)) *errorx.ServiceErr {
+	changed, err := apply()
+	if err != nil {
+		zlog.Error("Failed to change membership", zap.String("userID", user.UserID), zap.String("action", history.Action), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !changed {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Membership changed in the meantime, please try again", nil)
+	}
+
+	zlog.Info("Membership changed",
+		zap.String("userID", user.UserID),
+		zap.String("action", history.Action),
+		zap.Int32("toType", history.ToType),
+		zap.Int32("amount", history.Amount),
+	)
+
+	// membershipType is part of the claims
+	return token.Service().Outdate(ctx, user.UserID)
+}
+
+// notify sends a notification and, if the email is verified, a mail
+func (m *MembershipService) notify(ctx context.Context, user *model.User, content string) {
+	notify.Service().System(ctx, user.UserID, content)
+
+	if user.Email == nil || !user.EmailVerified {
+		return
+	}
+
+	err := mailer.Default().Send(ctx, &mailer.Message{
+		To:      *user.Email,
+		Subject: "Your membership",
+		Body:    fmt.Sprintf("Hi %s,\n\n%s\n", user.Username, content),
+	})
+	if err != nil {
+		zlog.Error("Error while sending membership mail", zap.String("userID", user.UserID), zap.Error(err))
+	}
+}
+
+func getUser(ctx context.Context, userID string) (*model.User, *errorx.ServiceErr) {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Warn("User not found", zap.String("userID", userID))
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return user, nil
+}
+
+func toMembershipRecord(history *model.MembershipHistory) *sdto.MembershipRecord {
+	record := &sdto.MembershipRecord{
+		Action:    history.Action,
+		FromType:  history.FromType,
+		ToType:    history.ToType,
+		Amount:    history.Amount,
+		ExpiresAt: history.ExpiresAt,
+	}
+	if history.CreatedAt != nil {
+		record.CreatedAt = history.CreatedAt.Unix()
+	}
+
+	return record
+}
@@ -0,0 +1,72 @@
+package membership
+
+import (
+	"testing"
+	"time"
+
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/util"
+)
+
+func at(t time.Time) *time.Time {
+	return &t
+}
+
+func TestRefundTrial(t *testing.T) {
+	now := time.Now()
+	histories := []*model.MembershipHistory{
+		{Action: ACTION_TRIAL, ToType: 1, ExpiresAt: now.Add(13 * 24 * time.Hour).Unix(), CreatedAt: at(now.Add(-24 * time.Hour))},
+	}
+
+	amount, ok := refund(histories, now)
+	if !ok || amount != 0 {
+		t.Errorf("refund(trial) = %d, %t; expected 0, true", amount, ok)
+	}
+}
+
+func TestRefundSubscriptionThenVoucher(t *testing.T) {
+	now := time.Now()
+	period := planOf(1).period
+	paidAt := now.Add(-24 * time.Hour)
+
+	// newest first, the voucher adds a year after the paid period
+	histories := []*model.MembershipHistory{
+		{Action: ACTION_VOUCHER, ToType: 1, ExpiresAt: paidAt.Add(period + 365*24*time.Hour).Unix(), CreatedAt: at(now.Add(-time.Hour))},
+		{Action: ACTION_SUBSCRIBE, ToType: 1, Amount: 1000, ExpiresAt: paidAt.Add(period).Unix(), CreatedAt: at(paidAt)},
+	}
+
+	amount, ok := refund(histories, now)
+	expected := util.Prorate(1000, period-24*time.Hour, period)
+	if !ok || amount != expected {
+		t.Errorf("refund(subscribe, voucher) = %d, %t; expected %d, true", amount, ok, expected)
+	}
+	if amount > 1000 {
+		t.Errorf("refund(subscribe, voucher) = %d; expected at most the 1000 paid", amount)
+	}
+}
+
+func TestRefundWindowExpired(t *testing.T) {
+	now := time.Now()
+	paidAt := now.Add(-refundWindow - time.Hour)
+	histories := []*model.MembershipHistory{
+		{Action: ACTION_SUBSCRIBE, ToType: 1, Amount: 1000, ExpiresAt: paidAt.Add(planOf(1).period).Unix(), CreatedAt: at(paidAt)},
+	}
+
+	if amount, ok := refund(histories, now); ok {
+		t.Errorf("refund(old subscription) = %d, true; expected false", amount)
+	}
+}
+
+func TestRefundAfterEarlierCancel(t *testing.T) {
+	now := time.Now()
+	histories := []*model.MembershipHistory{
+		{Action: ACTION_TRIAL, ToType: 1, ExpiresAt: now.Add(13 * 24 * time.Hour).Unix(), CreatedAt: at(now.Add(-time.Hour))},
+		{Action: ACTION_CANCEL, FromType: 1, Amount: -900, CreatedAt: at(now.Add(-2 * time.Hour))},
+		{Action: ACTION_SUBSCRIBE, ToType: 1, Amount: 1000, ExpiresAt: now.Add(27 * 24 * time.Hour).Unix(), CreatedAt: at(now.Add(-3 * 24 * time.Hour))},
+	}
+
+	amount, ok := refund(histories, now)
+	if !ok || amount != 0 {
+		t.Errorf("refund(cancelled subscription, trial) = %d, %t; expected 0, true", amount, ok)
+	}
+}
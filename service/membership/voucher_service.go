@@ -0,0 +1,312 @@
+package membership
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	userService "api.backend.xjco2913/service/user"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Vouchers and the free trial grant a membership without a payment. Both go through grant,
+// like a subscription, so the time left of the current membership is kept.
+const (
+	maxVoucherQuantity = 1000
+	maxVoucherDays     = 3650
+
+	// failed redemptions of a user, to stop guessing codes
+	//
+	//	voucherAttempts:<userID> => count
+	voucherAttemptsKeyFmt = "voucherAttempts:%s"
+	maxVoucherAttempts    = 10
+	voucherAttemptsWindow = time.Hour
+)
+
+// CreateVouchers generates a batch of codes, the codes are returned only once
+func (m *MembershipService) CreateVouchers(ctx context.Context, in *sdto.VoucherBatchInput) (*sdto.VoucherBatchOutput, *errorx.ServiceErr) {
+	if _, ok := loadPlans()[in.MembershipType]; !ok {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Unknown membership type", nil)
+	}
+	if in.Days <= 0 || in.Days > maxVoucherDays {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, fmt.Sprintf("Days must be between 1 and %d", maxVoucherDays), nil)
+	}
+	if in.Quantity <= 0 || in.Quantity > maxVoucherQuantity {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, fmt.Sprintf("Quantity must be between 1 and %d", maxVoucherQuantity), nil)
+	}
+	if in.RedeemBy != nil && !in.RedeemBy.After(time.Now()) {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "RedeemBy must be in the future", nil)
+	}
+
+	codes := make([]string, in.Quantity)
+	hashes := make([]string, in.Quantity)
+	for i := range codes {
+		code, err := newVoucherCode()
+		if err != nil {
+			zlog.Error("Error while generating voucher code", zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+
+		codes[i] = code
+		hashes[i] = util.HashToken(normalizeVoucherCode(code))
+	}
+
+	batch := &model.VoucherBatch{
+		Name:           in.Name,
+		MembershipType: in.MembershipType,
+		Days:           in.Days,
+		Quantity:       in.Quantity,
+		RedeemBy:       in.RedeemBy,
+		CreatedBy:      in.AdminID,
+	}
+	if err := dao.CreateVoucherBatch(ctx, batch, hashes); err != nil {
+		zlog.Error("Failed to create voucher batch", zap.String("adminID", in.AdminID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	zlog.Info("Voucher batch created",
+		zap.Int32("batchID", batch.ID),
+		zap.String("adminID", in.AdminID),
+		zap.Int32("membershipType", in.MembershipType),
+		zap.Int32("quantity", in.Quantity),
+	)
+
+	return &sdto.VoucherBatchOutput{
+		VoucherBatch: toVoucherBatch(batch, 0),
+		Codes:        codes,
+	}, nil
+}
+
+func (m *MembershipService) VoucherBatches(ctx context.Context) ([]*sdto.VoucherBatch, *errorx.ServiceErr) {
+	batches, err := dao.GetVoucherBatches(ctx)
+	if err != nil {
+		zlog.Error("Failed to get voucher batches", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	redeemed, err := dao.CountRedeemedVouchers(ctx)
+	if err != nil {
+		zlog.Error("Failed to count redeemed vouchers", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := make([]*sdto.VoucherBatch, 0, len(batches))
+	for _, batch := range batches {
+		res = append(res, toVoucherBatch(batch, redeemed[batch.ID]))
+	}
+
+	return res, nil
+}
+
+// RevokeVoucherBatch stops the codes of a batch, redeemed codes keep their membership
+func (m *MembershipService) RevokeVoucherBatch(ctx context.Context, batchID int32, adminID string) *errorx.ServiceErr {
+	revoked, err := dao.RevokeVoucherBatch(ctx, batchID, time.Now())
+	if err != nil {
+		zlog.Error("Failed to revoke voucher batch", zap.Int32("batchID", batchID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !revoked {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Voucher batch not found or already revoked", nil)
+	}
+
+	zlog.Info("Voucher batch revoked", zap.Int32("batchID", batchID), zap.String("adminID", adminID))
+
+	return nil
+}
+
+// RedeemVoucher grants the membership of a voucher, each code can be redeemed once
+func (m *MembershipService) RedeemVoucher(ctx context.Context, userID string, code string) (*sdto.MembershipRecord, *errorx.ServiceErr) {
+	attemptsKey := fmt.Sprintf(voucherAttemptsKeyFmt, userID)
+
+	attempts, err := redis.RDB().Get(ctx, attemptsKey).Int()
+	if err != nil && !errors.Is(err, redis.KEY_NOT_FOUND) {
+		zlog.Error("Error while getting voucher attempts", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if attempts >= maxVoucherAttempts {
+		return nil, errorx.NewServicerErr(429, "Too many attempts, please try again later", nil)
+	}
+
+	user, sErr := getUser(ctx, userID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if sErr := userService.RequireVerifiedEmail(user); sErr != nil {
+		return nil, sErr
+	}
+
+	voucher, err := dao.GetVoucherByCodeHash(ctx, util.HashToken(normalizeVoucherCode(code)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			pipe := redis.RDB().TxPipeline()
+			pipe.Incr(ctx, attemptsKey)
+			pipe.Expire(ctx, attemptsKey, voucherAttemptsWindow)
+			if _, err := pipe.Exec(ctx); err != nil {
+				zlog.Error("Error while counting voucher attempts", zap.String("userID", userID), zap.Error(err))
+			}
+
+			zlog.Warn("Invalid voucher code", zap.String("userID", userID))
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid voucher code", nil)
+		}
+		zlog.Error("Failed to get voucher", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	if voucher.RedeemedBy != nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Voucher has already been redeemed", nil)
+	}
+
+	batch, err := dao.GetVoucherBatchByID(ctx, voucher.BatchID)
+	if err != nil {
+		zlog.Error("Failed to get voucher batch", zap.Int32("batchID", voucher.BatchID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	now := time.Now()
+	if batch.RevokedAt != nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Voucher has been revoked", nil)
+	}
+	if batch.RedeemBy != nil && !batch.RedeemBy.After(now) {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Voucher has expired", nil)
+	}
+
+	target := planOf(batch.MembershipType)
+	history := &model.MembershipHistory{
+		UserID:   userID,
+		Action:   ACTION_VOUCHER,
+		FromType: user.MembershipType,
+		ToType:   target.membershipType,
+	}
+
+	var updates map[string]interface{}
+	updates, history.ExpiresAt = grant(user, target, time.Duration(batch.Days)*24*time.Hour, now)
+
+	sErr = m.commit(ctx, user, history, func() (bool, error) {
+		return dao.RedeemVoucher(ctx, voucher, userID, user.MembershipType, user.MembershipTime, updates, history)
+	})
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return toMembershipRecord(history), nil
+}
+
+// StartTrial grants the trial membership, once per account and only to users who are not members
+func (m *MembershipService) StartTrial(ctx context.Context, userID string) (*sdto.MembershipRecord, *errorx.ServiceErr) {
+	target, duration, ok := loadTrial()
+	if !ok {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Free trial is not available", nil)
+	}
+
+	user, sErr := getUser(ctx, userID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if sErr := userService.RequireVerifiedEmail(user); sErr != nil {
+		return nil, sErr
+	}
+
+	now := time.Now()
+	if user.TrialUsed {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Free trial has already been used", nil)
+	}
+	if active(user, now) {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Free trial is only available to non-members", nil)
+	}
+
+	history := &model.MembershipHistory{
+		UserID:   userID,
+		Action:   ACTION_TRIAL,
+		FromType: user.MembershipType,
+		ToType:   target.membershipType,
+	}
+
+	var updates map[string]interface{}
+	updates, history.ExpiresAt = grant(user, target, duration, now)
+
+	sErr = m.commit(ctx, user, history, func() (bool, error) {
+		return dao.StartTrial(ctx, userID, user.MembershipType, user.MembershipTime, updates, history)
+	})
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return toMembershipRecord(history), nil
+}
+
+// loadTrial reads the plan and the length of the free trial from membership.trial
+func loadTrial() (*plan, time.Duration, bool) {
+	membershipType, err1 := strconv.Atoi(config.Get("membership.trial.type"))
+	days, err2 := strconv.Atoi(config.Get("membership.trial.days"))
+	if errors.Join(err1, err2) != nil || days <= 0 {
+		return nil, 0, false
+	}
+
+	target, ok := loadPlans()[int32(membershipType)]
+	if !ok {
+		return nil, 0, false
+	}
+
+	return target, time.Duration(days) * 24 * time.Hour, true
+}
+
+func trialAvailable(user *model.User, now time.Time) bool {
+	_, _, ok := loadTrial()
+
+	return ok && !user.TrialUsed && !active(user, now)
+}
+
+// newVoucherCode returns 80 random bits as XXXX-XXXX-XXXX-XXXX
+func newVoucherCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	raw := base32.StdEncoding.EncodeToString(b)
+
+	return raw[:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:], nil
+}
+
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func toVoucherBatch(batch *model.VoucherBatch, redeemed int64) *sdto.VoucherBatch {
+	res := &sdto.VoucherBatch{
+		BatchID:        batch.ID,
+		Name:           batch.Name,
+		MembershipType: batch.MembershipType,
+		Days:           batch.Days,
+		Quantity:       batch.Quantity,
+		Redeemed:       redeemed,
+		CreatedBy:      batch.CreatedBy,
+	}
+	if batch.RedeemBy != nil {
+		res.RedeemBy = batch.RedeemBy.Unix()
+	}
+	if batch.CreatedAt != nil {
+		res.CreatedAt = batch.CreatedAt.Unix()
+	}
+	if batch.RevokedAt != nil {
+		res.RevokedAt = batch.RevokedAt.Unix()
+	}
+
+	return res
+}
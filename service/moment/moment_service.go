@@ -3,15 +3,23 @@ package moment
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"api.backend.xjco2913/dao"
 	"api.backend.xjco2913/dao/minio"
 	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/block"
 	"api.backend.xjco2913/service/gpx"
+	"api.backend.xjco2913/service/privacy"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/stats"
+	"api.backend.xjco2913/service/timeline"
+	"api.backend.xjco2913/service/trending"
 	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
 	"api.backend.xjco2913/util/zlog"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -19,7 +27,19 @@ import (
 )
 
 const (
-	MOMENT_FEED_LIMIT = 10
+	// images a moment can have when moment.maxImages is not set
+	defaultMaxImages = 9
+)
+
+const (
+	MOMENT_FEED_LIMIT     = 10
+	MOMENT_TRENDING_LIMIT = 20
+)
+
+// Comment threads are paged, pageSize defaults to COMMENT_PAGE_SIZE
+const (
+	COMMENT_PAGE_SIZE     = 20
+	COMMENT_MAX_PAGE_SIZE = 100
 )
 
 type MomentService struct{}
@@ -32,114 +52,214 @@ func Service() *MomentService {
 	return &momentService
 }
 
+// Create stores a moment with its attachments, the images in the order given, then the video
+// and then the route. The video is uploaded in the background, the moment is deleted if that fails.
 func (m *MomentService) Create(ctx context.Context, in *sdto.CreateMomentInput) *errorx.ServiceErr {
-	momentId := uuid.New()
-	momentIdStr := momentId.String()
-	_, err := dao.CreateNewMoment(ctx, &model.Moment{
-		AuthorID: in.UserID,
-		Content:  &in.Content,
-		MomentID: momentIdStr,
-	})
-	if err != nil {
-		zlog.Error("Error while create new moment", zap.Error(err))
-		return errorx.NewInternalErr()
+	if util.IsEmpty(in.Content) && len(in.Images) == 0 && in.Video == nil && in.GPXData == nil {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Moment cannot be empty", nil)
+	}
+	if max := maxImages(); len(in.Images) > max {
+		return errorx.NewServicerErr(errorx.ErrExternal, fmt.Sprintf("A moment can have at most %d images", max), nil)
 	}
 
-	return nil
-}
+	// check every file before anything is stored
+	attachments := []*model.MomentAttachment{}
+	for i, data := range in.Images {
+		contentType, width, height, err := util.DetectImage(data)
+		if err != nil {
+			return errorx.NewServicerErr(errorx.ErrExternal, fmt.Sprintf("Image %d: %s", i+1, err.Error()), nil)
+		}
 
-func (m *MomentService) CreateWithImage(ctx context.Context, in *sdto.CreateMomentImageInput) *errorx.ServiceErr {
-	imageName := uuid.New()
-	err := minio.UploadMomentImage(ctx, imageName.String(), in.ImageData)
-	if err != nil {
-		zlog.Error("Error while store moment image into minio", zap.Error(err))
-		return errorx.NewInternalErr()
+		objectName, w, h := uuid.NewString(), int32(width), int32(height)
+		attachments = append(attachments, &model.MomentAttachment{
+			Kind:        dao.ATTACHMENT_IMAGE,
+			ObjectName:  &objectName,
+			ContentType: &contentType,
+			Width:       &w,
+			Height:      &h,
+		})
+	}
+	images := len(attachments)
+
+	var video *model.MomentAttachment
+	if in.Video != nil {
+		contentType, width, height, err := util.DetectVideo(in.Video, in.VideoContentType)
+		if err != nil {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Video: "+err.Error(), nil)
+		}
+
+		objectName := uuid.NewString()
+		video = &model.MomentAttachment{
+			Kind:        dao.ATTACHMENT_VIDEO,
+			ObjectName:  &objectName,
+			ContentType: &contentType,
+		}
+		// only known for the MP4 and QuickTime family
+		if width > 0 && height > 0 {
+			w, h := int32(width), int32(height)
+			video.Width, video.Height = &w, &h
+		}
+		attachments = append(attachments, video)
+	}
+
+	var route *sdto.ParseGPXDataOutput
+	if in.GPXData != nil {
+		var sErr *errorx.ServiceErr
+		route, sErr = gpx.Service().ParseGPXData(ctx, &sdto.ParseGPXDataInput{
+			GPXData: in.GPXData,
+		})
+		if sErr != nil {
+			return sErr
+		}
+
+		attachments = append(attachments, &model.MomentAttachment{
+			Kind:    dao.ATTACHMENT_ROUTE,
+			RouteID: &route.RouteID,
+		})
+	}
+
+	// uploaded objects are removed again if the moment cannot be stored
+	uploaded := []string{}
+	cleanUp := func() {
+		go func() {
+			if err := minio.RemoveMomentMedia(context.Background(), uploaded...); err != nil {
+				zlog.Error("Error while remove moment media", zap.Error(err))
+			}
+		}()
+		if route != nil {
+			if err := dao.DeleteRouteById(ctx, route.RouteID); err != nil {
+				zlog.Error("Error while delete moment route", zap.Int32("routeID", route.RouteID), zap.Error(err))
+			}
+		}
+	}
+
+	for i, attachment := range attachments[:images] {
+		err := minio.UploadMomentMedia(ctx, *attachment.ObjectName, in.Images[i], *attachment.ContentType)
+		if err != nil {
+			zlog.Error("Error while store moment image into minio", zap.Error(err))
+			cleanUp()
+			return errorx.NewInternalErr()
+		}
+		uploaded = append(uploaded, *attachment.ObjectName)
 	}
 
-	imageNameStr := imageName.String()
-	momentId := uuid.New()
-	momentIdStr := momentId.String()
-	_, err = dao.CreateNewMoment(ctx, &model.Moment{
+	momentIdStr := uuid.NewString()
+	newMoment := &model.Moment{
 		AuthorID: in.UserID,
 		Content:  &in.Content,
-		ImageURL: &imageNameStr,
 		MomentID: momentIdStr,
-	})
-	if err != nil {
+	}
+	if route != nil {
+		newMoment.RouteID = &route.RouteID
+	}
+	if _, err := dao.CreateMomentWithAttachments(ctx, newMoment, attachments); err != nil {
 		zlog.Error("Error while create new moment", zap.Error(err))
+		cleanUp()
+		return errorx.NewInternalErr()
+	}
+	timeline.Service().Publish(ctx, momentIdStr)
 
-		// Async remove image in minio
+	if video != nil {
+		// Async upload video, the request may be over by the time it is done
 		go func() {
-			minio.RemoveObjectFromMoment(ctx, imageNameStr)
+			ctx := context.Background()
+			err := minio.UploadMomentMedia(ctx, *video.ObjectName, in.Video, *video.ContentType)
+			if err != nil {
+				// Error, remove the moment with the rest of its media
+				zlog.Error("Error while async upload moment video", zap.String("momentID", momentIdStr), zap.Error(err))
+				m.Delete(ctx, momentIdStr)
+			}
 		}()
+	}
 
-		return errorx.NewInternalErr()
+	if route != nil {
+		// the moment stays even if it is missing from the statistics
+		stats.Service().RecordRide(ctx, in.UserID, dao.RIDE_MOMENT, momentIdStr, route)
 	}
 
 	return nil
 }
 
-func (m *MomentService) CreateWithVideo(ctx context.Context, in *sdto.CreateMomentVideoInput) *errorx.ServiceErr {
-	videoName := uuid.New()
-	videoNameStr := videoName.String()
-
-	momentId := uuid.New()
-	momentIdStr := momentId.String()
-	newMomentID, err := dao.CreateNewMoment(ctx, &model.Moment{
-		AuthorID: in.UserID,
-		Content:  &in.Content,
-		VideoURL: &videoNameStr,
-		MomentID: momentIdStr,
-	})
+// IsAuthor reports whether the user wrote the moment, a moment that does not exist
+// has nothing to protect
+func (m *MomentService) IsAuthor(ctx context.Context, momentID string, userID string) (bool, *errorx.ServiceErr) {
+	moment, err := dao.GetMomentByID(ctx, momentID)
 	if err != nil {
-		zlog.Error("Error while create new moment", zap.Error(err))
-		return errorx.NewInternalErr()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+
+		zlog.Error("Failed to retrieve moment by moment ID", zap.String("momentID", momentID), zap.Error(err))
+		return false, errorx.NewInternalErr()
 	}
 
-	// Async upload video
-	go func() {
-		err := minio.UploadMomentVideo(ctx, videoNameStr, in.VideoData)
-		if err != nil {
-			// Error, remove moment record in mysql
-			zlog.Error("Error while async upload moment video", zap.Error(err))
-			dao.DeleteMomentByID(ctx, newMomentID)
-		}
-	}()
+	return moment.AuthorID == userID, nil
+}
+
+// Edit replaces the text of the moment, the attached media stay as they are
+func (m *MomentService) Edit(ctx context.Context, in *sdto.EditMomentInput) *errorx.ServiceErr {
+	if _, sErr := getMoment(ctx, in.MomentID); sErr != nil {
+		return sErr
+	}
+
+	if err := dao.UpdateMomentContent(ctx, in.MomentID, in.Content); err != nil {
+		zlog.Error("Error while edit moment", zap.String("momentID", in.MomentID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
 
 	return nil
 }
 
-func (m *MomentService) CreateWithGPX(ctx context.Context, in *sdto.CreateMomentGPXInput) *errorx.ServiceErr {
-	gpxResp, sErr := gpx.Service().ParseGPXData(ctx, &sdto.ParseGPXDataInput{
-		GPXData: in.GPXData,
-	})
+// Delete removes the moment with its likes, comments, route and ride. The media are removed
+// from minio first, a retry would not find them otherwise.
+func (m *MomentService) Delete(ctx context.Context, momentID string) *errorx.ServiceErr {
+	moment, sErr := getMoment(ctx, momentID)
 	if sErr != nil {
 		return sErr
 	}
 
-	momentId := uuid.New()
-	momentIdStr := momentId.String()
-	_, err := dao.CreateNewMoment(ctx, &model.Moment{
-		AuthorID: in.UserID,
-		Content:  &in.Content,
-		RouteID:  &gpxResp.RouteID,
-		MomentID: momentIdStr,
-	})
+	objects, err := dao.GetAttachmentObjects(ctx, momentID)
 	if err != nil {
-		zlog.Error("Error while create new moment", zap.Error(err))
+		zlog.Error("Error while get moment attachments", zap.String("momentID", momentID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if err := minio.RemoveMomentMedia(ctx, objects...); err != nil {
+		zlog.Error("Error while remove moment media", zap.String("momentID", momentID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	if err := dao.DeleteMomentCascade(ctx, moment); err != nil {
+		zlog.Error("Error while delete moment", zap.String("momentID", momentID), zap.Error(err))
 		return errorx.NewInternalErr()
 	}
 
+	zlog.Info("Moment deleted", zap.String("momentID", momentID), zap.String("authorID", moment.AuthorID))
+
 	return nil
 }
 
-func (m *MomentService) Feed(ctx context.Context, in *sdto.FeedMomentInput) (*sdto.FeedMomentOutput, *errorx.ServiceErr) {
-	moments, err := dao.GetMomentsByTime(ctx, MOMENT_FEED_LIMIT, time.UnixMilli(in.LatestTime))
+func getMoment(ctx context.Context, momentID string) (*model.Moment, *errorx.ServiceErr) {
+	moment, err := dao.GetMomentByID(ctx, momentID)
 	if err != nil {
-		zlog.Error("Error while get moment feed by latest time", zap.Error(err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "Moment not found by moment ID", nil)
+		}
+
+		zlog.Error("Failed to retrieve moment by moment ID", zap.String("momentID", momentID), zap.Error(err))
 		return nil, errorx.NewInternalErr()
 	}
 
+	return moment, nil
+}
+
+// Feed returns the home timeline of the user, the moments of the user and of the users the user
+// follows created before latestTime. NextTime is where the next page starts.
+func (m *MomentService) Feed(ctx context.Context, in *sdto.FeedMomentInput) (*sdto.FeedMomentOutput, *errorx.ServiceErr) {
+	moments, sErr := timeline.Service().Page(ctx, in.UserID, time.UnixMilli(in.LatestTime), MOMENT_FEED_LIMIT)
+	if sErr != nil {
+		return nil, sErr
+	}
+
 	var nextTime int64
 	if len(moments) == 0 {
 		nextTime = time.Now().UnixMilli()
@@ -147,11 +267,71 @@ func (m *MomentService) Feed(ctx context.Context, in *sdto.FeedMomentInput) (*sd
 		nextTime = moments[len(moments)-1].CreatedAt.UnixMilli()
 	}
 
+	// the authors the user blocked or muted are left out
+	hidden, sErr := block.Service().Hidden(ctx, in.UserID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	res, sErr := m.present(ctx, in.UserID, moments, hidden)
+	if sErr != nil {
+		return nil, sErr
+	}
+	res.NextTime = nextTime
+
+	return res, nil
+}
+
+// Trending returns the moments trending everywhere, or in the region if one is given, best first
+func (m *MomentService) Trending(ctx context.Context, in *sdto.TrendingInput) (*sdto.FeedMomentOutput, *errorx.ServiceErr) {
+	ids, sErr := trending.Service().Top(ctx, trending.KIND_MOMENT, in.Region, MOMENT_TRENDING_LIMIT)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	found, err := dao.GetMomentsByMomentIDs(ctx, ids)
+	if err != nil {
+		zlog.Error("Error while get trending moments", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	byID := make(map[string]*model.Moment, len(found))
+	for _, moment := range found {
+		byID[moment.MomentID] = moment
+	}
+	// in the order of the ranking, deleted moments are skipped
+	moments := make([]*model.Moment, 0, len(found))
+	for _, id := range ids {
+		if moment, ok := byID[id]; ok {
+			moments = append(moments, moment)
+		}
+	}
+
+	blocked, sErr := block.Service().Blocked(ctx, in.UserID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return m.present(ctx, in.UserID, moments, blocked)
+}
+
+// present adds the authors, media and routes to the moments shown to the user. Moments of authors
+// whose profile the user may not see are left out, so are the ones of the hidden authors.
+func (m *MomentService) present(ctx context.Context, userID string, moments []*model.Moment, hidden block.Filter) (*sdto.FeedMomentOutput, *errorx.ServiceErr) {
 	res := &sdto.FeedMomentOutput{
 		GPXRouteText:  make(map[int][][]string),
 		AuthorInfoMap: make(map[string]*model.User),
 	}
-	for i, moment := range moments {
+
+	viewer := &sdto.Viewer{UserID: userID}
+	trimmer := privacy.Service().Trimmer(viewer)
+	visible := make(map[string]bool)
+	var shown []*model.Moment
+
+	for _, moment := range moments {
+		if hidden.Hides(moment.AuthorID) {
+			continue
+		}
+
 		// Get author info
 		author, err := dao.GetUserByID(ctx, moment.AuthorID)
 		if err != nil {
@@ -159,6 +339,23 @@ func (m *MomentService) Feed(ctx context.Context, in *sdto.FeedMomentInput) (*sd
 			return nil, errorx.NewInternalErr()
 		}
 
+		ok, checked := visible[author.UserID]
+		if !checked {
+			var sErr *errorx.ServiceErr
+			if ok, sErr = privacy.Service().CanView(ctx, viewer, author); sErr != nil {
+				return nil, sErr
+			}
+			visible[author.UserID] = ok
+		}
+		if !ok {
+			continue
+		}
+		if !privacy.CanSeeRoutes(viewer, author) {
+			moment.RouteID = nil
+		}
+		i := len(shown)
+		shown = append(shown, moment)
+
 		// Get author avatar url
 		if author.AvatarURL != nil {
 			url, err := minio.GetUserAvatarUrl(ctx, *author.AvatarURL)
@@ -172,24 +369,6 @@ func (m *MomentService) Feed(ctx context.Context, in *sdto.FeedMomentInput) (*sd
 
 		res.AuthorInfoMap[moment.MomentID] = author
 
-		if moment.ImageURL != nil {
-			url, err := minio.GetMomentImageUrl(ctx, *moment.ImageURL)
-			if err != nil {
-				zlog.Error("Error while get moment image url from minio", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
-
-			moment.ImageURL = &url
-		}
-		if moment.VideoURL != nil {
-			url, err := minio.GetMomentImageUrl(ctx, *moment.VideoURL)
-			if err != nil {
-				zlog.Error("Error while get moment video url from minio", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
-
-			moment.VideoURL = &url
-		}
 		if moment.RouteID != nil {
 			path, err := dao.GetPathAsText(ctx, *moment.RouteID)
 			if err != nil {
@@ -203,25 +382,43 @@ func (m *MomentService) Feed(ctx context.Context, in *sdto.FeedMomentInput) (*sd
 				return nil, errorx.NewInternalErr()
 			}
 
-			res.GPXRouteText[i] = util.GPXStrTo2DString(pathText)
+			route, sErr := trimmer.Trim(ctx, author.UserID, util.GPXStrTo2DString(pathText))
+			if sErr != nil {
+				return nil, sErr
+			}
+			res.GPXRouteText[i] = route
 		}
 	}
 
-	res.Moments = moments
-	res.NextTime = nextTime
+	res.Moments = shown
+
+	var sErr *errorx.ServiceErr
+	if res.Attachments, sErr = attachments(ctx, shown); sErr != nil {
+		return nil, sErr
+	}
 
 	return res, nil
 }
 
-func (m *MomentService) GetLikesByMomentId(ctx context.Context, momentId string) (*sdto.GetLikesOutput, *errorx.ServiceErr) {
+// GetLikesByMomentId returns who liked the moment, leaving out the users blocked in either direction by userId
+func (m *MomentService) GetLikesByMomentId(ctx context.Context, momentId, userId string) (*sdto.GetLikesOutput, *errorx.ServiceErr) {
 	likes, err := dao.GetLikeByMomentId(ctx, momentId)
 	if err != nil {
 		zlog.Error("Error while get moment likes", zap.Error(err))
 		return nil, errorx.NewInternalErr()
 	}
 
+	blocked, sErr := block.Service().Blocked(ctx, userId)
+	if sErr != nil {
+		return nil, sErr
+	}
+
 	personLikes := []sdto.MomentUser{}
 	for _, like := range likes {
+		if blocked.Hides(like.UserID) {
+			continue
+		}
+
 		likeId := like.UserID
 
 		personLike, err := dao.GetUserByID(ctx, likeId)
@@ -250,44 +447,121 @@ func (m *MomentService) GetLikesByMomentId(ctx context.Context, momentId string)
 	}, nil
 }
 
-func (m *MomentService) GetCommentListByMomentId(ctx context.Context, momentId string) (*sdto.GetCommentListOutput, *errorx.ServiceErr) {
-	commentlist := []sdto.MomentComment{}
+// GetCommentListByMomentId returns a page of the comment threads of the moment, oldest first,
+// each with all its replies, if the viewer may see the profile of its author. Comments of users
+// blocked either way are left out.
+func (m *MomentService) GetCommentListByMomentId(ctx context.Context, in *sdto.CommentPageInput) (*sdto.GetCommentListOutput, *errorx.ServiceErr) {
+	moment, err := dao.GetMomentByID(ctx, in.MomentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Warn("Moment not found by moment ID", zap.String("momentID", in.MomentID))
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "Moment not found by moment ID", nil)
+		}
+		zlog.Error("Failed to retrieve moment by moment ID", zap.String("momentID", in.MomentID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if _, sErr := privacy.Service().CanViewByID(ctx, in.Viewer, moment.AuthorID); sErr != nil {
+		return nil, sErr
+	}
+
+	page, pageSize := in.Page, in.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = COMMENT_PAGE_SIZE
+	}
+	if pageSize > COMMENT_MAX_PAGE_SIZE {
+		pageSize = COMMENT_MAX_PAGE_SIZE
+	}
 
-	commentModels, err := dao.GetCommentsByMomentId(ctx, momentId)
+	threads, total, err := dao.GetCommentThreads(ctx, in.MomentID, (page-1)*pageSize, pageSize)
 	if err != nil {
-		zlog.Error("Error while get moment comments", zap.Error(err))
+		zlog.Error("Error while get moment comments", zap.String("momentID", in.MomentID), zap.Error(err))
 		return nil, errorx.NewInternalErr()
 	}
 
-	for _, commentModel := range commentModels {
-		var comment sdto.MomentComment
+	parentIds := make([]string, len(threads))
+	for i, thread := range threads {
+		parentIds[i] = thread.CommentID
+	}
+	replies, err := dao.GetRepliesByParentIDs(ctx, parentIds)
+	if err != nil {
+		zlog.Error("Error while get comment replies", zap.String("momentID", in.MomentID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
 
-		author, err := dao.GetUserByID(ctx, commentModel.AuthorID)
-		if err != nil {
-			zlog.Error("Error while get comment user", zap.Error(err))
-			return nil, errorx.NewInternalErr()
-		}
+	blocked, sErr := block.Service().Blocked(ctx, in.Viewer.UserID)
+	if sErr != nil {
+		return nil, sErr
+	}
 
-		var url string
-		if author.AvatarURL != nil && *author.AvatarURL != "" {
-			url, err = minio.GetUserAvatarUrl(ctx, *author.AvatarURL)
+	authors := map[string]sdto.MomentUser{}
+	toComment := func(commentModel *model.Comment) (sdto.MomentComment, *errorx.ServiceErr) {
+		author, ok := authors[commentModel.AuthorID]
+		if !ok {
+			user, err := dao.GetUserByID(ctx, commentModel.AuthorID)
 			if err != nil {
-				zlog.Error("Error while get user avatar url", zap.Error(err))
-				return nil, errorx.NewInternalErr()
+				zlog.Error("Error while get comment user", zap.Error(err))
+				return sdto.MomentComment{}, errorx.NewInternalErr()
+			}
+
+			author.Name = user.Username
+			if user.AvatarURL != nil && *user.AvatarURL != "" {
+				author.AvatarUrl, err = minio.GetUserAvatarUrl(ctx, *user.AvatarURL)
+				if err != nil {
+					zlog.Error("Error while get user avatar url", zap.Error(err))
+					return sdto.MomentComment{}, errorx.NewInternalErr()
+				}
 			}
+			authors[commentModel.AuthorID] = author
 		}
 
-		comment.Id = commentModel.CommentID
-		comment.CreatedAt = *commentModel.CreatedAt
-		comment.Message = commentModel.Content
-		comment.Author.Name = author.Username
-		comment.Author.AvatarUrl = url
+		return sdto.MomentComment{
+			Id:        commentModel.CommentID,
+			ParentId:  commentModel.ParentID,
+			AuthorID:  commentModel.AuthorID,
+			Author:    author,
+			CreatedAt: *commentModel.CreatedAt,
+			EditedAt:  commentModel.EditedAt,
+			Message:   commentModel.Content,
+		}, nil
+	}
+
+	repliesByParent := map[string][]sdto.MomentComment{}
+	for _, reply := range replies {
+		if blocked.Hides(reply.AuthorID) {
+			continue
+		}
+
+		comment, sErr := toComment(reply)
+		if sErr != nil {
+			return nil, sErr
+		}
+		repliesByParent[*reply.ParentID] = append(repliesByParent[*reply.ParentID], comment)
+	}
+
+	commentlist := []sdto.MomentComment{}
+	for _, thread := range threads {
+		if blocked.Hides(thread.AuthorID) {
+			continue
+		}
+
+		comment, sErr := toComment(thread)
+		if sErr != nil {
+			return nil, sErr
+		}
+		comment.Replies = repliesByParent[thread.CommentID]
+		if comment.Replies == nil {
+			comment.Replies = []sdto.MomentComment{}
+		}
 
 		commentlist = append(commentlist, comment)
 	}
 
 	return &sdto.GetCommentListOutput{
 		CommentList: commentlist,
+		Total:       total,
 	}, nil
 }
 
@@ -305,16 +579,19 @@ func (m *MomentService) IsLiked(ctx context.Context, momentId, userId string) (b
 	return likeModel != nil, nil
 }
 
-func (m *MomentService) GetByUserID(ctx context.Context, userID string) (*sdto.GetMomentOutput, *errorx.ServiceErr) {
-	moments, err := dao.GetMomentsByUserID(ctx, userID)
-	if err != nil {
-		zlog.Error("Failed to retrieve moments", zap.String("userID", userID), zap.Error(err))
-		return nil, errorx.NewInternalErr()
+// GetByUserID returns the moments of the user if the viewer may see the profile of the user,
+// routes are left out if the user hides them
+func (m *MomentService) GetByUserID(ctx context.Context, viewer *sdto.Viewer, userID string) (*sdto.GetMomentOutput, *errorx.ServiceErr) {
+	user, sErr := privacy.Service().CanViewByID(ctx, viewer, userID)
+	if sErr != nil {
+		return nil, sErr
 	}
+	showRoutes := privacy.CanSeeRoutes(viewer, user)
+	trimmer := privacy.Service().Trimmer(viewer)
 
-	user, err := dao.GetUserByID(ctx, userID)
+	moments, err := dao.GetMomentsByUserID(ctx, userID)
 	if err != nil {
-		zlog.Error("Error while retrieve user info", zap.String("userID", userID), zap.Error(err))
+		zlog.Error("Failed to retrieve moments", zap.String("userID", userID), zap.Error(err))
 		return nil, errorx.NewInternalErr()
 	}
 
@@ -325,27 +602,10 @@ func (m *MomentService) GetByUserID(ctx context.Context, userID string) (*sdto.G
 	}
 
 	for i, moment := range moments {
-		// Enhance moment with image, video and route info
-		if moment.ImageURL != nil {
-			url, err := minio.GetMomentImageUrl(ctx, *moment.ImageURL)
-			if err != nil {
-				zlog.Error("Error while get moment image url", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
-
-			moment.ImageURL = &url
+		// Enhance moment with route info
+		if moment.RouteID != nil && !showRoutes {
+			moment.RouteID = nil
 		}
-
-		if moment.VideoURL != nil {
-			url, err := minio.GetMomentImageUrl(ctx, *moment.VideoURL)
-			if err != nil {
-				zlog.Error("Error while get moment video url", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
-
-			moment.VideoURL = &url
-		}
-
 		if moment.RouteID != nil {
 			path, err := dao.GetPathAsText(ctx, *moment.RouteID)
 			if err != nil {
@@ -359,12 +619,20 @@ func (m *MomentService) GetByUserID(ctx context.Context, userID string) (*sdto.G
 				return nil, errorx.NewInternalErr()
 			}
 
-			res.GPXRouteText[i] = util.GPXStrTo2DString(pathText)
+			route, sErr := trimmer.Trim(ctx, userID, util.GPXStrTo2DString(pathText))
+			if sErr != nil {
+				return nil, sErr
+			}
+			res.GPXRouteText[i] = route
 		}
 	}
 
 	res.Moments = moments
 
+	if res.Attachments, sErr = attachments(ctx, moments); sErr != nil {
+		return nil, sErr
+	}
+
 	return res, nil
 }
 
@@ -404,28 +672,71 @@ func (m *MomentService) GetLatestByUserID(ctx context.Context, userID string) (*
 		User:         user,
 	}
 
-	// Enhance moment with image, video and route info
-	if moment != nil {
-		if moment.ImageURL != nil {
-			url, err := minio.GetMomentImageUrl(ctx, *moment.ImageURL)
-			if err != nil {
-				zlog.Error("Error while get moment image url", zap.Error(err))
-				return nil, errorx.NewInternalErr()
-			}
+	attached, sErr := attachments(ctx, []*model.Moment{moment})
+	if sErr != nil {
+		return nil, sErr
+	}
+	res.Attachments = attached[moment.MomentID]
+
+	return res, nil
+}
+
+// attachments returns the attachments of the moments in order, keyed by moment ID, with a
+// presigned URL for the images and videos. Routes are left out of moments whose route is hidden,
+// the route itself comes with the moment.
+func attachments(ctx context.Context, moments []*model.Moment) (map[string][]*sdto.MomentAttachment, *errorx.ServiceErr) {
+	res := make(map[string][]*sdto.MomentAttachment, len(moments))
+	ids := make([]string, len(moments))
+	showRoute := make(map[string]bool, len(moments))
+	for i, moment := range moments {
+		ids[i] = moment.MomentID
+		showRoute[moment.MomentID] = moment.RouteID != nil
+		res[moment.MomentID] = []*sdto.MomentAttachment{}
+	}
 
-			moment.ImageURL = &url
+	rows, err := dao.GetAttachmentsByMomentIDs(ctx, ids)
+	if err != nil {
+		zlog.Error("Error while get moment attachments", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	for _, row := range rows {
+		attachment := &sdto.MomentAttachment{
+			Type:        row.Kind,
+			ContentType: row.ContentType,
+			Width:       row.Width,
+			Height:      row.Height,
 		}
 
-		if moment.VideoURL != nil {
-			url, err := minio.GetMomentImageUrl(ctx, *moment.VideoURL)
+		switch row.Kind {
+		case dao.ATTACHMENT_IMAGE, dao.ATTACHMENT_VIDEO:
+			url, err := minio.GetMomentImageUrl(ctx, *row.ObjectName)
+			if row.Kind == dao.ATTACHMENT_VIDEO {
+				url, err = minio.GetMomentVideoUrl(ctx, *row.ObjectName)
+			}
 			if err != nil {
-				zlog.Error("Error while get moment video url", zap.Error(err))
+				zlog.Error("Error while get moment media url from minio", zap.String("momentID", row.MomentID), zap.Error(err))
 				return nil, errorx.NewInternalErr()
 			}
-
-			moment.VideoURL = &url
+			attachment.URL = url
+		case dao.ATTACHMENT_ROUTE:
+			if !showRoute[row.MomentID] {
+				continue
+			}
 		}
+
+		res[row.MomentID] = append(res[row.MomentID], attachment)
 	}
 
 	return res, nil
 }
+
+// maxImages returns how many images a moment can have
+func maxImages() int {
+	max, err := strconv.Atoi(config.Get("moment.maxImages"))
+	if err != nil || max <= 0 {
+		return defaultMaxImages
+	}
+
+	return max
+}
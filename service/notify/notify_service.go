@@ -7,9 +7,12 @@ import (
 	"api.backend.xjco2913/dao"
 	"api.backend.xjco2913/dao/minio"
 	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/block"
 	"api.backend.xjco2913/service/gpx"
+	"api.backend.xjco2913/service/privacy"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/trending"
 	"api.backend.xjco2913/util"
 	"api.backend.xjco2913/util/zlog"
 	"github.com/google/uuid"
@@ -17,6 +20,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// notifications of the system are sent in the name of this user
+const systemSenderID = "03616eec-dd45-11ee-bf61-0242ac150006" // hard code as user 'yuerfei'
+
 type NotifyService struct{}
 
 var (
@@ -40,6 +46,9 @@ func (n *NotifyService) Pull(ctx context.Context, userId string) (*sdto.PullNoti
 		return nil, errorx.NewInternalErr()
 	}
 
+	// routes shared by other users are shown without their privacy zones
+	trimmer := privacy.Service().Trimmer(&sdto.Viewer{UserID: userId})
+
 	res := make([]*sdto.Notification, len(notifications))
 	for i, notification := range notifications {
 		res[i] = &sdto.Notification{}
@@ -76,7 +85,17 @@ func (n *NotifyService) Pull(ctx context.Context, userId string) (*sdto.PullNoti
 				return nil, errorx.NewInternalErr()
 			}
 
-			routeData = util.GPXStrTo2DString(pathText)
+			// the zones of the rider are trimmed, routes of unknown riders count as the sender's
+			ownerID := notification.SenderID
+			if notification.RouteOwnerID != nil {
+				ownerID = *notification.RouteOwnerID
+			}
+
+			var sErr *errorx.ServiceErr
+			routeData, sErr = trimmer.Trim(ctx, ownerID, util.GPXStrTo2DString(pathText))
+			if sErr != nil {
+				return nil, sErr
+			}
 		}
 
 		// get organiser result
@@ -89,6 +108,7 @@ func (n *NotifyService) Pull(ctx context.Context, userId string) (*sdto.PullNoti
 		res[i].Sender = &sender
 		res[i].Route = routeData
 		res[i].OrgResult = orgResult
+		res[i].Content = notification.Content
 		res[i].Type = notification.Type
 		res[i].CreatedAt = notification.CreatedAt
 
@@ -106,6 +126,35 @@ func (n *NotifyService) Pull(ctx context.Context, userId string) (*sdto.PullNoti
 }
 
 func (n *NotifyService) ShareRoute(ctx context.Context, in *sdto.ShareRouteInput) *errorx.ServiceErr {
+	if util.IsEmpty(in.SenderID) {
+		return errorx.NewServicerErr(403, "User ID is required", nil)
+	}
+	if sErr := block.Service().Check(ctx, in.SenderID, in.ReceiverID); sErr != nil {
+		return sErr
+	}
+
+	// a route shared from a moment is the route of its author
+	ownerID := in.SenderID
+	var moment *model.Moment
+	if in.MomentID != "" {
+		var err error
+		moment, err = dao.GetMomentByID(ctx, in.MomentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errorx.NewServicerErr(errorx.ErrExternal, "Moment not found by moment ID", nil)
+			}
+
+			zlog.Error("Failed to retrieve moment by moment ID", zap.String("momentID", in.MomentID), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+		ownerID = moment.AuthorID
+
+		// only moments the sender can see are shared, and made to trend
+		if _, sErr := privacy.Service().CanViewByID(ctx, &sdto.Viewer{UserID: in.SenderID}, moment.AuthorID); sErr != nil {
+			return sErr
+		}
+	}
+
 	gpxResp, sErr := gpx.Service().ParseLonLatData(ctx, &sdto.ParseLonLatDataInput{
 		LonLatData: in.RouteData,
 	})
@@ -116,9 +165,10 @@ func (n *NotifyService) ShareRoute(ctx context.Context, in *sdto.ShareRouteInput
 	newNotificationId := uuid.New()
 	newNotification := model.Notification{
 		NotificationID: newNotificationId.String(),
-		SenderID:       "03616eec-dd45-11ee-bf61-0242ac150006", // hard code as user 'yuerfei'
+		SenderID:       in.SenderID,
 		ReceiverID:     in.ReceiverID,
 		RouteID:        &gpxResp.RouteID,
+		RouteOwnerID:   &ownerID,
 		Type:           2,
 		Status:         -1,
 	}
@@ -129,6 +179,11 @@ func (n *NotifyService) ShareRoute(ctx context.Context, in *sdto.ShareRouteInput
 		return errorx.NewInternalErr()
 	}
 
+	// routes shared from a moment make the moment trend
+	if moment != nil {
+		trending.Service().Moment(ctx, in.SenderID, moment, trending.SIGNAL_SHARE)
+	}
+
 	return nil
 }
 
@@ -137,7 +192,7 @@ func (n *NotifyService) OrgResult(ctx context.Context, in *sdto.OrgResultInput)
 	newNotification := model.Notification{
 		NotificationID: newNotificationId.String(),
 		ReceiverID:     in.ReceiverID,
-		SenderID:       "03616eec-dd45-11ee-bf61-0242ac150006", // hard code as user 'yuerfei'
+		SenderID:       systemSenderID,
 		Type:           1,
 		Status:         -1,
 	}
@@ -159,6 +214,26 @@ func (n *NotifyService) OrgResult(ctx context.Context, in *sdto.OrgResultInput)
 	return nil
 }
 
+// System sends a text notification, e.g. a membership reminder
+func (n *NotifyService) System(ctx context.Context, receiverID string, content string) *errorx.ServiceErr {
+	newNotification := model.Notification{
+		NotificationID: uuid.NewString(),
+		ReceiverID:     receiverID,
+		SenderID:       systemSenderID,
+		Type:           3,
+		Status:         -1,
+		Content:        &content,
+	}
+
+	err := dao.PushNotification(ctx, &newNotification)
+	if err != nil {
+		zlog.Error("error while push system notification", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
 func (n *NotifyService) UnreadCount(ctx context.Context, userId string) (int, *errorx.ServiceErr) {
 	unread, err := dao.GetUnreadNotificationByUserId(ctx, userId)
 	if err != nil {
@@ -3,13 +3,12 @@ package organiser
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"api.backend.xjco2913/dao"
 	"api.backend.xjco2913/dao/minio"
-	"api.backend.xjco2913/dao/redis"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
 	"api.backend.xjco2913/util/zlog"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -94,13 +93,8 @@ func (o *OrganiserService) Agree(ctx context.Context, userId string) *errorx.Ser
 		return errorx.NewInternalErr()
 	}
 
-	// async delete token cache
-	go func() {
-		cacheKey := fmt.Sprintf("jwt:%s", userModel.Username)
-		redis.RDB().Del(ctx, cacheKey).Err()
-	}()
-
-	return nil
+	// organiser status is part of the claims, make clients refresh their tokens
+	return token.Service().Outdate(ctx, userModel.UserID)
 }
 
 func (o *OrganiserService) Refuse(ctx context.Context, userId string) *errorx.ServiceErr {
@@ -125,13 +119,8 @@ func (o *OrganiserService) Refuse(ctx context.Context, userId string) *errorx.Se
 		return errorx.NewInternalErr()
 	}
 
-	// async delete token cache
-	go func() {
-		cacheKey := fmt.Sprintf("jwt:%s", userModel.Username)
-		redis.RDB().Del(ctx, cacheKey).Err()
-	}()
-
-	return nil
+	// organiser status is part of the claims, make clients refresh their tokens
+	return token.Service().Outdate(ctx, userModel.UserID)
 }
 
 func (o *OrganiserService) Apply(ctx context.Context, userId string) *errorx.ServiceErr {
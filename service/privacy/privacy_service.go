@@ -0,0 +1,161 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/block"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Who may see a profile, together with its moments and follow lists
+const (
+	PROFILE_PUBLIC    int32 = 0
+	PROFILE_FOLLOWERS int32 = 1
+	PROFILE_PRIVATE   int32 = 2
+)
+
+// Who may follow a user
+const (
+	FOLLOW_EVERYONE int32 = 0
+	FOLLOW_NOBODY   int32 = 1
+	FOLLOW_APPROVAL int32 = 2
+)
+
+type PrivacyService struct{}
+
+var (
+	privacyService PrivacyService
+)
+
+func Service() *PrivacyService {
+	return &privacyService
+}
+
+func (p *PrivacyService) Get(ctx context.Context, userID string) (*sdto.PrivacySettings, *errorx.ServiceErr) {
+	user, sErr := getUser(ctx, userID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return &sdto.PrivacySettings{
+		ProfileVisibility: user.ProfileVisibility,
+		HideBirthday:      user.HideBirthday,
+		HideRoutes:        user.HideRoutes,
+		FollowPolicy:      user.FollowPolicy,
+	}, nil
+}
+
+// Update changes the settings that are set in the input
+func (p *PrivacyService) Update(ctx context.Context, userID string, in *sdto.UpdatePrivacyInput) (*sdto.PrivacySettings, *errorx.ServiceErr) {
+	updates := make(map[string]interface{})
+
+	if in.ProfileVisibility != nil {
+		if *in.ProfileVisibility < PROFILE_PUBLIC || *in.ProfileVisibility > PROFILE_PRIVATE {
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "Profile visibility must be 0, 1 or 2", nil)
+		}
+		updates["profileVisibility"] = *in.ProfileVisibility
+	}
+	if in.FollowPolicy != nil {
+		if *in.FollowPolicy < FOLLOW_EVERYONE || *in.FollowPolicy > FOLLOW_APPROVAL {
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "Follow policy must be 0, 1 or 2", nil)
+		}
+		updates["followPolicy"] = *in.FollowPolicy
+	}
+	if in.HideBirthday != nil {
+		updates["hideBirthday"] = *in.HideBirthday
+	}
+	if in.HideRoutes != nil {
+		updates["hideRoutes"] = *in.HideRoutes
+	}
+
+	if len(updates) > 0 {
+		if err := dao.UpdateUserByID(ctx, userID, updates); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+			}
+			zlog.Error("Failed to update privacy settings", zap.String("userID", userID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+	}
+
+	return p.Get(ctx, userID)
+}
+
+// IsSelf reports whether the viewer sees everything of owner, being the user or an admin
+func IsSelf(viewer *sdto.Viewer, owner *model.User) bool {
+	return viewer.IsAdmin || viewer.UserID == owner.UserID
+}
+
+// CanView reports whether the viewer may see the profile, moments and follow lists of owner,
+// never if either of them blocked the other
+func (p *PrivacyService) CanView(ctx context.Context, viewer *sdto.Viewer, owner *model.User) (bool, *errorx.ServiceErr) {
+	if IsSelf(viewer, owner) {
+		return true, nil
+	}
+
+	blocked, sErr := block.Service().IsBlocked(ctx, viewer.UserID, owner.UserID)
+	if sErr != nil {
+		return false, sErr
+	}
+	if blocked {
+		return false, nil
+	}
+
+	switch owner.ProfileVisibility {
+	case PROFILE_PUBLIC:
+		return true, nil
+	case PROFILE_FOLLOWERS:
+		follows, err := dao.CheckIsFollowed(ctx, viewer.UserID, owner.UserID)
+		if err != nil {
+			zlog.Error("Error while checking follow relation", zap.String("userID", viewer.UserID), zap.Error(err))
+			return false, errorx.NewInternalErr()
+		}
+		return follows, nil
+	default:
+		return false, nil
+	}
+}
+
+// CanViewByID is CanView for a user that is not loaded yet
+func (p *PrivacyService) CanViewByID(ctx context.Context, viewer *sdto.Viewer, ownerID string) (*model.User, *errorx.ServiceErr) {
+	owner, sErr := getUser(ctx, ownerID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	ok, sErr := p.CanView(ctx, viewer, owner)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if !ok {
+		return nil, errorx.NewServicerErr(403, "This profile is private", nil)
+	}
+
+	return owner, nil
+}
+
+// CanSeeRoutes reports whether the routes of moments of owner are shown to the viewer
+func CanSeeRoutes(viewer *sdto.Viewer, owner *model.User) bool {
+	return IsSelf(viewer, owner) || !owner.HideRoutes
+}
+
+func getUser(ctx context.Context, userID string) (*model.User, *errorx.ServiceErr) {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Warn("User not found", zap.String("userID", userID))
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return user, nil
+}
@@ -0,0 +1,143 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+)
+
+// Privacy zones cut points out of the routes of a user for everyone but the user and admins,
+// the stored routes are never changed
+const (
+	maxPrivacyZones = 5
+	minZoneRadius   = 100
+	maxZoneRadius   = 5000
+)
+
+func (p *PrivacyService) Zones(ctx context.Context, userID string) ([]*sdto.PrivacyZone, *errorx.ServiceErr) {
+	zones, err := dao.GetPrivacyZonesByUserID(ctx, userID)
+	if err != nil {
+		zlog.Error("Failed to get privacy zones", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := make([]*sdto.PrivacyZone, 0, len(zones))
+	for _, zone := range zones {
+		res = append(res, toPrivacyZone(zone))
+	}
+
+	return res, nil
+}
+
+func (p *PrivacyService) CreateZone(ctx context.Context, in *sdto.PrivacyZoneInput) (*sdto.PrivacyZone, *errorx.ServiceErr) {
+	if in.Latitude < -90 || in.Latitude > 90 || in.Longitude < -180 || in.Longitude > 180 {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid coordinates", nil)
+	}
+	if in.Radius < minZoneRadius || in.Radius > maxZoneRadius {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, fmt.Sprintf("Radius must be between %d and %d meters", minZoneRadius, maxZoneRadius), nil)
+	}
+
+	if _, sErr := getUser(ctx, in.UserID); sErr != nil {
+		return nil, sErr
+	}
+
+	count, err := dao.CountPrivacyZones(ctx, in.UserID)
+	if err != nil {
+		zlog.Error("Failed to count privacy zones", zap.String("userID", in.UserID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if count >= maxPrivacyZones {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, fmt.Sprintf("At most %d privacy zones are allowed", maxPrivacyZones), nil)
+	}
+
+	zone := &model.PrivacyZone{
+		UserID:    in.UserID,
+		Name:      in.Name,
+		Latitude:  in.Latitude,
+		Longitude: in.Longitude,
+		Radius:    in.Radius,
+	}
+	if err := dao.CreatePrivacyZone(ctx, zone); err != nil {
+		zlog.Error("Failed to create privacy zone", zap.String("userID", in.UserID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return toPrivacyZone(zone), nil
+}
+
+func (p *PrivacyService) DeleteZone(ctx context.Context, userID string, zoneID int32) *errorx.ServiceErr {
+	deleted, err := dao.DeletePrivacyZone(ctx, userID, zoneID)
+	if err != nil {
+		zlog.Error("Failed to delete privacy zone", zap.String("userID", userID), zap.Int32("zoneID", zoneID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !deleted {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Privacy zone not found", nil)
+	}
+
+	return nil
+}
+
+// RouteTrimmer trims the routes of any number of users for one viewer, loading the zones
+// of every user once
+type RouteTrimmer struct {
+	viewer *sdto.Viewer
+	zones  map[string][]util.Zone
+}
+
+func (p *PrivacyService) Trimmer(viewer *sdto.Viewer) *RouteTrimmer {
+	return &RouteTrimmer{
+		viewer: viewer,
+		zones:  make(map[string][]util.Zone),
+	}
+}
+
+// Trim leaves out the points of a [[lon, lat]...] route of owner inside the privacy zones of owner
+func (t *RouteTrimmer) Trim(ctx context.Context, ownerID string, route [][]string) ([][]string, *errorx.ServiceErr) {
+	if t.viewer.IsAdmin || t.viewer.UserID == ownerID {
+		return route, nil
+	}
+
+	zones, ok := t.zones[ownerID]
+	if !ok {
+		models, err := dao.GetPrivacyZonesByUserID(ctx, ownerID)
+		if err != nil {
+			zlog.Error("Failed to get privacy zones", zap.String("userID", ownerID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+
+		zones = make([]util.Zone, 0, len(models))
+		for _, zone := range models {
+			zones = append(zones, util.Zone{
+				Latitude:  zone.Latitude,
+				Longitude: zone.Longitude,
+				Radius:    float64(zone.Radius),
+			})
+		}
+		t.zones[ownerID] = zones
+	}
+
+	return util.TrimRoute(route, zones), nil
+}
+
+// TrimRoute is Trim for a single route
+func (p *PrivacyService) TrimRoute(ctx context.Context, viewer *sdto.Viewer, ownerID string, route [][]string) ([][]string, *errorx.ServiceErr) {
+	return p.Trimmer(viewer).Trim(ctx, ownerID, route)
+}
+
+func toPrivacyZone(zone *model.PrivacyZone) *sdto.PrivacyZone {
+	return &sdto.PrivacyZone{
+		ZoneID:    zone.ID,
+		Name:      zone.Name,
+		Latitude:  zone.Latitude,
+		Longitude: zone.Longitude,
+		Radius:    zone.Radius,
+	}
+}
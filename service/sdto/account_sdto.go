@@ -0,0 +1,23 @@
+package sdto
+
+import (
+	"context"
+	"io"
+)
+
+type AccountDeletionStatus struct {
+	UserID      string `json:"userID"`
+	Status      string `json:"status"`
+	Step        string `json:"step"`
+	Progress    int    `json:"progress"`
+	Error       string `json:"error,omitempty"`
+	RequestedBy string `json:"requestedBy"`
+	RequestedAt int64  `json:"requestedAt"`
+	FinishedAt  int64  `json:"finishedAt,omitempty"`
+}
+
+type ExportDataOutput struct {
+	FileName string
+	// WriteTo streams the zip archive, the data is read before so that it only fails on io errors
+	WriteTo func(ctx context.Context, w io.Writer) error
+}
@@ -78,6 +78,8 @@ type SignUpActivityInput struct {
 	UserID         string
 	ActivityID     string
 	MembershipType int64
+	// pay as much of the fee as possible with credits
+	UseCredits bool
 }
 
 type GetActivitiesByUserID struct {
@@ -143,9 +145,49 @@ type UploadRouteInput struct {
 type GetRouteInput struct {
 	ActivityID string
 	UserID     string
+	Viewer     *Viewer
 }
 
 type GetRouteOutput struct {
 	GPXRouteText map[int][][]string
 	AvatarUrl    string
 }
+
+type RevenueReportInput struct {
+	StartDate string
+	EndDate   string
+	Interval  string
+	GroupBy   string
+	Timezone  string
+}
+
+type RevenueBucket struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Label string    `json:"label"`
+}
+
+type RevenueSeries struct {
+	Key     string  `json:"key"`
+	Name    string  `json:"name"`
+	Revenue []int64 `json:"revenue"`
+	Signups []int64 `json:"signups"`
+	Total   int64   `json:"total"`
+}
+
+type RevenueReportOutput struct {
+	Interval string
+	GroupBy  string
+	Timezone string
+	Buckets  []*RevenueBucket
+	Series   []*RevenueSeries
+	// every activity counted once, whatever the grouping
+	Total   int64
+	Signups int64
+}
+
+type ExportRevenueReportOutput struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
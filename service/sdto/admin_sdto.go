@@ -3,10 +3,15 @@ package sdto
 type AdminAuthenticateInput struct {
 	Name     string
 	Password string
+	Device   SessionDevice
 }
 
 type AdminAuthenticateOuput struct {
-	Token   string
-	AdminId string
-	Name    string
+	Token        string
+	RefreshToken string
+	ExpiresAt    int64
+	AdminId      string
+	Name         string
+	// set instead of the tokens when a second factor is required
+	Challenge *TwoFactorChallenge
 }
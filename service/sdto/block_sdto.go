@@ -0,0 +1,9 @@
+package sdto
+
+// RelatedUser is a user the user blocked or muted
+type RelatedUser struct {
+	UserID    string `json:"userId"`
+	Username  string `json:"username"`
+	AvatarUrl string `json:"avatarUrl"`
+	CreatedAt int64  `json:"createdAt"`
+}
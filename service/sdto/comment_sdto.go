@@ -1,7 +1,22 @@
 package sdto
 
+import "time"
+
 type CreateCommentInput struct {
 	AuthorID string
 	MomentID string
 	Content  string
+	// empty for top-level comments
+	ParentID string
+}
+
+type EditCommentInput struct {
+	UserID    string
+	CommentID string
+	Content   string
+}
+
+type CommentEdit struct {
+	Content  string     `json:"content"`
+	EditedAt *time.Time `json:"editedAt"`
 }
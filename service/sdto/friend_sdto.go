@@ -1,17 +1,28 @@
 package sdto
 
-import "api.backend.xjco2913/dao/model"
-
 type FollowInput struct {
 	FollowerId  string
 	FollowingId string
 }
 
+type FollowOutput struct {
+	// following, or requested if the user approves followers
+	Status string `json:"status"`
+}
+
+type FollowPageInput struct {
+	Viewer   *Viewer
+	UserID   string
+	Page     int
+	PageSize int
+}
+
 type Follower struct {
 	UserID     string `json:"userId"`
 	AvatarUrl  string `json:"avatarUrl"`
 	Username   string `json:"username"`
 	Region     string `json:"region"`
+	FollowedAt int64  `json:"followedAt"`
 	IsFollowed bool   `json:"isFollowed"`
 }
 
@@ -20,11 +31,20 @@ type GetAllFollowerOutput struct {
 }
 
 type GetAllFollowingOutput struct {
-	Followings []*model.User
+	Followings []*Follower
 }
 
 type GetAllFriendsOutput struct {
-	Friends []*model.User
+	Friends []*Follower
+}
+
+type FollowRequest struct {
+	RequestID   int32  `json:"requestId"`
+	UserID      string `json:"userId"`
+	AvatarUrl   string `json:"avatarUrl"`
+	Username    string `json:"username"`
+	Region      string `json:"region"`
+	RequestedAt int64  `json:"requestedAt"`
 }
 
 type FollowerCountOutput struct {
@@ -1,11 +1,19 @@
 package sdto
 
+import "time"
+
 type ParseGPXDataInput struct {
 	GPXData []byte
 }
 
 type ParseGPXDataOutput struct {
 	RouteID int32
+	// in meters
+	Distance      float64
+	ElevationGain float64
+	// zero if the gpx has no timestamps
+	StartTime time.Time
+	Best10K   time.Duration
 }
 
 type ParseLonLatDataInput struct {
@@ -0,0 +1,58 @@
+package sdto
+
+import "time"
+
+type MembershipPlan struct {
+	Name           string `json:"name"`
+	MembershipType int32  `json:"membershipType"`
+	Price          int32  `json:"price"`
+	PeriodDays     int    `json:"periodDays"`
+}
+
+type MembershipRecord struct {
+	Action   string `json:"action"`
+	FromType int32  `json:"fromType"`
+	ToType   int32  `json:"toType"`
+	// charged for the change, negative for refunds
+	Amount    int32 `json:"amount"`
+	ExpiresAt int64 `json:"expiresAt"`
+	CreatedAt int64 `json:"createdAt"`
+}
+
+type MembershipOutput struct {
+	MembershipType int32               `json:"membershipType"`
+	ExpiresAt      int64               `json:"expiresAt"`
+	AutoRenew      bool                `json:"autoRenew"`
+	TrialAvailable bool                `json:"trialAvailable"`
+	History        []*MembershipRecord `json:"history"`
+}
+
+type VoucherBatchInput struct {
+	AdminID        string
+	Name           string
+	MembershipType int32
+	Days           int32
+	Quantity       int32
+	// nil if the codes do not expire
+	RedeemBy *time.Time
+}
+
+type VoucherBatch struct {
+	BatchID        int32  `json:"batchId"`
+	Name           string `json:"name"`
+	MembershipType int32  `json:"membershipType"`
+	Days           int32  `json:"days"`
+	Quantity       int32  `json:"quantity"`
+	Redeemed       int64  `json:"redeemed"`
+	// 0 if the codes do not expire
+	RedeemBy  int64  `json:"redeemBy"`
+	CreatedBy string `json:"createdBy"`
+	CreatedAt int64  `json:"createdAt"`
+	RevokedAt int64  `json:"revokedAt,omitempty"`
+}
+
+type VoucherBatchOutput struct {
+	*VoucherBatch
+	// shown only once, only their hashes are stored
+	Codes []string `json:"codes"`
+}
@@ -9,29 +9,38 @@ import (
 type CreateMomentInput struct {
 	UserID  string
 	Content string
+	// in the order they are shown
+	Images [][]byte
+	Video  []byte
+	// the content type the client sent for the video, used if the content does not tell
+	VideoContentType string
+	GPXData          []byte
 }
 
-type CreateMomentImageInput struct {
-	UserID    string
-	Content   string
-	ImageData []byte
+// MomentAttachment is an image, video or route of a moment. Images and videos have a presigned URL,
+// the route of a moment is sent with the moment.
+type MomentAttachment struct {
+	Type        string  `json:"type"`
+	URL         string  `json:"url,omitempty"`
+	ContentType *string `json:"contentType,omitempty"`
+	Width       *int32  `json:"width,omitempty"`
+	Height      *int32  `json:"height,omitempty"`
 }
 
-type CreateMomentVideoInput struct {
-	UserID    string
-	Content   string
-	VideoData []byte
+type FeedMomentInput struct {
+	UserID     string
+	LatestTime int64
 }
 
-type CreateMomentGPXInput struct {
-	UserID  string
-	Content string
-	GPXData []byte
+type TrendingInput struct {
+	UserID string
+	// empty for the ranking of everywhere
+	Region string
 }
 
-type FeedMomentInput struct {
-	UserID     string
-	LatestTime int64
+type EditMomentInput struct {
+	MomentID string
+	Content  string
 }
 
 type FeedMomentOutput struct {
@@ -39,6 +48,8 @@ type FeedMomentOutput struct {
 	AuthorInfoMap map[string]*model.User
 	NextTime      int64
 	GPXRouteText  map[int][][]string
+	// keyed by moment ID
+	Attachments map[string][]*MomentAttachment
 }
 
 type MomentUser struct {
@@ -46,29 +57,46 @@ type MomentUser struct {
 	AvatarUrl string `json:"avatarUrl"`
 }
 
+// MomentComment is a top-level comment with the replies in its thread, or a reply with its parentId
 type MomentComment struct {
-	Id        string     `json:"id"`
-	Author    MomentUser `json:"author"`
-	CreatedAt time.Time  `json:"createdAt"`
-	Message   string     `json:"message"`
+	Id        string          `json:"id"`
+	ParentId  *string         `json:"parentId,omitempty"`
+	AuthorID  string          `json:"authorId"`
+	Author    MomentUser      `json:"author"`
+	CreatedAt time.Time       `json:"createdAt"`
+	EditedAt  *time.Time      `json:"editedAt"`
+	Message   string          `json:"message"`
+	Replies   []MomentComment `json:"replies,omitempty"`
 }
 
 type GetLikesOutput struct {
 	PersonLikes []MomentUser
 }
 
+type CommentPageInput struct {
+	MomentID string
+	Viewer   *Viewer
+	Page     int
+	PageSize int
+}
+
 type GetCommentListOutput struct {
 	CommentList []MomentComment
+	// number of top-level comments
+	Total int64
 }
 
 type GetMomentOutput struct {
 	Moments      []*model.Moment
 	GPXRouteText map[int][][]string
-	User         *model.User
+	// keyed by moment ID
+	Attachments map[string][]*MomentAttachment
+	User        *model.User
 }
 
 type GetLatestMomentOutput struct {
 	Moment       *model.Moment
 	GPXRouteText [][]string
+	Attachments  []*MomentAttachment
 	User         *model.User
 }
@@ -14,6 +14,7 @@ type Notification struct {
 	Route          [][]string  `json:"route"`
 	OrgResult      *int32       `json:"orgResult"`
 	Type           int32       `json:"type"`
+	Content        *string     `json:"content"`
 	CreatedAt      *time.Time  `json:"createdAt"`
 }
 
@@ -22,8 +23,11 @@ type PullNotificationOutput struct {
 }
 
 type ShareRouteInput struct {
+	SenderID   string
 	ReceiverID string
 	RouteData  [][]string
+	// the moment the route is shared from, if any
+	MomentID string
 }
 
 type OrgResultInput struct {
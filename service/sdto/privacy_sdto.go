@@ -0,0 +1,39 @@
+package sdto
+
+// Viewer is whoever makes a request for data of a user
+type Viewer struct {
+	// empty for admins
+	UserID  string
+	IsAdmin bool
+}
+
+type PrivacySettings struct {
+	ProfileVisibility int32 `json:"profileVisibility"`
+	HideBirthday      bool  `json:"hideBirthday"`
+	HideRoutes        bool  `json:"hideRoutes"`
+	FollowPolicy      int32 `json:"followPolicy"`
+}
+
+type UpdatePrivacyInput struct {
+	ProfileVisibility *int32
+	HideBirthday      *bool
+	HideRoutes        *bool
+	FollowPolicy      *int32
+}
+
+type PrivacyZoneInput struct {
+	UserID    string
+	Name      string
+	Latitude  float64
+	Longitude float64
+	// in meters
+	Radius int32
+}
+
+type PrivacyZone struct {
+	ZoneID    int32   `json:"zoneId"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    int32   `json:"radius"`
+}
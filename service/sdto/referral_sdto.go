@@ -0,0 +1,15 @@
+package sdto
+
+type CreditEntry struct {
+	// negative when credits are spent
+	Amount    int32  `json:"amount"`
+	Reason    string `json:"reason"`
+	RefID     string `json:"refId"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+type CreditsOutput struct {
+	Balance      int32          `json:"balance"`
+	ReferralCode string         `json:"referralCode"`
+	Entries      []*CreditEntry `json:"entries"`
+}
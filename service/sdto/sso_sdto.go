@@ -0,0 +1,32 @@
+package sdto
+
+import "time"
+
+type SSOBeginOutput struct {
+	AuthURL   string
+	ExpiresAt int64
+}
+
+type SSOCallbackInput struct {
+	Provider string
+	State    string
+	Code     string
+	Device   SessionDevice
+}
+
+type SSOCallbackOutput struct {
+	UserID string
+	// the user has been created by this login
+	Created bool
+	// the identity has been linked to a logged in user, no tokens are issued
+	Linked    bool
+	Tokens    *TokenPair
+	Challenge *TwoFactorChallenge
+}
+
+type Identity struct {
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
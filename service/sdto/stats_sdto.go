@@ -0,0 +1,54 @@
+package sdto
+
+import "time"
+
+type StatsInput struct {
+	Viewer   *Viewer
+	UserID   string
+	Timezone string
+}
+
+// Distances and elevations are in meters
+type StatsBucket struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Distance      float64   `json:"distance"`
+	ElevationGain float64   `json:"elevationGain"`
+	Rides         int64     `json:"rides"`
+}
+
+type StatsOutput struct {
+	UserID              string         `json:"userId"`
+	TotalDistance       float64        `json:"totalDistance"`
+	TotalElevationGain  float64        `json:"totalElevationGain"`
+	RideCount           int32          `json:"rideCount"`
+	LongestRide         float64        `json:"longestRide"`
+	ActivitiesJoined    int64          `json:"activitiesJoined"`
+	ActivitiesOrganised int64          `json:"activitiesOrganised"`
+	Followers           int64          `json:"followers"`
+	Following           int64          `json:"following"`
+	Timezone            string         `json:"timezone"`
+	Weekly              []*StatsBucket `json:"weekly"`
+	Monthly             []*StatsBucket `json:"monthly"`
+}
+
+type Badge struct {
+	Badge     string `json:"badge"`
+	Title     string `json:"title"`
+	AwardedAt int64  `json:"awardedAt"`
+}
+
+// Value is in meters for distances and climbs, in seconds for times
+type PersonalRecord struct {
+	Record string  `json:"record"`
+	Title  string  `json:"title"`
+	Value  float64 `json:"value"`
+	Source string  `json:"source"`
+	RefID  string  `json:"refId"`
+	RodeAt int64   `json:"rodeAt"`
+}
+
+type AchievementsOutput struct {
+	Badges  []*Badge          `json:"badges"`
+	Records []*PersonalRecord `json:"records"`
+}
@@ -0,0 +1,25 @@
+package sdto
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    int64
+	SessionID    string
+}
+
+// SessionDevice describes the client a session was started from
+type SessionDevice struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
+
+type Session struct {
+	SessionID  string `json:"sessionId"`
+	DeviceName string `json:"deviceName"`
+	UserAgent  string `json:"userAgent"`
+	IP         string `json:"ip"`
+	CreatedAt  int64  `json:"createdAt"`
+	LastSeen   int64  `json:"lastSeen"`
+	Current    bool   `json:"current"`
+}
@@ -0,0 +1,19 @@
+package sdto
+
+type TwoFactorSetupOutput struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// TwoFactorChallenge is handed out instead of tokens when the password alone is not enough
+type TwoFactorChallenge struct {
+	ChallengeToken string
+	EnrollRequired bool
+	ExpiresAt      int64
+}
+
+type TwoFactorLoginOutput struct {
+	SubjectID     string
+	Tokens        *TokenPair
+	RecoveryCodes []string
+}
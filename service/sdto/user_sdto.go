@@ -1,25 +1,35 @@
 package sdto
 
+import "time"
+
 type CreateUserInput struct {
 	Username string
 	Password string
+	Email    string
 	Gender   int32
 	Birthday string
 	Region   string
+	// optional referral code of the user who referred the new user
+	ReferralCode string
 }
 
 type AuthenticateInput struct {
 	Username string
 	Password string
+	Device   SessionDevice
 }
 
 type AuthenticateOutput struct {
-	UserID    string
-	Token     string
-	Gender    int32
-	Birthday  string
-	Region    string
-	AvatarUrl string
+	UserID       string
+	Token        string
+	RefreshToken string
+	ExpiresAt    int64
+	Gender       int32
+	Birthday     string
+	Region       string
+	AvatarUrl    string
+	// set instead of the tokens when a second factor is required
+	Challenge *TwoFactorChallenge
 }
 
 type GetAllOutput struct {
@@ -44,11 +54,41 @@ type GetByIDOutput struct {
 	AvatarURL      string
 	IsOrganiser    bool
 	MembershipType int32
+	Email          string
+	EmailVerified  bool
+	Credits        int32
+	ReferralCode   string
+	// the viewer is not the user, the account details are left out
+	Restricted bool
 }
 
 type GetAllStatusOutput struct {
-	UserID   string
-	IsBanned bool
+	UserID     string
+	IsBanned   bool
+	ActiveBan  *BanRecord
+	BanHistory []*BanRecord
+}
+
+type BanInput struct {
+	// multiple users are separated using '|'
+	UserIDs string
+	AdminID string
+	Reason  string
+	// 0 bans permanently
+	Duration time.Duration
+}
+
+type BanRecord struct {
+	BanID   int32  `json:"banId"`
+	AdminID string `json:"adminId"`
+	Reason  string `json:"reason"`
+	StartAt int64  `json:"startAt"`
+	// 0 for a permanent ban
+	EndAt      int64  `json:"endAt"`
+	LiftedAt   int64  `json:"liftedAt,omitempty"`
+	LiftedBy   string `json:"liftedBy,omitempty"`
+	AppealNote string `json:"appealNote,omitempty"`
+	Active     bool   `json:"active"`
 }
 
 type UpdateUserInput struct {
@@ -56,6 +96,7 @@ type UpdateUserInput struct {
 	Gender   *int32
 	Birthday *string
 	Region   *string
+	Email    *string
 }
 
 type UploadAvatarInput struct {
@@ -64,7 +105,9 @@ type UploadAvatarInput struct {
 }
 
 type RefreshTokenOutput struct {
-	NewToken string
+	NewToken        string
+	NewRefreshToken string
+	ExpiresAt       int64
 }
 
 type MockUser struct {
@@ -0,0 +1,480 @@
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	"api.backend.xjco2913/service/twofactor"
+	"api.backend.xjco2913/service/user"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/oidc"
+	"api.backend.xjco2913/util/zlog"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	STATE_DURATION = 10 * time.Minute
+
+	// oidcState:<stateHash> => hash of a login started at a provider
+	stateKeyFmt = "oidcState:%s"
+
+	maxUsernameLength = 24
+	providerTimeout   = 10 * time.Second
+)
+
+type SSOService struct {
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider
+}
+
+var (
+	ssoService SSOService
+)
+
+func Service() *SSOService {
+	return &ssoService
+}
+
+// Providers lists the configured providers users can log in with
+func (s *SSOService) Providers() []string {
+	names := []string{}
+	for _, name := range config.Keys("oidc.providers") {
+		if !util.IsEmpty(config.Get(providerKey(name, "clientId"))) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Begin starts the authorization code flow. With linkUserID set the identity is linked
+// to that user on callback instead of logging in.
+func (s *SSOService) Begin(ctx context.Context, providerName string, linkUserID string, deviceName string) (*sdto.SSOBeginOutput, *errorx.ServiceErr) {
+	provider, sErr := s.provider(ctx, providerName)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	state, err := util.RandomToken(32)
+	if err != nil {
+		zlog.Error("Error while generating oidc state", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	nonce, err := util.RandomToken(32)
+	if err != nil {
+		zlog.Error("Error while generating oidc nonce", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		zlog.Error("Error while generating pkce verifier", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	key := fmt.Sprintf(stateKeyFmt, util.HashToken(state))
+	pipe := redis.RDB().TxPipeline()
+	pipe.HSet(ctx, key,
+		"provider", providerName,
+		"nonce", nonce,
+		"verifier", verifier,
+		"linkUserID", linkUserID,
+		"deviceName", deviceName,
+	)
+	pipe.Expire(ctx, key, STATE_DURATION)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while storing oidc state", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return &sdto.SSOBeginOutput{
+		AuthURL:   provider.AuthCodeURL(state, nonce, challenge),
+		ExpiresAt: time.Now().Add(STATE_DURATION).Unix(),
+	}, nil
+}
+
+// Callback redeems the code the provider redirected back with. The identity is resolved to a user,
+// who is created on the first login, and our usual token pair (or a 2FA challenge) is returned.
+func (s *SSOService) Callback(ctx context.Context, in *sdto.SSOCallbackInput) (*sdto.SSOCallbackOutput, *errorx.ServiceErr) {
+	state, sErr := s.consumeState(ctx, in.State)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if state["provider"] != in.Provider {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid or expired login state", nil)
+	}
+
+	provider, sErr := s.provider(ctx, in.Provider)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	tokenResp, err := provider.Exchange(ctx, in.Code, state["verifier"])
+	if err != nil {
+		zlog.Warn("Error while exchanging oidc code", zap.String("provider", in.Provider), zap.Error(err))
+		return nil, errorx.NewServicerErr(401, "Login at the identity provider failed", nil)
+	}
+
+	idToken, err := provider.VerifyIDToken(ctx, tokenResp.IDToken, state["nonce"])
+	if err != nil {
+		zlog.Warn("Invalid id token", zap.String("provider", in.Provider), zap.Error(err))
+		return nil, errorx.NewServicerErr(401, "Login at the identity provider failed", nil)
+	}
+
+	if linkUserID := state["linkUserID"]; linkUserID != "" {
+		if sErr := s.link(ctx, linkUserID, in.Provider, idToken); sErr != nil {
+			return nil, sErr
+		}
+
+		return &sdto.SSOCallbackOutput{
+			UserID: linkUserID,
+			Linked: true,
+		}, nil
+	}
+
+	userID, created, sErr := s.resolveUser(ctx, in.Provider, idToken)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if user.Service().IsBanned(ctx, userID) {
+		zlog.Warn("Attempted login by banned user", zap.String("userID", userID))
+		return nil, user.Service().BannedErr(ctx, userID)
+	}
+
+	in.Device.DeviceName = state["deviceName"]
+	out := &sdto.SSOCallbackOutput{
+		UserID:  userID,
+		Created: created,
+	}
+
+	// the provider replaces the password, not the second factor
+	challenge, sErr := twofactor.Service().BeginLogin(ctx, userID, false, &in.Device)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if challenge != nil {
+		out.Challenge = challenge
+		return out, nil
+	}
+
+	tokens, sErr := token.Service().Issue(ctx, userID, false, &in.Device)
+	if sErr != nil {
+		return nil, sErr
+	}
+	out.Tokens = tokens
+
+	return out, nil
+}
+
+func (s *SSOService) Identities(ctx context.Context, userID string) ([]*sdto.Identity, *errorx.ServiceErr) {
+	identities, err := dao.GetIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		zlog.Error("Error while getting identities", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	out := make([]*sdto.Identity, len(identities))
+	for i, identity := range identities {
+		out[i] = &sdto.Identity{
+			Provider:    identity.Provider,
+			CreatedAt:   identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		}
+		if identity.Email != nil {
+			out[i].Email = *identity.Email
+		}
+	}
+
+	return out, nil
+}
+
+// Unlink removes the identity of a provider. The last one is kept unless the user has a verified
+// email, users created through a provider do not know their password and could not get back in.
+func (s *SSOService) Unlink(ctx context.Context, userID string, providerName string) *errorx.ServiceErr {
+	identities, err := dao.GetIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		zlog.Error("Error while getting identities", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	if len(identities) == 1 && identities[0].Provider == providerName {
+		userModel, err := dao.GetUserByID(ctx, userID)
+		if err != nil {
+			zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+
+		if sErr := user.RequireVerifiedEmail(userModel); sErr != nil {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Verify an email before unlinking the last identity provider", nil)
+		}
+	}
+
+	deleted, err := dao.DeleteIdentity(ctx, userID, providerName)
+	if err != nil {
+		zlog.Error("Error while deleting identity", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if !deleted {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Identity not found", nil)
+	}
+
+	return nil
+}
+
+// resolveUser returns the user of an identity. Unknown identities are linked to the user owning the
+// same email if both sides verified it, otherwise a new user is created.
+func (s *SSOService) resolveUser(ctx context.Context, providerName string, idToken *oidc.IDToken) (string, bool, *errorx.ServiceErr) {
+	email := user.NormalizeEmail(idToken.Email)
+	var emailPtr *string
+	if email != "" {
+		emailPtr = &email
+	}
+
+	identity, err := dao.FindIdentity(ctx, providerName, idToken.Subject)
+	if err == nil {
+		if err := dao.TouchIdentity(ctx, identity.ID, emailPtr); err != nil {
+			zlog.Warn("Error while updating identity", zap.Int32("identityID", identity.ID), zap.Error(err))
+		}
+		return identity.UserID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		zlog.Error("Error while finding identity", zap.String("provider", providerName), zap.Error(err))
+		return "", false, errorx.NewInternalErr()
+	}
+
+	newIdentity := &model.UserIdentity{
+		Provider: providerName,
+		Subject:  idToken.Subject,
+		Email:    emailPtr,
+	}
+
+	// the email is only taken over if it is free, an unverified owner does not get the identity linked
+	userEmail := emailPtr
+	if email != "" {
+		owner, err := dao.FindUserByEmail(ctx, email)
+		if err == nil {
+			if idToken.EmailVerified && owner.EmailVerified {
+				newIdentity.UserID = owner.UserID
+				if err := dao.CreateIdentity(ctx, newIdentity); err != nil {
+					zlog.Error("Error while linking identity", zap.String("userID", owner.UserID), zap.Error(err))
+					return "", false, errorx.NewInternalErr()
+				}
+
+				zlog.Info("Identity linked by verified email", zap.String("userID", owner.UserID), zap.String("provider", providerName))
+				return owner.UserID, false, nil
+			}
+			userEmail = nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Error("Error while finding user by email", zap.Error(err))
+			return "", false, errorx.NewInternalErr()
+		}
+	}
+
+	username, sErr := s.uniqueUsername(ctx, idToken)
+	if sErr != nil {
+		return "", false, sErr
+	}
+
+	// nobody knows this password, the user logs in through the provider or resets it by email
+	password, err := util.RandomToken(32)
+	if err != nil {
+		zlog.Error("Error while generating password", zap.Error(err))
+		return "", false, errorx.NewInternalErr()
+	}
+	hashPwd, err := util.EncryptPassword(password)
+	if err != nil {
+		zlog.Error("Error while encrypt password", zap.Error(err))
+		return "", false, errorx.NewInternalErr()
+	}
+
+	newUser := &model.User{
+		UserID:         uuid.NewString(),
+		MembershipTime: time.Now().Unix(),
+		Gender:         2,
+		Username:       username,
+		Password:       hashPwd,
+		Email:          userEmail,
+		EmailVerified:  userEmail != nil && idToken.EmailVerified,
+	}
+	if err := dao.CreateUserWithIdentity(ctx, newUser, newIdentity); err != nil {
+		zlog.Error("Error while creating user from identity", zap.String("provider", providerName), zap.Error(err))
+		return "", false, errorx.NewInternalErr()
+	}
+
+	if newUser.Email != nil && !newUser.EmailVerified {
+		if sErr := user.Service().SendVerification(ctx, newUser.UserID); sErr != nil {
+			zlog.Warn("Verification mail not sent on sign up", zap.String("userID", newUser.UserID))
+		}
+	}
+
+	return newUser.UserID, true, nil
+}
+
+func (s *SSOService) link(ctx context.Context, userID string, providerName string, idToken *oidc.IDToken) *errorx.ServiceErr {
+	identity, err := dao.FindIdentity(ctx, providerName, idToken.Subject)
+	if err == nil {
+		if identity.UserID == userID {
+			return nil
+		}
+		return errorx.NewServicerErr(errorx.ErrExternal, "This account is already linked to another user", nil)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		zlog.Error("Error while finding identity", zap.String("provider", providerName), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	identities, err := dao.GetIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		zlog.Error("Error while getting identities", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	for _, i := range identities {
+		if i.Provider == providerName {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Another account of this provider is already linked", nil)
+		}
+	}
+
+	var email *string
+	if normalized := user.NormalizeEmail(idToken.Email); normalized != "" {
+		email = &normalized
+	}
+
+	err = dao.CreateIdentity(ctx, &model.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  idToken.Subject,
+		Email:    email,
+	})
+	if err != nil {
+		zlog.Error("Error while linking identity", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// consumeState makes the state single use, whoever deletes it may continue the login
+func (s *SSOService) consumeState(ctx context.Context, state string) (map[string]string, *errorx.ServiceErr) {
+	key := fmt.Sprintf(stateKeyFmt, util.HashToken(state))
+
+	values, err := redis.RDB().HGetAll(ctx, key).Result()
+	if err != nil {
+		zlog.Error("Error while getting oidc state", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if len(values) == 0 {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid or expired login state", nil)
+	}
+
+	deleted, err := redis.RDB().Del(ctx, key).Result()
+	if err != nil {
+		zlog.Error("Error while deleting oidc state", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if deleted == 0 {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid or expired login state", nil)
+	}
+
+	return values, nil
+}
+
+// provider discovers a configured provider on first use, failures are retried on the next login
+func (s *SSOService) provider(ctx context.Context, name string) (*oidc.Provider, *errorx.ServiceErr) {
+	clientID := config.Get(providerKey(name, "clientId"))
+	if util.IsEmpty(name) || util.IsEmpty(clientID) {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Unknown identity provider", nil)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if provider, ok := s.providers[name]; ok {
+		return provider, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       config.Get(providerKey(name, "issuer")),
+		ClientID:     clientID,
+		ClientSecret: config.Get(providerKey(name, "clientSecret")),
+		RedirectURL:  config.Get(providerKey(name, "redirectUrl")),
+		Scopes:       strings.Fields(config.Get(providerKey(name, "scopes"))),
+		HTTPClient:   &http.Client{Timeout: providerTimeout},
+	})
+	if err != nil {
+		zlog.Error("Error while discovering identity provider", zap.String("provider", name), zap.Error(err))
+		return nil, errorx.NewServicerErr(502, "Identity provider unavailable", nil)
+	}
+
+	if s.providers == nil {
+		s.providers = make(map[string]*oidc.Provider)
+	}
+	s.providers[name] = provider
+
+	return provider, nil
+}
+
+// uniqueUsername derives a username from the claims, a random suffix is added if it is taken
+func (s *SSOService) uniqueUsername(ctx context.Context, idToken *oidc.IDToken) (string, *errorx.ServiceErr) {
+	base := ""
+	localPart, _, _ := strings.Cut(idToken.Email, "@")
+	for _, candidate := range []string{idToken.PreferredUsername, localPart, idToken.Name} {
+		if base = sanitizeUsername(candidate); base != "" {
+			break
+		}
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		_, err := dao.FindUserByUsername(ctx, username)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return username, nil
+		}
+		if err != nil {
+			zlog.Error("Error while finding user by username", zap.String("username", username), zap.Error(err))
+			return "", errorx.NewInternalErr()
+		}
+
+		username = fmt.Sprintf("%s_%s", base, uuid.NewString()[:4])
+	}
+
+	return fmt.Sprintf("%s_%s", base, uuid.NewString()[:8]), nil
+}
+
+func sanitizeUsername(s string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)), r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		}
+		if b.Len() >= maxUsernameLength {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+func providerKey(name string, field string) string {
+	return "oidc.providers." + name + "." + field
+}
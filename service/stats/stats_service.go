@@ -0,0 +1,257 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/achievement"
+	"api.backend.xjco2913/service/privacy"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Every uploaded route is measured once and kept as a ride, the totals of a user are
+// refreshed from the rides. Routes uploaded before the statistics existed are measured
+// the first time the statistics of the user are needed.
+const (
+	// length of the weekly and monthly series, the current week and month included
+	seriesLength = 12
+)
+
+type StatsService struct{}
+
+var (
+	statsService StatsService
+)
+
+func Service() *StatsService {
+	return &statsService
+}
+
+// RecordRide keeps the measurements of a route uploaded by the user, source is
+// dao.RIDE_MOMENT or dao.RIDE_ACTIVITY and refID the moment or activity it belongs to
+func (s *StatsService) RecordRide(ctx context.Context, userID string, source string, refID string, route *sdto.ParseGPXDataOutput) *errorx.ServiceErr {
+	// the earlier routes of the user have to be recorded first, they would be missed otherwise
+	if _, err := s.userStats(ctx, userID); err != nil {
+		zlog.Error("Failed to get user stats", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	rodeAt := route.StartTime
+	if rodeAt.IsZero() {
+		rodeAt = time.Now()
+	}
+
+	ride := &model.Ride{
+		UserID:        userID,
+		Source:        source,
+		RefID:         refID,
+		RouteID:       route.RouteID,
+		Distance:      route.Distance,
+		ElevationGain: route.ElevationGain,
+		RodeAt:        rodeAt,
+	}
+	if route.Best10K > 0 {
+		seconds := int32(route.Best10K.Seconds())
+		ride.Best10k = &seconds
+	}
+
+	if err := dao.SaveRide(ctx, ride); err != nil {
+		zlog.Error("Failed to save ride", zap.String("userID", userID), zap.String("source", source), zap.String("refID", refID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	achievement.Service().Evaluate(ctx, ride)
+
+	return nil
+}
+
+// Get returns the lifetime totals and the weekly and monthly series of the user,
+// to anyone who may see the profile
+func (s *StatsService) Get(ctx context.Context, in *sdto.StatsInput) (*sdto.StatsOutput, *errorx.ServiceErr) {
+	owner, sErr := privacy.Service().CanViewByID(ctx, in.Viewer, in.UserID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	name := in.Timezone
+	if util.IsEmpty(name) {
+		name = config.Get("report.timezone")
+	}
+	loc, err := util.LoadLocation(name)
+	if err != nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Unknown timezone: "+name, nil)
+	}
+
+	totals, err := s.userStats(ctx, in.UserID)
+	if err != nil {
+		zlog.Error("Failed to get user stats", zap.String("userID", in.UserID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	joined, err1 := dao.CountActivityUsersByUserID(ctx, in.UserID)
+	organised, err2 := dao.CountActivitiesByCreatorID(ctx, in.UserID)
+	if err := errors.Join(err1, err2); err != nil {
+		zlog.Error("Failed to count activities", zap.String("userID", in.UserID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	weekly, err := s.series(ctx, in.UserID, util.INTERVAL_WEEK, loc)
+	if err != nil {
+		zlog.Error("Failed to get weekly ride series", zap.String("userID", in.UserID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	monthly, err := s.series(ctx, in.UserID, util.INTERVAL_MONTH, loc)
+	if err != nil {
+		zlog.Error("Failed to get monthly ride series", zap.String("userID", in.UserID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return &sdto.StatsOutput{
+		UserID:              in.UserID,
+		TotalDistance:       totals.Distance,
+		TotalElevationGain:  totals.ElevationGain,
+		RideCount:           totals.RideCount,
+		LongestRide:         totals.LongestRide,
+		ActivitiesJoined:    joined,
+		ActivitiesOrganised: organised,
+		Followers:           int64(owner.FollowerCount),
+		Following:           int64(owner.FollowingCount),
+		Timezone:            loc.String(),
+		Weekly:              weekly,
+		Monthly:             monthly,
+	}, nil
+}
+
+// userStats returns the totals of the user, recording the rides of earlier routes
+// if the statistics of the user were never built
+func (s *StatsService) userStats(ctx context.Context, userID string) (*model.UserStat, error) {
+	totals, err := dao.GetUserStats(ctx, userID)
+	if err == nil {
+		return totals, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	rides, err := earlierRides(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := dao.BackfillRides(ctx, userID, rides); err != nil {
+		return nil, err
+	}
+
+	zlog.Info("User stats built", zap.String("userID", userID), zap.Int("rides", len(rides)))
+
+	return dao.GetUserStats(ctx, userID)
+}
+
+// earlierRides measures the stored routes of the moments and activities of the user,
+// the stored routes have no elevation
+func earlierRides(ctx context.Context, userID string) ([]*model.Ride, error) {
+	rides := []*model.Ride{}
+
+	measure := func(source string, refID string, routeID *int32, rodeAt *time.Time) error {
+		if routeID == nil {
+			return nil
+		}
+
+		path, err := dao.GetPathAsText(ctx, *routeID)
+		if err != nil {
+			return err
+		}
+		route, err := util.GPXRoute(path)
+		if err != nil {
+			return err
+		}
+
+		ride := &model.Ride{
+			UserID:   userID,
+			Source:   source,
+			RefID:    refID,
+			RouteID:  *routeID,
+			Distance: util.RouteLength(util.GPXStrTo2DString(route)),
+			RodeAt:   time.Now(),
+		}
+		if rodeAt != nil {
+			ride.RodeAt = *rodeAt
+		}
+		rides = append(rides, ride)
+
+		return nil
+	}
+
+	moments, err := dao.GetMomentsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, moment := range moments {
+		if err := measure(dao.RIDE_MOMENT, moment.MomentID, moment.RouteID, moment.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	activityUsers, err := dao.GetActivityUsersByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, activityUser := range activityUsers {
+		// updated when the route was uploaded
+		if err := measure(dao.RIDE_ACTIVITY, activityUser.ActivityID, activityUser.RouteID, activityUser.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return rides, nil
+}
+
+// series sums the rides of the last seriesLength weeks or months
+func (s *StatsService) series(ctx context.Context, userID string, interval string, loc *time.Location) ([]*sdto.StatsBucket, error) {
+	current, err := util.StartOfInterval(time.Now(), interval, loc)
+	if err != nil {
+		return nil, err
+	}
+	end, err := util.NextInterval(current, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	start := current.AddDate(0, 0, -7*(seriesLength-1))
+	if interval == util.INTERVAL_MONTH {
+		start = current.AddDate(0, -(seriesLength - 1), 0)
+	}
+
+	buckets, err := util.CalendarBuckets(start, end, interval, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := dao.SumRidesByBuckets(ctx, userID, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*sdto.StatsBucket, len(buckets))
+	for i, bucket := range buckets {
+		res[i] = &sdto.StatsBucket{
+			Start: bucket.Start,
+			End:   bucket.End,
+		}
+	}
+	for _, row := range rows {
+		res[row.BucketIdx].Distance = row.Distance
+		res[row.BucketIdx].ElevationGain = row.ElevationGain
+		res[row.BucketIdx].Rides = row.Rides
+	}
+
+	return res, nil
+}
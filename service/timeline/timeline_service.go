@@ -0,0 +1,197 @@
+package timeline
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+)
+
+// The home timeline of a user mixes the moments of the user with the ones of the users the user
+// follows, friends included. New moments are pushed to the timelines of the followers of their
+// author in Redis (fan-out on write). Authors with many followers are not pushed, their moments
+// are read from the database when a timeline is shown (fan-out on read). Timelines are built on
+// the first read and dropped whenever the users a user follows, blocks or mutes change.
+const (
+	defaultCelebrityFollowers = 5000
+	defaultTimelineLength     = 500
+)
+
+type TimelineService struct{}
+
+var (
+	timelineService TimelineService
+)
+
+func Service() *TimelineService {
+	return &timelineService
+}
+
+// Publish pushes a new moment to the timelines of its author and the followers of the author.
+// Failures are logged, the moment is found again when the timelines are built the next time.
+func (t *TimelineService) Publish(ctx context.Context, momentID string) {
+	// the creation time set by the database is the one the feed pages by
+	moment, err := dao.GetMomentByID(ctx, momentID)
+	if err != nil {
+		zlog.Error("Failed to get moment to publish", zap.String("momentID", momentID), zap.Error(err))
+		return
+	}
+	author, err := dao.GetUserByID(ctx, moment.AuthorID)
+	if err != nil {
+		zlog.Error("Failed to get moment author", zap.String("userID", moment.AuthorID), zap.Error(err))
+		return
+	}
+
+	celebrityFollowers, length := settings()
+	receivers := []string{author.UserID}
+	if author.FollowerCount < celebrityFollowers {
+		followers, err := dao.GetFollowerIDs(ctx, author.UserID)
+		if err != nil {
+			zlog.Error("Failed to get followers", zap.String("userID", author.UserID), zap.Error(err))
+			return
+		}
+		receivers = append(receivers, followers...)
+	}
+
+	if err := redis.PushToTimelines(ctx, receivers, moment.MomentID, *moment.CreatedAt, length); err != nil {
+		zlog.Error("Failed to push moment to timelines", zap.String("momentID", momentID), zap.Int("receivers", len(receivers)), zap.Error(err))
+	}
+}
+
+// Reset drops the timelines of the users after whom they follow, block or mute changed
+func (t *TimelineService) Reset(ctx context.Context, userIDs ...string) {
+	if err := redis.DropTimelines(ctx, userIDs...); err != nil {
+		zlog.Error("Failed to drop timelines", zap.Strings("userIDs", userIDs), zap.Error(err))
+	}
+}
+
+// Page returns up to limit moments of the timeline of the user created before the given time, newest first
+func (t *TimelineService) Page(ctx context.Context, userID string, before time.Time, limit int) ([]*model.Moment, *errorx.ServiceErr) {
+	celebrityFollowers, length := settings()
+
+	exists, err := redis.TimelineExists(ctx, userID)
+	if err != nil {
+		zlog.Error("Failed to check timeline", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if !exists {
+		if err := build(ctx, userID, celebrityFollowers, length); err != nil {
+			zlog.Error("Failed to build timeline", zap.String("userID", userID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+	}
+
+	ids, count, err := redis.ReadTimeline(ctx, userID, before, limit)
+	if err != nil {
+		zlog.Error("Failed to read timeline", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	// paging past the end of a full timeline, the older moments are only in the database
+	if len(ids) < limit && count >= int64(length) {
+		moments, err := dao.GetTimelineMoments(ctx, userID, 0, before, limit)
+		if err != nil {
+			zlog.Error("Failed to get older timeline moments", zap.String("userID", userID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+		return moments, nil
+	}
+
+	pushed, err := dao.GetMomentsByMomentIDs(ctx, ids)
+	if err != nil {
+		zlog.Error("Failed to get timeline moments", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	popular, err := dao.GetPopularMoments(ctx, userID, celebrityFollowers, before, limit)
+	if err != nil {
+		zlog.Error("Failed to get moments of popular users", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return merge(limit, pushed, popular), nil
+}
+
+// build fills the timeline of the user from the database, leaving out the popular users. Moments
+// created while it is built are not pushed to the timeline, it does not exist yet, so they are
+// added once it is built.
+func build(ctx context.Context, userID string, celebrityFollowers int32, length int) error {
+	// the database sets the creation time, allow for a clock skew
+	start := time.Now().Add(-time.Minute)
+
+	moments, err := dao.GetTimelineMoments(ctx, userID, celebrityFollowers, time.Now().Add(time.Minute), length)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(moments))
+	createdAt := make([]time.Time, len(moments))
+	for i, moment := range moments {
+		ids[i] = moment.MomentID
+		createdAt[i] = *moment.CreatedAt
+	}
+
+	if err := redis.BuildTimeline(ctx, userID, ids, createdAt); err != nil {
+		return err
+	}
+
+	// pushing a moment that is in the timeline already changes nothing
+	missed, err := dao.GetTimelineMomentsSince(ctx, userID, celebrityFollowers, start, length)
+	if err != nil {
+		return err
+	}
+	for _, moment := range missed {
+		if err := redis.PushToTimelines(ctx, []string{userID}, moment.MomentID, *moment.CreatedAt, length); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// merge returns the newest limit moments of both lists, a moment pushed before its author became
+// popular is in both
+func merge(limit int, lists ...[]*model.Moment) []*model.Moment {
+	seen := make(map[string]bool)
+	res := []*model.Moment{}
+	for _, list := range lists {
+		for _, moment := range list {
+			if seen[moment.MomentID] {
+				continue
+			}
+			seen[moment.MomentID] = true
+			res = append(res, moment)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(*res[j].CreatedAt)
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res
+}
+
+// settings returns from how many followers moments are read instead of pushed and how many
+// moments a timeline keeps
+func settings() (int32, int) {
+	celebrityFollowers, err := strconv.Atoi(config.Get("feed.celebrityFollowers"))
+	if err != nil || celebrityFollowers <= 0 {
+		celebrityFollowers = defaultCelebrityFollowers
+	}
+	length, err := strconv.Atoi(config.Get("feed.timelineLength"))
+	if err != nil || length <= 0 {
+		length = defaultTimelineLength
+	}
+
+	return int32(celebrityFollowers), length
+}
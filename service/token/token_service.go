@@ -0,0 +1,438 @@
+package token
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/zlog"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	ACCESS_TOKEN_DURATION  = 15 * time.Minute
+	REFRESH_TOKEN_DURATION = 30 * 24 * time.Hour
+
+	// how stale the last seen time of a session may get
+	lastSeenResolution = time.Minute
+)
+
+// Redis key formats
+//
+//	session:<sessionID>  => hash of the session, lives as long as its refresh token
+//	sessions:<userID>    => set of the session ids of a user (or an admin)
+//	revoked:<tokenID>    => access tokens revoked before they expire
+//	tokenVer:<userID>    => bumped whenever the claims of a user change
+const (
+	sessionKeyFmt  = "session:%s"
+	sessionsKeyFmt = "sessions:%s"
+	revokedKeyFmt  = "revoked:%s"
+	tokenVerKeyFmt = "tokenVer:%s"
+)
+
+type TokenService struct{}
+
+var (
+	tokenService TokenService
+)
+
+func Service() *TokenService {
+	return &tokenService
+}
+
+// Issue starts a new session for the subject and returns its first token pair
+func (t *TokenService) Issue(ctx context.Context, subjectID string, isAdmin bool, device *sdto.SessionDevice) (*sdto.TokenPair, *errorx.ServiceErr) {
+	sessionID := uuid.NewString()
+	refreshToken, refreshHash, err := newRefreshToken(sessionID)
+	if err != nil {
+		zlog.Error("Error while generating refresh token", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	sessionKey := fmt.Sprintf(sessionKeyFmt, sessionID)
+	sessionsKey := fmt.Sprintf(sessionsKeyFmt, subjectID)
+	now := time.Now()
+
+	pipe := redis.RDB().TxPipeline()
+	pipe.HSet(ctx, sessionKey,
+		"userID", subjectID,
+		"isAdmin", strconv.FormatBool(isAdmin),
+		"refreshHash", refreshHash,
+		"deviceName", device.DeviceName,
+		"userAgent", device.UserAgent,
+		"ip", device.IP,
+		"createdAt", now.Unix(),
+		"lastSeen", now.Unix(),
+	)
+	pipe.Expire(ctx, sessionKey, REFRESH_TOKEN_DURATION)
+	pipe.SAdd(ctx, sessionsKey, sessionID)
+	pipe.Expire(ctx, sessionsKey, REFRESH_TOKEN_DURATION)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while storing session", zap.String("userID", subjectID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	accessToken, expiresAt, sErr := t.signAccessToken(ctx, subjectID, isAdmin, sessionID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return &sdto.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		SessionID:    sessionID,
+	}, nil
+}
+
+// Refresh rotates the refresh token and signs a new access token with up to date claims.
+// Presenting a refresh token that has already been rotated means it leaked, the whole session is revoked.
+// isAdmin tells which login issued the session, admin and user sessions cannot be refreshed through each other.
+func (t *TokenService) Refresh(ctx context.Context, refreshToken string, isAdmin bool, device *sdto.SessionDevice) (*sdto.TokenPair, *errorx.ServiceErr) {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || util.IsEmpty(sessionID) {
+		return nil, errorx.NewServicerErr(401, "Invalid refresh token", nil)
+	}
+
+	sessionKey := fmt.Sprintf(sessionKeyFmt, sessionID)
+	session, err := redis.RDB().HGetAll(ctx, sessionKey).Result()
+	if err != nil {
+		zlog.Error("Error while getting session", zap.String("sessionID", sessionID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if len(session) == 0 {
+		return nil, errorx.NewServicerErr(401, "Session expired or revoked", nil)
+	}
+
+	subjectID := session["userID"]
+	if (session["isAdmin"] == "true") != isAdmin {
+		return nil, errorx.NewServicerErr(401, "Invalid refresh token", nil)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(util.HashToken(refreshToken)), []byte(session["refreshHash"])) != 1 {
+		zlog.Warn("Refresh token reused, revoking session", zap.String("userID", subjectID), zap.String("sessionID", sessionID))
+		if sErr := t.RevokeSession(ctx, subjectID, sessionID); sErr != nil {
+			return nil, sErr
+		}
+		return nil, errorx.NewServicerErr(401, "Refresh token has already been used", nil)
+	}
+
+	newRefreshToken, refreshHash, err := newRefreshToken(sessionID)
+	if err != nil {
+		zlog.Error("Error while generating refresh token", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	pipe := redis.RDB().TxPipeline()
+	pipe.HSet(ctx, sessionKey,
+		"refreshHash", refreshHash,
+		"userAgent", device.UserAgent,
+		"ip", device.IP,
+		"lastSeen", time.Now().Unix(),
+	)
+	pipe.Expire(ctx, sessionKey, REFRESH_TOKEN_DURATION)
+	pipe.Expire(ctx, fmt.Sprintf(sessionsKeyFmt, subjectID), REFRESH_TOKEN_DURATION)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while rotating refresh token", zap.String("sessionID", sessionID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	accessToken, expiresAt, sErr := t.signAccessToken(ctx, subjectID, isAdmin, sessionID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return &sdto.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresAt:    expiresAt,
+		SessionID:    sessionID,
+	}, nil
+}
+
+// Validate is used by middleware.VerifyToken, it rejects access tokens whose session
+// has been revoked, which have been revoked themselves, or whose claims are outdated
+func (t *TokenService) Validate(ctx context.Context, claims jwt.MapClaims) *errorx.ServiceErr {
+	userID, _ := claims["userID"].(string)
+	sessionID, _ := claims["sid"].(string)
+	tokenID, _ := claims["jti"].(string)
+	version, _ := claims["ver"].(float64)
+	if util.IsEmpty(userID) || util.IsEmpty(sessionID) || util.IsEmpty(tokenID) {
+		return errorx.NewServicerErr(401, "Token is no longer supported, please login again", nil)
+	}
+
+	sessionKey := fmt.Sprintf(sessionKeyFmt, sessionID)
+
+	pipe := redis.RDB().Pipeline()
+	lastSeen := pipe.HGet(ctx, sessionKey, "lastSeen")
+	tokenRevoked := pipe.Exists(ctx, fmt.Sprintf(revokedKeyFmt, tokenID))
+	currentVer := pipe.Get(ctx, fmt.Sprintf(tokenVerKeyFmt, userID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.KEY_NOT_FOUND {
+		zlog.Error("Error while validating token", zap.String("sessionID", sessionID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	// lastSeen is written when the session is issued, so a missing field means the session is gone
+	if lastSeen.Err() == redis.KEY_NOT_FOUND || tokenRevoked.Val() > 0 {
+		return errorx.NewServicerErr(401, "Token has been revoked", nil)
+	}
+
+	if ver, err := currentVer.Int64(); err == nil && ver > int64(version) {
+		return errorx.NewServicerErr(401, "Token is outdated, please refresh", nil)
+	}
+
+	// Throttle last seen updates to keep the hot path mostly read only
+	now := time.Now().Unix()
+	if seen, err := lastSeen.Int64(); err == nil && now-seen >= int64(lastSeenResolution.Seconds()) {
+		redis.RDB().HSet(ctx, sessionKey, "lastSeen", now)
+	}
+
+	return nil
+}
+
+// ListSessions returns the live sessions of the user, most recently used first.
+// currentSessionID marks the session the request was made with.
+func (t *TokenService) ListSessions(ctx context.Context, userID string, currentSessionID string) ([]*sdto.Session, *errorx.ServiceErr) {
+	sessionsKey := fmt.Sprintf(sessionsKeyFmt, userID)
+	sessionIDs, err := redis.RDB().SMembers(ctx, sessionsKey).Result()
+	if err != nil {
+		zlog.Error("Error while listing sessions", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	pipe := redis.RDB().Pipeline()
+	cmds := make([]*goredis.StringStringMapCmd, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		cmds[i] = pipe.HGetAll(ctx, fmt.Sprintf(sessionKeyFmt, sessionID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while getting sessions", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	sessions := make([]*sdto.Session, 0, len(sessionIDs))
+	var expired []interface{}
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+
+		createdAt, _ := strconv.ParseInt(fields["createdAt"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(fields["lastSeen"], 10, 64)
+		sessions = append(sessions, &sdto.Session{
+			SessionID:  sessionIDs[i],
+			DeviceName: fields["deviceName"],
+			UserAgent:  fields["userAgent"],
+			IP:         fields["ip"],
+			CreatedAt:  createdAt,
+			LastSeen:   lastSeen,
+			Current:    sessionIDs[i] == currentSessionID,
+		})
+	}
+
+	// Sessions expire on their own, drop their dangling ids from the set
+	if len(expired) > 0 {
+		redis.RDB().SRem(ctx, sessionsKey, expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen > sessions[j].LastSeen
+	})
+
+	return sessions, nil
+}
+
+// Logout revokes the session and the access token used for the request
+func (t *TokenService) Logout(ctx context.Context, userID string, sessionID string, tokenID string, tokenExp time.Time) *errorx.ServiceErr {
+	if sErr := t.RevokeSession(ctx, userID, sessionID); sErr != nil {
+		return sErr
+	}
+
+	if ttl := time.Until(tokenExp); ttl > 0 {
+		err := redis.RDB().Set(ctx, fmt.Sprintf(revokedKeyFmt, tokenID), userID, ttl).Err()
+		if err != nil {
+			zlog.Error("Error while revoking access token", zap.String("tokenID", tokenID), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+	}
+
+	return nil
+}
+
+// RevokeSession deletes one session of the user, access tokens issued for it stop working immediately
+func (t *TokenService) RevokeSession(ctx context.Context, userID string, sessionID string) *errorx.ServiceErr {
+	owner, err := redis.RDB().HGet(ctx, fmt.Sprintf(sessionKeyFmt, sessionID), "userID").Result()
+	if err != nil && err != redis.KEY_NOT_FOUND {
+		zlog.Error("Error while getting session", zap.String("sessionID", sessionID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	if err == redis.KEY_NOT_FOUND || owner != userID {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Session not found", nil)
+	}
+
+	pipe := redis.RDB().TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf(sessionKeyFmt, sessionID))
+	pipe.SRem(ctx, fmt.Sprintf(sessionsKeyFmt, userID), sessionID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while revoking session", zap.String("userID", userID), zap.String("sessionID", sessionID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// RevokeOthers deletes every session of the user except keepSessionID
+func (t *TokenService) RevokeOthers(ctx context.Context, userID string, keepSessionID string) *errorx.ServiceErr {
+	sessionsKey := fmt.Sprintf(sessionsKeyFmt, userID)
+	sessionIDs, err := redis.RDB().SMembers(ctx, sessionsKey).Result()
+	if err != nil {
+		zlog.Error("Error while listing sessions", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	pipe := redis.RDB().TxPipeline()
+	for _, sessionID := range sessionIDs {
+		if sessionID == keepSessionID {
+			continue
+		}
+		pipe.Del(ctx, fmt.Sprintf(sessionKeyFmt, sessionID))
+		pipe.SRem(ctx, sessionsKey, sessionID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while revoking sessions", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// RevokeAll deletes every session of the user, i.e. logs out everywhere
+func (t *TokenService) RevokeAll(ctx context.Context, userID string) *errorx.ServiceErr {
+	sessionsKey := fmt.Sprintf(sessionsKeyFmt, userID)
+	sessionIDs, err := redis.RDB().SMembers(ctx, sessionsKey).Result()
+	if err != nil {
+		zlog.Error("Error while listing sessions", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	keys := make([]string, 0, len(sessionIDs)+1)
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, fmt.Sprintf(sessionKeyFmt, sessionID))
+	}
+	keys = append(keys, sessionsKey)
+
+	if err := redis.RDB().Del(ctx, keys...).Err(); err != nil {
+		zlog.Error("Error while revoking sessions", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// Outdate makes the access tokens of the user fail with "outdated" so that clients
+// refresh them and pick up changed claims such as organiser status or membership
+func (t *TokenService) Outdate(ctx context.Context, userID string) *errorx.ServiceErr {
+	key := fmt.Sprintf(tokenVerKeyFmt, userID)
+
+	pipe := redis.RDB().TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, REFRESH_TOKEN_DURATION)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while outdating tokens", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+func (t *TokenService) signAccessToken(ctx context.Context, subjectID string, isAdmin bool, sessionID string) (string, int64, *errorx.ServiceErr) {
+	claims, sErr := t.loadClaims(ctx, subjectID, isAdmin)
+	if sErr != nil {
+		return "", 0, sErr
+	}
+
+	version, err := redis.RDB().Get(ctx, fmt.Sprintf(tokenVerKeyFmt, subjectID)).Int64()
+	if err != nil && err != redis.KEY_NOT_FOUND {
+		zlog.Error("Error while getting token version", zap.String("userID", subjectID), zap.Error(err))
+		return "", 0, errorx.NewInternalErr()
+	}
+
+	expiresAt := time.Now().Add(ACCESS_TOKEN_DURATION).Unix()
+	claims["sid"] = sessionID
+	claims["jti"] = uuid.NewString()
+	claims["ver"] = version
+	claims["exp"] = expiresAt
+
+	tokenStr, err := util.GenerateJWTToken(claims)
+	if err != nil {
+		zlog.Error("Error while generating jwt", zap.Error(err))
+		return "", 0, errorx.NewInternalErr()
+	}
+
+	return tokenStr, expiresAt, nil
+}
+
+// loadClaims reads the claims from the database so that refreshed tokens reflect the current state
+func (t *TokenService) loadClaims(ctx context.Context, subjectID string, isAdmin bool) (jwt.MapClaims, *errorx.ServiceErr) {
+	if isAdmin {
+		// admin tokens carry the admin id in both userID and adminID, so handlers reading
+		// userID keep working while admin specific code can tell the two apart
+		return jwt.MapClaims{
+			"userID":         subjectID,
+			"adminID":        subjectID,
+			"isAdmin":        true,
+			"isOrganiser":    false,
+			"membershipType": 0,
+		}, nil
+	}
+
+	user, err := dao.GetUserByID(ctx, subjectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.NewServicerErr(401, "User not found", nil)
+		}
+
+		zlog.Error("Error while finding user by userID", zap.String("userID", subjectID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	organiser, err := dao.GetOrganiserByID(ctx, user.UserID)
+	isOrganiser := false
+	if err == nil && organiser != nil && organiser.Status == 2 {
+		isOrganiser = true
+	}
+
+	return jwt.MapClaims{
+		"userID":         user.UserID,
+		"isAdmin":        false,
+		"isOrganiser":    isOrganiser,
+		"membershipType": user.MembershipType,
+	}, nil
+}
+
+// newRefreshToken returns an opaque token prefixed with its session id, together with the hash stored server side
+func newRefreshToken(sessionID string) (string, string, error) {
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := sessionID + "." + secret
+	return token, util.HashToken(token), nil
+}
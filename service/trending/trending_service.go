@@ -0,0 +1,121 @@
+package trending
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+)
+
+// Likes, comments, shares and signups make moments and activities trend, a signal counts
+// half as much after every half-life and every user gives each signal to an item once.
+// Items trend everywhere and in the region of their author or creator.
+const (
+	KIND_MOMENT   = "moment"
+	KIND_ACTIVITY = "activity"
+)
+
+type Signal string
+
+const (
+	SIGNAL_LIKE    Signal = "like"
+	SIGNAL_COMMENT Signal = "comment"
+	SIGNAL_SHARE   Signal = "share"
+	SIGNAL_SIGNUP  Signal = "signup"
+)
+
+// how much a signal counts
+var weights = map[Signal]float64{
+	SIGNAL_LIKE:    1,
+	SIGNAL_COMMENT: 2,
+	SIGNAL_SHARE:   3,
+	SIGNAL_SIGNUP:  3,
+}
+
+const (
+	// items kept per ranking
+	trendingLength       = 1000
+	defaultHalfLifeHours = 24
+	// a user counts once per signal and item for this many half-lives, by then the first
+	// signal hardly counts anymore
+	signalHalfLives = 10
+)
+
+type TrendingService struct{}
+
+var (
+	trendingService TrendingService
+)
+
+func Service() *TrendingService {
+	return &trendingService
+}
+
+// Moment counts a signal the user gave to the moment, failures are logged
+func (t *TrendingService) Moment(ctx context.Context, userID string, moment *model.Moment, signal Signal) {
+	t.bump(ctx, userID, KIND_MOMENT, moment.MomentID, moment.AuthorID, signal)
+}
+
+// Activity counts a signal the user gave to the activity, failures are logged
+func (t *TrendingService) Activity(ctx context.Context, userID string, activity *model.Activity, signal Signal) {
+	t.bump(ctx, userID, KIND_ACTIVITY, activity.ActivityID, activity.CreatorID, signal)
+}
+
+// Top returns the IDs of up to limit trending items of the kind, best first.
+// Region is empty for the ranking of everywhere.
+func (t *TrendingService) Top(ctx context.Context, kind, region string, limit int) ([]string, *errorx.ServiceErr) {
+	ids, err := redis.TopTrending(ctx, kind, region, limit)
+	if err != nil {
+		zlog.Error("Failed to get trending items", zap.String("kind", kind), zap.String("region", region), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return ids, nil
+}
+
+// bump counts the signal once per user, signals without a user are not counted
+func (t *TrendingService) bump(ctx context.Context, userID, kind, id, ownerID string, signal Signal) {
+	if userID == "" {
+		return
+	}
+
+	halfLife := halfLife()
+	first, err := redis.MarkTrendingSignal(ctx, kind, id, string(signal), userID, signalHalfLives*halfLife)
+	if err != nil {
+		zlog.Error("Failed to mark trending signal", zap.String("kind", kind), zap.String("id", id), zap.Error(err))
+		return
+	}
+	if !first {
+		return
+	}
+
+	owner, err := dao.GetUserByID(ctx, ownerID)
+	if err != nil {
+		zlog.Error("Failed to get owner of trending item", zap.String("kind", kind), zap.String("id", id), zap.Error(err))
+		return
+	}
+
+	// 2^(now / halfLife) grows by one every half-life, in log2 space
+	logWeight := math.Log2(weights[signal]) + float64(time.Now().Unix())/halfLife.Seconds()
+
+	if err := redis.BumpTrending(ctx, kind, owner.Region, id, logWeight, trendingLength); err != nil {
+		zlog.Error("Failed to bump trending item", zap.String("kind", kind), zap.String("id", id), zap.Error(err))
+	}
+}
+
+func halfLife() time.Duration {
+	hours, err := strconv.ParseFloat(config.Get("trending.halfLifeHours"), 64)
+	if err != nil || hours <= 0 {
+		hours = defaultHalfLifeHours
+	}
+
+	return time.Duration(hours * float64(time.Hour))
+}
@@ -0,0 +1,558 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	OWNER_USER  = "user"
+	OWNER_ADMIN = "admin"
+
+	CHALLENGE_DURATION = 5 * time.Minute
+
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+
+	// codes of the previous and the next period are accepted as well
+	totpSkew = 1
+	// a code must not be accepted twice while it is within the skew window
+	usedCodeDuration = (2*totpSkew + 1) * util.TOTP_PERIOD * time.Second
+
+	defaultIssuer = "XJCO2913"
+)
+
+// Redis key formats
+//
+//	2faChallenge:<tokenHash>  => hash of a pending login that still needs its second factor
+//	2faUsed:<ownerID>:<step>  => set once the code of a time step has been used
+//	setting:admin2faEnforced  => cache of the setting in the database, "1" or "0"
+const (
+	challengeKeyFmt  = "2faChallenge:%s"
+	usedCodeKeyFmt   = "2faUsed:%s:%d"
+	adminEnforcedKey = "setting:admin2faEnforced"
+
+	adminEnforcedCacheDuration = time.Minute
+)
+
+type TwoFactorService struct{}
+
+var (
+	twoFactorService TwoFactorService
+)
+
+func Service() *TwoFactorService {
+	return &twoFactorService
+}
+
+// Setup generates a new secret for the owner, it only takes effect once confirmed through Enable
+func (s *TwoFactorService) Setup(ctx context.Context, ownerID string, isAdmin bool) (*sdto.TwoFactorSetupOutput, *errorx.ServiceErr) {
+	twoFactor, sErr := s.get(ctx, ownerID)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Two-factor authentication is already enabled", nil)
+	}
+
+	account, sErr := accountName(ctx, ownerID, isAdmin)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		zlog.Error("Error while generating totp secret", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	ownerType := OWNER_USER
+	if isAdmin {
+		ownerType = OWNER_ADMIN
+	}
+
+	err = dao.SaveTwoFactor(ctx, &model.TwoFactor{
+		OwnerID:   ownerID,
+		OwnerType: ownerType,
+		Secret:    secret,
+		Enabled:   false,
+	})
+	if err != nil {
+		zlog.Error("Error while saving two factor", zap.String("ownerID", ownerID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return &sdto.TwoFactorSetupOutput{
+		Secret:          secret,
+		ProvisioningURI: util.TOTPProvisioningURI(issuer(), account, secret),
+	}, nil
+}
+
+// Enable confirms the pending secret with a code from the authenticator app.
+// The recovery codes are returned in plain text only this once.
+func (s *TwoFactorService) Enable(ctx context.Context, ownerID string, code string) ([]string, *errorx.ServiceErr) {
+	twoFactor, sErr := s.get(ctx, ownerID)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if twoFactor == nil {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Two-factor authentication has not been set up", nil)
+	}
+	if twoFactor.Enabled {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Two-factor authentication is already enabled", nil)
+	}
+
+	ok, sErr := s.verify(ctx, twoFactor, code)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if !ok {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid code", nil)
+	}
+
+	codes, hashed, err := newRecoveryCodes()
+	if err != nil {
+		zlog.Error("Error while generating recovery codes", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	err = dao.UpdateTwoFactor(ctx, ownerID, map[string]interface{}{
+		"enabled":       true,
+		"recoveryCodes": hashed,
+	})
+	if err != nil {
+		zlog.Error("Error while enabling two factor", zap.String("ownerID", ownerID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return codes, nil
+}
+
+// Disable removes the enrolment, a current code or a recovery code is required.
+// Admins cannot opt out while 2FA is enforced for them.
+func (s *TwoFactorService) Disable(ctx context.Context, ownerID string, isAdmin bool, code string) *errorx.ServiceErr {
+	if isAdmin {
+		enforced, sErr := s.IsAdminEnforced(ctx)
+		if sErr != nil {
+			return sErr
+		}
+		if enforced {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Two-factor authentication is enforced for admins", nil)
+		}
+	}
+
+	twoFactor, sErr := s.enabled(ctx, ownerID)
+	if sErr != nil {
+		return sErr
+	}
+
+	ok, sErr := s.verify(ctx, twoFactor, code)
+	if sErr != nil {
+		return sErr
+	}
+	if !ok {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Invalid code", nil)
+	}
+
+	if err := dao.DeleteTwoFactor(ctx, ownerID); err != nil {
+		zlog.Error("Error while deleting two factor", zap.String("ownerID", ownerID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, the old ones stop working
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, ownerID string, code string) ([]string, *errorx.ServiceErr) {
+	twoFactor, sErr := s.enabled(ctx, ownerID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	ok, sErr := s.verify(ctx, twoFactor, code)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if !ok {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Invalid code", nil)
+	}
+
+	codes, hashed, err := newRecoveryCodes()
+	if err != nil {
+		zlog.Error("Error while generating recovery codes", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	err = dao.UpdateTwoFactor(ctx, ownerID, map[string]interface{}{
+		"recoveryCodes": hashed,
+	})
+	if err != nil {
+		zlog.Error("Error while updating recovery codes", zap.String("ownerID", ownerID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return codes, nil
+}
+
+// IsAdminEnforced reports whether every admin has to use 2FA. It fails closed, true is returned
+// together with the error if the setting cannot be read.
+func (s *TwoFactorService) IsAdminEnforced(ctx context.Context) (bool, *errorx.ServiceErr) {
+	cached, err := redis.RDB().Get(ctx, adminEnforcedKey).Result()
+	if err == nil {
+		return cached == "1", nil
+	}
+	if !errors.Is(err, redis.KEY_NOT_FOUND) {
+		zlog.Error("Error while getting cached admin 2fa enforcement", zap.Error(err))
+	}
+
+	enforced := false
+	setting, err := dao.GetSetting(ctx, dao.SETTING_ADMIN_2FA_ENFORCED)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Error("Error while getting admin 2fa enforcement", zap.Error(err))
+			return true, errorx.NewInternalErr()
+		}
+	} else {
+		enforced = setting.Value == "1"
+	}
+
+	if err := redis.RDB().Set(ctx, adminEnforcedKey, settingValue(enforced), adminEnforcedCacheDuration).Err(); err != nil {
+		zlog.Error("Error while caching admin 2fa enforcement", zap.Error(err))
+	}
+
+	return enforced, nil
+}
+
+func (s *TwoFactorService) SetAdminEnforced(ctx context.Context, enforced bool) *errorx.ServiceErr {
+	if err := dao.SaveSetting(ctx, dao.SETTING_ADMIN_2FA_ENFORCED, settingValue(enforced)); err != nil {
+		zlog.Error("Error while setting admin 2fa enforcement", zap.Bool("enforced", enforced), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	// the cache expires on its own if this fails
+	if err := redis.RDB().Set(ctx, adminEnforcedKey, settingValue(enforced), adminEnforcedCacheDuration).Err(); err != nil {
+		zlog.Error("Error while caching admin 2fa enforcement", zap.Bool("enforced", enforced), zap.Error(err))
+	}
+
+	return nil
+}
+
+// BeginLogin is called once the password has been verified. It returns a challenge if a second
+// factor is needed, nil if tokens can be issued right away. An admin without 2FA gets an
+// enrolment challenge while it is enforced.
+func (s *TwoFactorService) BeginLogin(ctx context.Context, ownerID string, isAdmin bool, device *sdto.SessionDevice) (*sdto.TwoFactorChallenge, *errorx.ServiceErr) {
+	twoFactor, sErr := s.get(ctx, ownerID)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if twoFactor != nil && twoFactor.Enabled {
+		return s.newChallenge(ctx, ownerID, isAdmin, false, device)
+	}
+
+	if isAdmin {
+		enforced, sErr := s.IsAdminEnforced(ctx)
+		if sErr != nil {
+			return nil, sErr
+		}
+		if enforced {
+			return s.newChallenge(ctx, ownerID, isAdmin, true, device)
+		}
+	}
+
+	return nil, nil
+}
+
+// SetupWithChallenge lets an admin who has to enrol before logging in get a secret
+func (s *TwoFactorService) SetupWithChallenge(ctx context.Context, challengeToken string, isAdmin bool) (*sdto.TwoFactorSetupOutput, *errorx.ServiceErr) {
+	challenge, sErr := s.loadChallenge(ctx, challengeToken, isAdmin)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if challenge["enroll"] != "true" {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Two-factor authentication is already enabled", nil)
+	}
+
+	return s.Setup(ctx, challenge["ownerID"], isAdmin)
+}
+
+// CompleteLogin checks the second factor of a challenge and issues the token pair.
+// Enrolment challenges confirm the secret instead and return the new recovery codes.
+func (s *TwoFactorService) CompleteLogin(ctx context.Context, challengeToken string, isAdmin bool, code string) (*sdto.TwoFactorLoginOutput, *errorx.ServiceErr) {
+	challenge, sErr := s.loadChallenge(ctx, challengeToken, isAdmin)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	key := fmt.Sprintf(challengeKeyFmt, util.HashToken(challengeToken))
+	attempts, err := redis.RDB().HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil {
+		zlog.Error("Error while counting 2fa attempts", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if attempts > maxChallengeAttempts {
+		redis.RDB().Del(ctx, key)
+		return nil, errorx.NewServicerErr(401, "Too many attempts, please log in again", nil)
+	}
+
+	ownerID := challenge["ownerID"]
+	out := &sdto.TwoFactorLoginOutput{SubjectID: ownerID}
+
+	if challenge["enroll"] == "true" {
+		codes, sErr := s.Enable(ctx, ownerID, code)
+		if sErr != nil {
+			return nil, sErr
+		}
+		out.RecoveryCodes = codes
+	} else {
+		twoFactor, sErr := s.enabled(ctx, ownerID)
+		if sErr != nil {
+			return nil, sErr
+		}
+
+		ok, sErr := s.verify(ctx, twoFactor, code)
+		if sErr != nil {
+			return nil, sErr
+		}
+		if !ok {
+			zlog.Info("Invalid 2fa code", zap.String("ownerID", ownerID))
+			return nil, errorx.NewServicerErr(401, fmt.Sprintf("Invalid code, %d attempts remaining", maxChallengeAttempts-attempts), map[string]any{
+				"remaining_attempts": maxChallengeAttempts - attempts,
+			})
+		}
+	}
+
+	// the challenge is single use, whoever deletes it gets the tokens
+	deleted, err := redis.RDB().Del(ctx, key).Result()
+	if err != nil {
+		zlog.Error("Error while deleting 2fa challenge", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if deleted == 0 {
+		return nil, errorx.NewServicerErr(401, "Invalid or expired challenge", nil)
+	}
+
+	tokens, sErr := token.Service().Issue(ctx, ownerID, isAdmin, &sdto.SessionDevice{
+		DeviceName: challenge["deviceName"],
+		UserAgent:  challenge["userAgent"],
+		IP:         challenge["ip"],
+	})
+	if sErr != nil {
+		return nil, sErr
+	}
+	out.Tokens = tokens
+
+	return out, nil
+}
+
+func (s *TwoFactorService) newChallenge(ctx context.Context, ownerID string, isAdmin bool, enroll bool, device *sdto.SessionDevice) (*sdto.TwoFactorChallenge, *errorx.ServiceErr) {
+	challengeToken, err := util.RandomToken(32)
+	if err != nil {
+		zlog.Error("Error while generating 2fa challenge", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	key := fmt.Sprintf(challengeKeyFmt, util.HashToken(challengeToken))
+	pipe := redis.RDB().TxPipeline()
+	pipe.HSet(ctx, key,
+		"ownerID", ownerID,
+		"isAdmin", strconv.FormatBool(isAdmin),
+		"enroll", strconv.FormatBool(enroll),
+		"deviceName", device.DeviceName,
+		"userAgent", device.UserAgent,
+		"ip", device.IP,
+		"attempts", 0,
+	)
+	pipe.Expire(ctx, key, CHALLENGE_DURATION)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while storing 2fa challenge", zap.String("ownerID", ownerID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return &sdto.TwoFactorChallenge{
+		ChallengeToken: challengeToken,
+		EnrollRequired: enroll,
+		ExpiresAt:      time.Now().Add(CHALLENGE_DURATION).Unix(),
+	}, nil
+}
+
+func (s *TwoFactorService) loadChallenge(ctx context.Context, challengeToken string, isAdmin bool) (map[string]string, *errorx.ServiceErr) {
+	challenge, err := redis.RDB().HGetAll(ctx, fmt.Sprintf(challengeKeyFmt, util.HashToken(challengeToken))).Result()
+	if err != nil {
+		zlog.Error("Error while getting 2fa challenge", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	// a user challenge cannot be completed through the admin login and vice versa
+	if len(challenge) == 0 || challenge["isAdmin"] != strconv.FormatBool(isAdmin) {
+		return nil, errorx.NewServicerErr(401, "Invalid or expired challenge", nil)
+	}
+
+	return challenge, nil
+}
+
+// verify accepts a TOTP code or, once enabled, a recovery code which is used up
+func (s *TwoFactorService) verify(ctx context.Context, twoFactor *model.TwoFactor, code string) (bool, *errorx.ServiceErr) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+
+	if step, ok := util.ValidateTOTP(twoFactor.Secret, code, time.Now(), totpSkew); ok {
+		fresh, err := redis.RDB().SetNX(ctx, fmt.Sprintf(usedCodeKeyFmt, twoFactor.OwnerID, step), 1, usedCodeDuration).Result()
+		if err != nil {
+			zlog.Error("Error while marking totp code used", zap.Error(err))
+			return false, errorx.NewInternalErr()
+		}
+		if !fresh {
+			zlog.Warn("Replayed totp code", zap.String("ownerID", twoFactor.OwnerID))
+		}
+
+		return fresh, nil
+	}
+
+	if !twoFactor.Enabled || twoFactor.RecoveryCodes == nil {
+		return false, nil
+	}
+
+	return s.useRecoveryCode(ctx, twoFactor, code)
+}
+
+func (s *TwoFactorService) useRecoveryCode(ctx context.Context, twoFactor *model.TwoFactor, code string) (bool, *errorx.ServiceErr) {
+	hash := util.HashToken(normalizeRecoveryCode(code))
+	previous := *twoFactor.RecoveryCodes
+
+	var remaining []string
+	found := false
+	for _, h := range strings.Split(previous, "|") {
+		if !found && h == hash {
+			found = true
+			continue
+		}
+		if h != "" {
+			remaining = append(remaining, h)
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	replaced, err := dao.ReplaceRecoveryCodes(ctx, twoFactor.OwnerID, previous, strings.Join(remaining, "|"))
+	if err != nil {
+		zlog.Error("Error while using recovery code", zap.String("ownerID", twoFactor.OwnerID), zap.Error(err))
+		return false, errorx.NewInternalErr()
+	}
+	if replaced {
+		zlog.Info("Recovery code used", zap.String("ownerID", twoFactor.OwnerID), zap.Int("remaining", len(remaining)))
+	}
+
+	return replaced, nil
+}
+
+// get returns nil without error when the owner has never set up 2FA
+func (s *TwoFactorService) get(ctx context.Context, ownerID string) (*model.TwoFactor, *errorx.ServiceErr) {
+	twoFactor, err := dao.GetTwoFactorByOwnerID(ctx, ownerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		zlog.Error("Error while getting two factor", zap.String("ownerID", ownerID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return twoFactor, nil
+}
+
+func (s *TwoFactorService) enabled(ctx context.Context, ownerID string) (*model.TwoFactor, *errorx.ServiceErr) {
+	twoFactor, sErr := s.get(ctx, ownerID)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Two-factor authentication is not enabled", nil)
+	}
+
+	return twoFactor, nil
+}
+
+// accountName is the label shown in the authenticator app
+func accountName(ctx context.Context, ownerID string, isAdmin bool) (string, *errorx.ServiceErr) {
+	if isAdmin {
+		admin, err := dao.GetAdminByID(ctx, ownerID)
+		if err != nil {
+			zlog.Error("Error while getting admin by ID", zap.String("adminID", ownerID), zap.Error(err))
+			return "", errorx.NewInternalErr()
+		}
+
+		return "admin:" + admin.Username, nil
+	}
+
+	user, err := dao.GetUserByID(ctx, ownerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", ownerID), zap.Error(err))
+		return "", errorx.NewInternalErr()
+	}
+
+	return user.Username, nil
+}
+
+func issuer() string {
+	if name := config.Get("twoFactor.issuer"); !util.IsEmpty(name) {
+		return name
+	}
+
+	return defaultIssuer
+}
+
+// newRecoveryCodes returns the codes in the xxxxx-xxxxx form shown to the owner
+// and their hashes joined the way they are stored
+func newRecoveryCodes() ([]string, string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, "", err
+		}
+
+		raw := hex.EncodeToString(b)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = util.HashToken(raw)
+	}
+
+	return codes, strings.Join(hashes, "|"), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
+
+func settingValue(enabled bool) string {
+	if enabled {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,341 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/minio"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/zlog"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// An account deletion runs in the background, its progress is kept in redis
+//
+//	accountDeletion:<userID>  => hash of status, step, done, placeholder, error...
+//
+// Every step can run again, a failed or interrupted deletion continues at the step it stopped at.
+const (
+	accountDeletionKeyFmt = "accountDeletion:%s"
+	accountDeletionTTL    = 7 * 24 * time.Hour
+
+	// sign ups and activities of deleted users are kept under a placeholder id
+	deletedUserPrefix = "deleted-"
+)
+
+const (
+	DELETION_QUEUED  = "queued"
+	DELETION_RUNNING = "running"
+	DELETION_DONE    = "done"
+	DELETION_FAILED  = "failed"
+)
+
+type deletionStep struct {
+	name string
+	run  func(ctx context.Context, userID string, placeholder string) error
+}
+
+var deletionSteps = []deletionStep{
+	{"sessions", lockAccount},
+	{"moments", deleteMoments},
+	{"likes", func(ctx context.Context, userID string, _ string) error {
+		return dao.DeleteLikesByUserID(ctx, userID)
+	}},
+	{"comments", func(ctx context.Context, userID string, _ string) error {
+		return dao.DeleteCommentsByAuthorID(ctx, userID)
+	}},
+	{"follows", func(ctx context.Context, userID string, _ string) error {
+		return dao.DeleteFollowsByUserID(ctx, userID)
+	}},
+	{"notifications", func(ctx context.Context, userID string, _ string) error {
+		return dao.DeleteNotificationsByUserID(ctx, userID)
+	}},
+	{"activities", func(ctx context.Context, userID string, placeholder string) error {
+		if err := dao.AnonymiseActivityUsers(ctx, userID, placeholder); err != nil {
+			return err
+		}
+		return dao.ReassignActivitiesCreator(ctx, userID, placeholder)
+	}},
+	{"rides", func(ctx context.Context, userID string, _ string) error {
+		if err := dao.DeleteBadgesByUserID(ctx, userID); err != nil {
+			return err
+		}
+		return dao.DeleteRidesByUserID(ctx, userID)
+	}},
+	{"organiser", func(ctx context.Context, userID string, _ string) error {
+		return dao.DeleteOrganiserByUserID(ctx, userID)
+	}},
+	{"membership", func(ctx context.Context, userID string, placeholder string) error {
+		if err := dao.AnonymiseMembershipHistory(ctx, userID, placeholder); err != nil {
+			return err
+		}
+		return dao.AnonymiseVouchers(ctx, userID, placeholder)
+	}},
+	{"credits", func(ctx context.Context, userID string, placeholder string) error {
+		if err := dao.DeleteCreditLedgerByUserID(ctx, userID); err != nil {
+			return err
+		}
+		return dao.AnonymiseReferrals(ctx, userID, placeholder)
+	}},
+	{"privacy zones", func(ctx context.Context, userID string, _ string) error {
+		return dao.DeletePrivacyZonesByUserID(ctx, userID)
+	}},
+	{"blocks", func(ctx context.Context, userID string, _ string) error {
+		return dao.DeleteRelationsByUserID(ctx, userID)
+	}},
+	{"account", deleteAccount},
+}
+
+// DeleteAccount is the deletion requested by the user, it needs the password once more
+func (s *UserService) DeleteAccount(ctx context.Context, userID string, password string) (*sdto.AccountDeletionStatus, *errorx.ServiceErr) {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	if !util.VerifyPassword(user.Password, password) {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "Wrong password", nil)
+	}
+
+	return s.RequestDeletion(ctx, userID, userID)
+}
+
+// RequestDeletion schedules the deletion of the account, requesting it again returns
+// the running deletion and restarts a failed one
+func (s *UserService) RequestDeletion(ctx context.Context, userID string, requestedBy string) (*sdto.AccountDeletionStatus, *errorx.ServiceErr) {
+	key := fmt.Sprintf(accountDeletionKeyFmt, userID)
+
+	status, sErr := s.DeletionStatus(ctx, userID)
+	if sErr == nil && status.Status != DELETION_FAILED {
+		return status, nil
+	}
+
+	if status == nil {
+		if _, err := dao.GetUserByID(ctx, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+			}
+			zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+
+		created, err := redis.RDB().HSetNX(ctx, key, "status", DELETION_QUEUED).Result()
+		if err != nil {
+			zlog.Error("Error while scheduling account deletion", zap.String("userID", userID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+		if !created {
+			// requested at the same time
+			return s.DeletionStatus(ctx, userID)
+		}
+
+		err = redis.RDB().HSet(ctx, key,
+			"placeholder", deletedUserPrefix+uuid.NewString(),
+			"done", 0,
+			"requestedBy", requestedBy,
+			"requestedAt", time.Now().Unix(),
+		).Err()
+		if err != nil {
+			zlog.Error("Error while scheduling account deletion", zap.String("userID", userID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+	} else {
+		// keep the placeholder and the finished steps of the failed run
+		if err := redis.RDB().HSet(ctx, key, "status", DELETION_QUEUED, "error", "").Err(); err != nil {
+			zlog.Error("Error while scheduling account deletion", zap.String("userID", userID), zap.Error(err))
+			return nil, errorx.NewInternalErr()
+		}
+	}
+
+	zlog.Info("Account deletion scheduled", zap.String("userID", userID), zap.String("requestedBy", requestedBy))
+	go s.runDeletion(context.Background(), userID)
+
+	return s.DeletionStatus(ctx, userID)
+}
+
+func (s *UserService) DeletionStatus(ctx context.Context, userID string) (*sdto.AccountDeletionStatus, *errorx.ServiceErr) {
+	fields, err := redis.RDB().HGetAll(ctx, fmt.Sprintf(accountDeletionKeyFmt, userID)).Result()
+	if err != nil {
+		zlog.Error("Error while getting account deletion", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+	if len(fields) == 0 || fields["requestedAt"] == "" {
+		return nil, errorx.NewServicerErr(errorx.ErrExternal, "No deletion requested for this account", nil)
+	}
+
+	done, _ := strconv.Atoi(fields["done"])
+	requestedAt, _ := strconv.ParseInt(fields["requestedAt"], 10, 64)
+	finishedAt, _ := strconv.ParseInt(fields["finishedAt"], 10, 64)
+
+	return &sdto.AccountDeletionStatus{
+		UserID:      userID,
+		Status:      fields["status"],
+		Step:        fields["step"],
+		Progress:    done * 100 / len(deletionSteps),
+		Error:       fields["error"],
+		RequestedBy: fields["requestedBy"],
+		RequestedAt: requestedAt,
+		FinishedAt:  finishedAt,
+	}, nil
+}
+
+// ResumeDeletions restarts the deletions that were queued or running when the server stopped
+func (s *UserService) ResumeDeletions(ctx context.Context) {
+	iter := redis.RDB().Scan(ctx, 0, fmt.Sprintf(accountDeletionKeyFmt, "*"), 100).Iterator()
+	for iter.Next(ctx) {
+		userID := strings.TrimPrefix(iter.Val(), fmt.Sprintf(accountDeletionKeyFmt, ""))
+
+		status, err := redis.RDB().HGet(ctx, iter.Val(), "status").Result()
+		if err != nil {
+			continue
+		}
+		if status == DELETION_QUEUED || status == DELETION_RUNNING {
+			zlog.Info("Resuming account deletion", zap.String("userID", userID))
+			go s.runDeletion(ctx, userID)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		zlog.Error("Error while resuming account deletions", zap.Error(err))
+	}
+}
+
+func (s *UserService) runDeletion(ctx context.Context, userID string) {
+	key := fmt.Sprintf(accountDeletionKeyFmt, userID)
+
+	fields, err := redis.RDB().HGetAll(ctx, key).Result()
+	if err != nil {
+		zlog.Error("Error while getting account deletion", zap.String("userID", userID), zap.Error(err))
+		return
+	}
+	done, _ := strconv.Atoi(fields["done"])
+
+	placeholder := fields["placeholder"]
+	if placeholder == "" {
+		placeholder = deletedUserPrefix + uuid.NewString()
+		redis.RDB().HSet(ctx, key, "placeholder", placeholder)
+	}
+
+	redis.RDB().HSet(ctx, key, "status", DELETION_RUNNING)
+
+	for i := done; i < len(deletionSteps); i++ {
+		step := deletionSteps[i]
+		redis.RDB().HSet(ctx, key, "step", step.name)
+
+		if err := step.run(ctx, userID, placeholder); err != nil {
+			zlog.Error("Account deletion failed", zap.String("userID", userID), zap.String("step", step.name), zap.Error(err))
+			redis.RDB().HSet(ctx, key, "status", DELETION_FAILED, "error", "Failed while deleting "+step.name)
+			return
+		}
+
+		redis.RDB().HSet(ctx, key, "done", i+1)
+	}
+
+	pipe := redis.RDB().TxPipeline()
+	pipe.HSet(ctx, key, "status", DELETION_DONE, "finishedAt", time.Now().Unix())
+	pipe.Expire(ctx, key, accountDeletionTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while finishing account deletion", zap.String("userID", userID), zap.Error(err))
+	}
+
+	zlog.Info("Account deleted", zap.String("userID", userID))
+}
+
+// lockAccount stops every way of logging in before anything is deleted
+func lockAccount(ctx context.Context, userID string, _ string) error {
+	// not a bcrypt hash, no password matches it
+	err := dao.UpdateUserByID(ctx, userID, map[string]interface{}{"password": "!"})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := dao.DeleteIdentitiesByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := dao.DeleteTwoFactor(ctx, userID); err != nil {
+		return err
+	}
+
+	return revokeTokens(ctx, userID)
+}
+
+func revokeTokens(ctx context.Context, userID string) error {
+	if sErr := token.Service().RevokeAll(ctx, userID); sErr != nil {
+		return sErr
+	}
+	if sErr := token.Service().Outdate(ctx, userID); sErr != nil {
+		return sErr
+	}
+
+	return nil
+}
+
+// deleteMoments removes the media before the rows, a retry would not find them otherwise
+func deleteMoments(ctx context.Context, userID string, _ string) error {
+	moments, err := dao.GetMomentsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, moment := range moments {
+		objects, err := dao.GetAttachmentObjects(ctx, moment.MomentID)
+		if err != nil {
+			return err
+		}
+		if err := minio.RemoveMomentMedia(ctx, objects...); err != nil {
+			return err
+		}
+
+		if err := dao.DeleteMomentCascade(ctx, moment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteAccount(ctx context.Context, userID string, _ string) error {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if user != nil && user.AvatarURL != nil && *user.AvatarURL != "" {
+		if err := minio.RemoveObject(ctx, minio.AVATAR_BUCKET, *user.AvatarURL); err != nil {
+			return err
+		}
+	}
+
+	if err := dao.DeleteBansByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := redis.RDB().Del(ctx, fmt.Sprintf(banKeyFmt, userID)).Err(); err != nil {
+		return err
+	}
+
+	// a password reset during the deletion could have opened new sessions
+	if err := revokeTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	if user != nil {
+		if _, _, err := dao.DeleteUsersByID(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,435 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MySQL holds the bans, redis only mirrors the active ones for the checks on every login
+//
+//	ban:<userID>  => id of the active ban, expires together with it
+//	bansSynced    => set after the mirror has been rebuilt, missing after a flush
+const (
+	banKeyFmt     = "ban:%s"
+	bansSyncedKey = "bansSynced"
+
+	// value of ban keys written before bans were stored in MySQL
+	legacyBanValue = "banned"
+	legacyBanAdmin = "system"
+)
+
+var banSyncMu sync.Mutex
+
+func (s *UserService) BanByID(ctx context.Context, in *sdto.BanInput) *errorx.ServiceErr {
+	if in.Duration < 0 {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Ban duration cannot be negative", nil)
+	}
+
+	ids := strings.Split(in.UserIDs, "|")
+	var bannedIDs []string
+	var notFoundIDs []string
+	var alreadyBannedIDs []string
+
+	for _, id := range ids {
+		_, err := dao.GetUserByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundIDs = append(notFoundIDs, id)
+				continue
+			} else {
+				zlog.Error("Failed to retrieve user by ID", zap.String("userID", id), zap.Error(err))
+				return errorx.NewInternalErr()
+			}
+		}
+
+		now := time.Now()
+		_, err = dao.GetActiveBan(ctx, id, now)
+		if err == nil {
+			alreadyBannedIDs = append(alreadyBannedIDs, id)
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Error("Failed to get active ban", zap.String("userID", id), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+
+		ban := &model.Ban{
+			UserID:  id,
+			AdminID: in.AdminID,
+			Reason:  in.Reason,
+			StartAt: now,
+		}
+		if in.Duration > 0 {
+			endAt := now.Add(in.Duration)
+			ban.EndAt = &endAt
+		}
+
+		if err := dao.CreateBan(ctx, ban); err != nil {
+			zlog.Error("Failed to ban user", zap.String("userID", id), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+
+		if err := mirrorBan(ctx, ban); err != nil {
+			// the ban is stored, the next sync puts it into redis
+			zlog.Error("Failed to mirror ban into redis", zap.String("userID", id), zap.Error(err))
+		}
+
+		// Banned users are logged out everywhere at once
+		if sErr := token.Service().RevokeAll(ctx, id); sErr != nil {
+			return sErr
+		}
+		bannedIDs = append(bannedIDs, id)
+	}
+
+	// All specified users were not found
+	if len(notFoundIDs) == len(ids) {
+		zlog.Warn("All specified users not found", zap.Strings("not_found_ids", notFoundIDs))
+		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users not found", map[string]any{"not_found_ids": notFoundIDs})
+	}
+
+	// All specified users were already banned
+	if len(alreadyBannedIDs) == len(ids) {
+		zlog.Warn("All specified users already banned", zap.Strings("already_banned_ids", alreadyBannedIDs))
+		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users already banned", map[string]any{"already_banned_ids": alreadyBannedIDs})
+	}
+
+	zlog.Info("Specified users banned", zap.Strings("banned_user_ids", bannedIDs), zap.String("adminID", in.AdminID), zap.Duration("duration", in.Duration))
+	// Part of specified users were not found
+	if len(notFoundIDs) > 0 {
+		zlog.Warn("Some specified users not found", zap.Strings("not_found_ids", notFoundIDs))
+	}
+
+	// Part of specified users were already banned
+	if len(alreadyBannedIDs) > 0 {
+		zlog.Warn("Some specified users already banned", zap.Strings("already_banned_ids", alreadyBannedIDs))
+	}
+
+	return nil
+}
+
+// UnbanByID lifts the active bans, they stay in the history
+func (s *UserService) UnbanByID(ctx context.Context, userIDs string, adminID string) *errorx.ServiceErr {
+	ids := strings.Split(userIDs, "|")
+	var unbannedIDs []string
+	var notFoundIDs []string
+	var notBannedIDs []string
+
+	for _, id := range ids {
+		_, err := dao.GetUserByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundIDs = append(notFoundIDs, id)
+				continue
+			} else {
+				zlog.Error("Failed to retrieve user by ID", zap.String("userID", id), zap.Error(err))
+				return errorx.NewInternalErr()
+			}
+		}
+
+		lifted, err := dao.LiftActiveBans(ctx, id, adminID, time.Now())
+		if err != nil {
+			zlog.Error("Failed to unban user", zap.String("userID", id), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+
+		deleted, err := redis.RDB().Del(ctx, fmt.Sprintf(banKeyFmt, id)).Result()
+		if err != nil {
+			zlog.Error("Failed to remove ban from redis", zap.String("userID", id), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+
+		if lifted == 0 && deleted == 0 {
+			notBannedIDs = append(notBannedIDs, id)
+			continue
+		}
+		unbannedIDs = append(unbannedIDs, id)
+	}
+
+	// All specified users were not found
+	if len(notFoundIDs) == len(ids) {
+		zlog.Warn("All specified users not found", zap.Strings("not_found_ids", notFoundIDs))
+		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users not found", map[string]any{"not_found_ids": notFoundIDs})
+	}
+
+	// All specified users were not banned
+	if len(notBannedIDs) == len(ids) {
+		zlog.Warn("All specified users were not banned", zap.Strings("not_banned_ids", notBannedIDs))
+		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users were not banned", map[string]any{"not_banned_ids": notBannedIDs})
+	}
+
+	zlog.Info("Specified users unbanned", zap.Strings("unbanned_user_ids", unbannedIDs), zap.String("adminID", adminID))
+	// Part of specified users were not found
+	if len(notFoundIDs) > 0 {
+		zlog.Warn("Some specified users not found", zap.Strings("not_found_ids", notFoundIDs))
+	}
+
+	// Part of specified users were not banned
+	if len(notBannedIDs) > 0 {
+		zlog.Warn("Some specified users were not banned", zap.Strings("not_banned_ids", notBannedIDs))
+	}
+
+	return nil
+}
+
+// IsBanned checks the redis mirror, the mirror is rebuilt first if redis has been flushed.
+// MySQL is asked directly while redis is unavailable.
+func (s *UserService) IsBanned(ctx context.Context, userID string) bool {
+	pipe := redis.RDB().Pipeline()
+	banned := pipe.Exists(ctx, fmt.Sprintf(banKeyFmt, userID))
+	synced := pipe.Exists(ctx, bansSyncedKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Failed to check ban in redis", zap.String("userID", userID), zap.Error(err))
+		return s.isBannedInDB(ctx, userID)
+	}
+
+	if synced.Val() == 0 {
+		if err := s.resyncBans(ctx); err != nil {
+			zlog.Error("Failed to sync bans", zap.Error(err))
+			return s.isBannedInDB(ctx, userID)
+		}
+
+		exists, err := redis.RDB().Exists(ctx, fmt.Sprintf(banKeyFmt, userID)).Result()
+		if err != nil {
+			return s.isBannedInDB(ctx, userID)
+		}
+		return exists > 0
+	}
+
+	return banned.Val() > 0
+}
+
+// ActiveBan returns the ban in force for the user, nil if there is none
+func (s *UserService) ActiveBan(ctx context.Context, userID string) (*sdto.BanRecord, *errorx.ServiceErr) {
+	now := time.Now()
+
+	ban, err := dao.GetActiveBan(ctx, userID, now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		zlog.Error("Failed to get active ban", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	return toBanRecord(ban, now), nil
+}
+
+// BannedErr tells a banned user why and until when
+func (s *UserService) BannedErr(ctx context.Context, userID string) *errorx.ServiceErr {
+	data := map[string]any{}
+	if ban, sErr := s.ActiveBan(ctx, userID); sErr == nil && ban != nil {
+		data["ban_reason"] = ban.Reason
+		data["ban_expires"] = ban.EndAt
+	}
+
+	return errorx.NewServicerErr(errorx.ErrExternal, "account is banned", data)
+}
+
+// GetAllStatus lists every user with the ban in force and the full ban history
+func (s *UserService) GetAllStatus(ctx context.Context) ([]*sdto.GetAllStatusOutput, *errorx.ServiceErr) {
+	users, err := dao.GetAllUsers(ctx)
+	if err != nil {
+		return nil, errorx.NewInternalErr()
+	}
+
+	bans, err := dao.GetBansByUserIDs(ctx, nil)
+	if err != nil {
+		zlog.Error("Failed to get ban history", zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	now := time.Now()
+	history := make(map[string][]*sdto.BanRecord)
+	for _, ban := range bans {
+		history[ban.UserID] = append(history[ban.UserID], toBanRecord(ban, now))
+	}
+
+	var statusList []*sdto.GetAllStatusOutput
+	for _, user := range users {
+		status := &sdto.GetAllStatusOutput{
+			UserID:     user.UserID,
+			BanHistory: history[user.UserID],
+		}
+		if status.BanHistory == nil {
+			status.BanHistory = []*sdto.BanRecord{}
+		}
+
+		// history is ordered by start time, the first active one is the latest
+		for _, record := range status.BanHistory {
+			if record.Active {
+				status.IsBanned = true
+				status.ActiveBan = record
+				break
+			}
+		}
+
+		statusList = append(statusList, status)
+	}
+
+	return statusList, nil
+}
+
+// AppealBan records the appeal of the user against a ban, e.g. as received by support
+func (s *UserService) AppealBan(ctx context.Context, banID int32, note string) *errorx.ServiceErr {
+	_, err := dao.GetBanByID(ctx, banID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Ban not found", nil)
+		}
+
+		zlog.Error("Failed to get ban", zap.Int32("banID", banID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	if err := dao.UpdateBanAppeal(ctx, banID, note); err != nil {
+		zlog.Error("Failed to update ban appeal", zap.Int32("banID", banID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// SyncBans rebuilds the redis mirror from MySQL. Ban keys written before bans were stored
+// in MySQL are turned into permanent bans so that nobody is unbanned by the migration.
+func (s *UserService) SyncBans(ctx context.Context) error {
+	now := time.Now()
+
+	active, err := dao.GetActiveBans(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	activeIDs := make(map[string]bool, len(active))
+	for _, ban := range active {
+		activeIDs[strconv.Itoa(int(ban.ID))] = true
+	}
+
+	iter := redis.RDB().Scan(ctx, 0, fmt.Sprintf(banKeyFmt, "*"), 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		userID := strings.TrimPrefix(key, fmt.Sprintf(banKeyFmt, ""))
+
+		value, err := redis.RDB().Get(ctx, key).Result()
+		if err != nil {
+			continue
+		}
+		if activeIDs[value] {
+			continue
+		}
+
+		if value == legacyBanValue {
+			if _, err := dao.GetActiveBan(ctx, userID, now); errors.Is(err, gorm.ErrRecordNotFound) {
+				ban := &model.Ban{
+					UserID:  userID,
+					AdminID: legacyBanAdmin,
+					Reason:  "Banned before the ban history was recorded",
+					StartAt: now,
+				}
+				if err := dao.CreateBan(ctx, ban); err != nil {
+					return err
+				}
+				active = append(active, ban)
+				zlog.Info("Migrated legacy ban", zap.String("userID", userID))
+			}
+			continue
+		}
+
+		// lifted in MySQL, or a ban created after the active ones were loaded
+		if _, err := dao.GetActiveBan(ctx, userID, now); errors.Is(err, gorm.ErrRecordNotFound) {
+			redis.RDB().Del(ctx, key)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	for _, ban := range active {
+		if err := mirrorBan(ctx, ban); err != nil {
+			return err
+		}
+	}
+
+	return redis.RDB().Set(ctx, bansSyncedKey, now.Unix(), 0).Err()
+}
+
+// resyncBans rebuilds the mirror once even if many checks notice the flush at the same time
+func (s *UserService) resyncBans(ctx context.Context) error {
+	banSyncMu.Lock()
+	defer banSyncMu.Unlock()
+
+	synced, err := redis.RDB().Exists(ctx, bansSyncedKey).Result()
+	if err != nil {
+		return err
+	}
+	if synced > 0 {
+		return nil
+	}
+
+	zlog.Warn("Ban mirror missing in redis, rebuilding it from MySQL")
+	return s.SyncBans(ctx)
+}
+
+func (s *UserService) isBannedInDB(ctx context.Context, userID string) bool {
+	_, err := dao.GetActiveBan(ctx, userID, time.Now())
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Error("Failed to get active ban", zap.String("userID", userID), zap.Error(err))
+		}
+		return false
+	}
+
+	return true
+}
+
+// mirrorBan writes the ban into redis, the key expires when the ban ends
+func mirrorBan(ctx context.Context, ban *model.Ban) error {
+	var ttl time.Duration
+	if ban.EndAt != nil {
+		ttl = time.Until(*ban.EndAt)
+		if ttl <= 0 {
+			return nil
+		}
+	}
+
+	return redis.RDB().Set(ctx, fmt.Sprintf(banKeyFmt, ban.UserID), ban.ID, ttl).Err()
+}
+
+func toBanRecord(ban *model.Ban, now time.Time) *sdto.BanRecord {
+	record := &sdto.BanRecord{
+		BanID:   ban.ID,
+		AdminID: ban.AdminID,
+		Reason:  ban.Reason,
+		StartAt: ban.StartAt.Unix(),
+		Active:  ban.LiftedAt == nil && !ban.StartAt.After(now) && (ban.EndAt == nil || ban.EndAt.After(now)),
+	}
+	if ban.EndAt != nil {
+		record.EndAt = ban.EndAt.Unix()
+	}
+	if ban.LiftedAt != nil {
+		record.LiftedAt = ban.LiftedAt.Unix()
+	}
+	if ban.LiftedBy != nil {
+		record.LiftedBy = *ban.LiftedBy
+	}
+	if ban.AppealNote != nil {
+		record.AppealNote = *ban.AppealNote
+	}
+
+	return record
+}
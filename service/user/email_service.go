@@ -0,0 +1,155 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/mailer"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	verifyTokenDuration = 24 * time.Hour
+
+	// emailVerify:<tokenHash> => userID|email the token was issued for
+	verifyTokenKeyFmt = "emailVerify:%s"
+)
+
+// NormalizeEmail makes emails comparable, the domain part is case insensitive and so are most mailboxes
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkEmailAvailable fails if another user than userID already owns email
+func (s *UserService) checkEmailAvailable(ctx context.Context, email string, userID string) *errorx.ServiceErr {
+	owner, err := dao.FindUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		zlog.Error("Error while finding user by email", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	if owner.UserID != userID {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Email already in use", nil)
+	}
+
+	return nil
+}
+
+// SendVerification mails a verification link for the current email of the user
+func (s *UserService) SendVerification(ctx context.Context, userID string) *errorx.ServiceErr {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	if user.Email == nil || util.IsEmpty(*user.Email) {
+		return errorx.NewServicerErr(errorx.ErrExternal, "User has no email", nil)
+	}
+
+	if user.EmailVerified {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Email already verified", nil)
+	}
+
+	return s.sendVerificationMail(ctx, user)
+}
+
+// VerifyEmail consumes a verification token, it only counts if the email has not changed since it was sent
+func (s *UserService) VerifyEmail(ctx context.Context, verifyToken string) *errorx.ServiceErr {
+	value, err := redis.RDB().GetDel(ctx, fmt.Sprintf(verifyTokenKeyFmt, util.HashToken(verifyToken))).Result()
+	if err != nil {
+		if err == redis.KEY_NOT_FOUND {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Invalid or expired verification token", nil)
+		}
+
+		zlog.Error("Error while consuming verification token", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	userID, email, _ := strings.Cut(value, "|")
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	if user.Email == nil || *user.Email != email {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Email has changed since the token was sent", nil)
+	}
+
+	err = dao.UpdateUserByID(ctx, userID, map[string]interface{}{
+		"emailVerified": true,
+	})
+	if err != nil {
+		zlog.Error("Failed to mark email verified", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// RequireVerifiedEmail is the gate for features involving payments
+func RequireVerifiedEmail(user *model.User) *errorx.ServiceErr {
+	if user.Email == nil || !user.EmailVerified {
+		return errorx.NewServicerErr(errorx.ErrExternal, "A verified email is required", map[string]any{
+			"email_verified": false,
+		})
+	}
+
+	return nil
+}
+
+func (s *UserService) sendVerificationMail(ctx context.Context, user *model.User) *errorx.ServiceErr {
+	verifyToken, err := util.RandomToken(32)
+	if err != nil {
+		zlog.Error("Error while generating verification token", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	key := fmt.Sprintf(verifyTokenKeyFmt, util.HashToken(verifyToken))
+	err = redis.RDB().Set(ctx, key, user.UserID+"|"+*user.Email, verifyTokenDuration).Err()
+	if err != nil {
+		zlog.Error("Error while storing verification token", zap.String("userID", user.UserID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	err = mailer.Default().Send(ctx, &mailer.Message{
+		To:      *user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm this is your email by opening the link below within %d hours:\n\n%s\n",
+			user.Username,
+			int(verifyTokenDuration.Hours()),
+			fmt.Sprintf(config.Get("mail.verifyEmailUrl"), verifyToken),
+		),
+	})
+	if err != nil {
+		zlog.Error("Error while sending verification mail", zap.String("userID", user.UserID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
@@ -0,0 +1,467 @@
+package user
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/minio"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Layout of the archive:
+//
+//	profile.json        account, privacy zones, badges, linked identities, organiser status and bans
+//	moments.json        moments with the comments and likes they received
+//	comments.json       comments written by the user
+//	likes.json          moments liked by the user
+//	follows.json        followers and followings
+//	activities.json     activities joined and created
+//	membership.json     changes of the membership and what was charged
+//	credits.json        credits earned through referrals and spent on activities
+//	notifications.json  notifications sent and received
+//	routes/*.gpx        routes of moments and activities
+//	media/...           avatar and moment images and videos
+
+type exportProfile struct {
+	UserID          string               `json:"userId"`
+	Username        string               `json:"username"`
+	Email           *string              `json:"email"`
+	EmailVerified   bool                 `json:"emailVerified"`
+	Gender          int32                `json:"gender"`
+	Region          string               `json:"region"`
+	Tags            *string              `json:"tags"`
+	Birthday        *time.Time           `json:"birthday"`
+	MembershipType  int32                `json:"membershipType"`
+	MembershipTime  int64                `json:"membershipTime"`
+	AutoRenew       bool                 `json:"autoRenew"`
+	TrialUsed       bool                 `json:"trialUsed"`
+	ReferralCode    *string              `json:"referralCode"`
+	ReferredBy      *string              `json:"referredBy"`
+	Credits         int32                `json:"credits"`
+	Privacy         sdto.PrivacySettings `json:"privacy"`
+	PrivacyZones    []*model.PrivacyZone `json:"privacyZones"`
+	Badges          []*model.UserBadge   `json:"badges"`
+	CreatedAt       *time.Time           `json:"createdAt"`
+	Avatar          string               `json:"avatar,omitempty"`
+	OrganiserStatus *int32               `json:"organiserStatus"`
+	Identities      []exportIdentity     `json:"identities"`
+	Bans            []*sdto.BanRecord    `json:"bans"`
+}
+
+type exportIdentity struct {
+	Provider    string     `json:"provider"`
+	Email       *string    `json:"email"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
+
+type exportMoment struct {
+	MomentID string  `json:"momentId"`
+	Content  *string `json:"content"`
+	// the images and video of the moment in their order
+	Media     []string        `json:"media,omitempty"`
+	Route     string          `json:"route,omitempty"`
+	CreatedAt *time.Time      `json:"createdAt"`
+	Comments  []exportComment `json:"comments"`
+	LikedBy   []string        `json:"likedBy"`
+}
+
+type exportComment struct {
+	CommentID string     `json:"commentId"`
+	MomentID  string     `json:"momentId"`
+	AuthorID  string     `json:"authorId"`
+	Content   string     `json:"content"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+type exportFollow struct {
+	UserID   string `json:"userId"`
+	Username string `json:"username"`
+}
+
+type exportActivity struct {
+	ActivityID string     `json:"activityId"`
+	Name       string     `json:"name"`
+	StartDate  time.Time  `json:"startDate"`
+	EndDate    time.Time  `json:"endDate"`
+	FinalFee   *int32     `json:"finalFee,omitempty"`
+	Route      string     `json:"route,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
+
+// exportMedia is an object copied from minio into the archive
+type exportMedia struct {
+	bucket string
+	object string
+	name   string
+}
+
+// ExportData collects everything stored about the user, the media is only read from minio
+// while the archive is written
+func (s *UserService) ExportData(ctx context.Context, userID string) (*sdto.ExportDataOutput, *errorx.ServiceErr) {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	files := make(map[string]any)
+	routes := make(map[string][]byte)
+	var media []exportMedia
+
+	fail := func(msg string, err error) (*sdto.ExportDataOutput, *errorx.ServiceErr) {
+		zlog.Error(msg, zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	// exportRoute adds the route as gpx and returns its name in the archive
+	exportRoute := func(name string, routeID *int32) (string, error) {
+		if routeID == nil {
+			return "", nil
+		}
+
+		linestring, err := dao.GetPathAsText(ctx, *routeID)
+		if err != nil {
+			return "", err
+		}
+		if linestring == "" {
+			return "", nil
+		}
+
+		data, err := util.LineStringToGPX(name, linestring)
+		if err != nil {
+			return "", err
+		}
+
+		fileName := path.Join("routes", name+".gpx")
+		routes[fileName] = data
+		return fileName, nil
+	}
+
+	// exportObject queues the object and returns its name in the archive
+	exportObject := func(bucket string, dir string, object *string) string {
+		if object == nil || *object == "" {
+			return ""
+		}
+
+		name := path.Join("media", dir, path.Base(*object))
+		media = append(media, exportMedia{bucket: bucket, object: *object, name: name})
+		return name
+	}
+
+	// profile
+	profile := exportProfile{
+		UserID:         user.UserID,
+		Username:       user.Username,
+		Email:          user.Email,
+		EmailVerified:  user.EmailVerified,
+		Gender:         user.Gender,
+		Region:         user.Region,
+		Tags:           user.Tags,
+		Birthday:       user.Birthday,
+		MembershipType: user.MembershipType,
+		MembershipTime: user.MembershipTime,
+		AutoRenew:      user.AutoRenew,
+		TrialUsed:      user.TrialUsed,
+		ReferralCode:   user.ReferralCode,
+		ReferredBy:     user.ReferredBy,
+		Credits:        user.Credits,
+		Privacy: sdto.PrivacySettings{
+			ProfileVisibility: user.ProfileVisibility,
+			HideBirthday:      user.HideBirthday,
+			HideRoutes:        user.HideRoutes,
+			FollowPolicy:      user.FollowPolicy,
+		},
+		CreatedAt:  user.CreatedAt,
+		Avatar:     exportObject(minio.AVATAR_BUCKET, "avatar", user.AvatarURL),
+		Identities: []exportIdentity{},
+		Bans:       []*sdto.BanRecord{},
+	}
+
+	profile.PrivacyZones, err = dao.GetPrivacyZonesByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get privacy zones for export", err)
+	}
+
+	profile.Badges, err = dao.GetBadgesByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get badges for export", err)
+	}
+
+	organiser, err := dao.GetOrganiserByID(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fail("Failed to get organiser for export", err)
+	}
+	if organiser != nil {
+		profile.OrganiserStatus = &organiser.Status
+	}
+
+	identities, err := dao.GetIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get identities for export", err)
+	}
+	for _, identity := range identities {
+		profile.Identities = append(profile.Identities, exportIdentity{
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			CreatedAt:   identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		})
+	}
+
+	bans, err := dao.GetBansByUserIDs(ctx, []string{userID})
+	if err != nil {
+		return fail("Failed to get bans for export", err)
+	}
+	now := time.Now()
+	for _, ban := range bans {
+		profile.Bans = append(profile.Bans, toBanRecord(ban, now))
+	}
+	files["profile.json"] = profile
+
+	// moments
+	moments, err := dao.GetMomentsByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get moments for export", err)
+	}
+	momentIDs := make([]string, len(moments))
+	for i, moment := range moments {
+		momentIDs[i] = moment.MomentID
+	}
+	attachments, err := dao.GetAttachmentsByMomentIDs(ctx, momentIDs)
+	if err != nil {
+		return fail("Failed to get moment attachments for export", err)
+	}
+	momentMedia := make(map[string][]string, len(moments))
+	for _, attachment := range attachments {
+		if name := exportObject(minio.MOMENT_BUCKET, "moments", attachment.ObjectName); name != "" {
+			momentMedia[attachment.MomentID] = append(momentMedia[attachment.MomentID], name)
+		}
+	}
+
+	exportMoments := make([]exportMoment, 0, len(moments))
+	for _, moment := range moments {
+		out := exportMoment{
+			MomentID:  moment.MomentID,
+			Content:   moment.Content,
+			Media:     momentMedia[moment.MomentID],
+			CreatedAt: moment.CreatedAt,
+			Comments:  []exportComment{},
+			LikedBy:   []string{},
+		}
+
+		out.Route, err = exportRoute("moment-"+moment.MomentID, moment.RouteID)
+		if err != nil {
+			return fail("Failed to export route of moment", err)
+		}
+
+		comments, err := dao.GetCommentsByMomentId(ctx, moment.MomentID)
+		if err != nil {
+			return fail("Failed to get comments for export", err)
+		}
+		for _, comment := range comments {
+			out.Comments = append(out.Comments, exportComment{
+				CommentID: comment.CommentID,
+				MomentID:  comment.MomentID,
+				AuthorID:  comment.AuthorID,
+				Content:   comment.Content,
+				CreatedAt: comment.CreatedAt,
+			})
+		}
+
+		likes, err := dao.GetLikeByMomentId(ctx, moment.MomentID)
+		if err != nil {
+			return fail("Failed to get likes for export", err)
+		}
+		for _, like := range likes {
+			out.LikedBy = append(out.LikedBy, like.UserID)
+		}
+
+		exportMoments = append(exportMoments, out)
+	}
+	files["moments.json"] = exportMoments
+
+	// comments and likes given
+	comments, err := dao.GetCommentsByAuthorID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get comments for export", err)
+	}
+	exportComments := make([]exportComment, 0, len(comments))
+	for _, comment := range comments {
+		exportComments = append(exportComments, exportComment{
+			CommentID: comment.CommentID,
+			MomentID:  comment.MomentID,
+			AuthorID:  comment.AuthorID,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+	files["comments.json"] = exportComments
+
+	likes, err := dao.GetLikesByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get likes for export", err)
+	}
+	files["likes.json"] = likes
+
+	// follows
+	followers, err := dao.GetFollowersByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get followers for export", err)
+	}
+	followings, err := dao.GetFollowingsByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get followings for export", err)
+	}
+	toFollows := func(users []*model.User) []exportFollow {
+		follows := make([]exportFollow, 0, len(users))
+		for _, u := range users {
+			follows = append(follows, exportFollow{UserID: u.UserID, Username: u.Username})
+		}
+		return follows
+	}
+	files["follows.json"] = map[string][]exportFollow{
+		"followers":  toFollows(followers),
+		"followings": toFollows(followings),
+	}
+
+	// activities
+	activityUsers, err := dao.GetActivityUsersByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get sign ups for export", err)
+	}
+	joined := make([]exportActivity, 0, len(activityUsers))
+	for _, activityUser := range activityUsers {
+		activity, err := dao.GetActivityByID(ctx, activityUser.ActivityID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return fail("Failed to get activity for export", err)
+		}
+
+		fee := activityUser.FinalFee
+		out := exportActivity{
+			ActivityID: activity.ActivityID,
+			Name:       activity.Name,
+			StartDate:  activity.StartDate,
+			EndDate:    activity.EndDate,
+			FinalFee:   &fee,
+			CreatedAt:  activityUser.CreatedAt,
+		}
+		out.Route, err = exportRoute("activity-"+activity.ActivityID, activityUser.RouteID)
+		if err != nil {
+			return fail("Failed to export route of activity", err)
+		}
+
+		joined = append(joined, out)
+	}
+
+	createdActivities, err := dao.GetActivitiesByCreatorID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get created activities for export", err)
+	}
+	created := make([]exportActivity, 0, len(createdActivities))
+	for _, activity := range createdActivities {
+		created = append(created, exportActivity{
+			ActivityID: activity.ActivityID,
+			Name:       activity.Name,
+			StartDate:  activity.StartDate,
+			EndDate:    activity.EndDate,
+			CreatedAt:  activity.CreatedAt,
+		})
+	}
+	files["activities.json"] = map[string][]exportActivity{
+		"joined":  joined,
+		"created": created,
+	}
+
+	memberships, err := dao.GetMembershipHistory(ctx, userID)
+	if err != nil {
+		return fail("Failed to get membership history for export", err)
+	}
+	files["membership.json"] = memberships
+
+	credits, err := dao.GetCreditLedger(ctx, userID)
+	if err != nil {
+		return fail("Failed to get credit ledger for export", err)
+	}
+	files["credits.json"] = credits
+
+	// notifications
+	notifications, err := dao.GetNotificationsByUserID(ctx, userID)
+	if err != nil {
+		return fail("Failed to get notifications for export", err)
+	}
+	files["notifications.json"] = notifications
+
+	return &sdto.ExportDataOutput{
+		FileName: fmt.Sprintf("%s-export-%s.zip", user.Username, now.Format("20060102")),
+		WriteTo: func(ctx context.Context, w io.Writer) error {
+			return writeExport(ctx, w, files, routes, media)
+		},
+	}, nil
+}
+
+func writeExport(ctx context.Context, w io.Writer, files map[string]any, routes map[string][]byte, media []exportMedia) error {
+	archive := zip.NewWriter(w)
+
+	for name, content := range files {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(content); err != nil {
+			return err
+		}
+	}
+
+	for name, data := range routes {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range media {
+		object, err := minio.GetObject(ctx, m.bucket, m.object)
+		if err != nil {
+			// a missing object must not break the whole export
+			zlog.Warn("Media missing from export", zap.String("bucket", m.bucket), zap.String("object", m.object), zap.Error(err))
+			continue
+		}
+
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: m.name, Method: zip.Store})
+		if err == nil {
+			_, err = io.Copy(f, object)
+		}
+		object.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,177 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	"api.backend.xjco2913/util"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/mailer"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	resetTokenDuration = 30 * time.Minute
+
+	// pwdReset:<tokenHash> => userID, pwdResetUser:<userID> => tokenHash of the outstanding token
+	resetTokenKeyFmt     = "pwdReset:%s"
+	resetTokenUserKeyFmt = "pwdResetUser:%s"
+)
+
+// ChangePassword verifies the old password before setting the new one.
+// Every other session of the user is logged out, the current one is kept.
+func (s *UserService) ChangePassword(ctx context.Context, userID string, currentSessionID string, oldPassword string, newPassword string) *errorx.ServiceErr {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	if !util.VerifyPassword(user.Password, oldPassword) {
+		return errorx.NewServicerErr(errorx.ErrExternal, "Wrong old password", nil)
+	}
+
+	if oldPassword == newPassword {
+		return errorx.NewServicerErr(errorx.ErrExternal, "New password must be different from the old one", nil)
+	}
+
+	if sErr := s.setPassword(ctx, userID, newPassword); sErr != nil {
+		return sErr
+	}
+
+	return token.Service().RevokeOthers(ctx, userID, currentSessionID)
+}
+
+// ForgotPassword mails a single use reset token to the verified email of the user.
+// account is either a username or an email. It reports success for unknown accounts as well
+// so that it cannot be used to probe them.
+func (s *UserService) ForgotPassword(ctx context.Context, account string) *errorx.ServiceErr {
+	var user *model.User
+	var err error
+	if strings.Contains(account, "@") {
+		user, err = dao.FindUserByEmail(ctx, NormalizeEmail(account))
+	} else {
+		user, err = dao.FindUserByUsername(ctx, account)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			zlog.Info("Password reset requested for unknown account", zap.String("account", account))
+			return nil
+		}
+
+		zlog.Error("Error while finding user by account", zap.String("account", account), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	// an unverified address may belong to someone else
+	if user.Email == nil || !user.EmailVerified {
+		zlog.Warn("Password reset requested for user without verified email", zap.String("userID", user.UserID))
+		return nil
+	}
+
+	resetToken, err := util.RandomToken(32)
+	if err != nil {
+		zlog.Error("Error while generating reset token", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	tokenHash := util.HashToken(resetToken)
+	userKey := fmt.Sprintf(resetTokenUserKeyFmt, user.UserID)
+
+	// Only the latest token stays valid
+	previousHash, err := redis.RDB().Get(ctx, userKey).Result()
+	if err != nil && err != redis.KEY_NOT_FOUND {
+		zlog.Error("Error while getting previous reset token", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	pipe := redis.RDB().TxPipeline()
+	if err == nil {
+		pipe.Del(ctx, fmt.Sprintf(resetTokenKeyFmt, previousHash))
+	}
+	pipe.Set(ctx, fmt.Sprintf(resetTokenKeyFmt, tokenHash), user.UserID, resetTokenDuration)
+	pipe.Set(ctx, userKey, tokenHash, resetTokenDuration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		zlog.Error("Error while storing reset token", zap.String("userID", user.UserID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	err = mailer.Default().Send(ctx, &mailer.Message{
+		To:      *user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password, it expires in %d minutes:\n\n%s\n\nIf you did not ask for it, just ignore this mail.\n",
+			user.Username,
+			int(resetTokenDuration.Minutes()),
+			fmt.Sprintf(config.Get("mail.resetPasswordUrl"), resetToken),
+		),
+	})
+	if err != nil {
+		zlog.Error("Error while sending reset password mail", zap.String("userID", user.UserID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and logs the user out everywhere
+func (s *UserService) ResetPassword(ctx context.Context, resetToken string, newPassword string) *errorx.ServiceErr {
+	// GETDEL makes the token single use even under concurrent requests
+	userID, err := redis.RDB().GetDel(ctx, fmt.Sprintf(resetTokenKeyFmt, util.HashToken(resetToken))).Result()
+	if err != nil {
+		if err == redis.KEY_NOT_FOUND {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Invalid or expired reset token", nil)
+		}
+
+		zlog.Error("Error while consuming reset token", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+	redis.RDB().Del(ctx, fmt.Sprintf(resetTokenUserKeyFmt, userID))
+
+	if sErr := s.setPassword(ctx, userID, newPassword); sErr != nil {
+		return sErr
+	}
+
+	// The account may have been locked by whoever guessed at the old password
+	user, err := dao.GetUserByID(ctx, userID)
+	if err == nil {
+		redis.RDB().Del(ctx, fmt.Sprintf("WrongPwd:%s", user.Username), fmt.Sprintf("lock:%s", user.Username))
+	}
+
+	return token.Service().RevokeAll(ctx, userID)
+}
+
+func (s *UserService) setPassword(ctx context.Context, userID string, password string) *errorx.ServiceErr {
+	hashPwd, err := util.EncryptPassword(password)
+	if err != nil {
+		zlog.Error("Error while encrypt password", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	err = dao.UpdateUserByID(ctx, userID, map[string]interface{}{
+		"password": hashPwd,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+
+		zlog.Error("Failed to update password", zap.String("userID", userID), zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"api.backend.xjco2913/dao"
+	"api.backend.xjco2913/dao/model"
+	"api.backend.xjco2913/service/notify"
+	"api.backend.xjco2913/service/sdto"
+	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Referral codes are 8 characters of base32, users created before the referral programme
+// get theirs the first time it is read
+const referralCodeBytes = 5
+
+// RewardReferral credits both sides of a referral when the referred user makes the first
+// payment, a subscription once it can no longer be refunded or a charged activity fee. Later
+// payments change nothing.
+func (s *UserService) RewardReferral(ctx context.Context, userID string) {
+	referrerAmount, err1 := strconv.Atoi(config.Get("referral.reward.referrer"))
+	referredAmount, err2 := strconv.Atoi(config.Get("referral.reward.referred"))
+	if err := errors.Join(err1, err2); err != nil {
+		zlog.Error("Invalid referral rewards in config", zap.Error(err))
+		return
+	}
+
+	rewarded, err := dao.RewardReferral(ctx, userID, int32(referrerAmount), int32(referredAmount))
+	if err != nil {
+		zlog.Error("Failed to reward referral", zap.String("userID", userID), zap.Error(err))
+		return
+	}
+	if !rewarded {
+		return
+	}
+
+	zlog.Info("Referral rewarded", zap.String("userID", userID))
+
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil || user.ReferredBy == nil {
+		return
+	}
+	notify.Service().System(ctx, userID, fmt.Sprintf("You earned %d credits for joining through a referral.", referredAmount))
+	notify.Service().System(ctx, *user.ReferredBy, fmt.Sprintf("You earned %d credits, %s joined through your referral.", referrerAmount, user.Username))
+}
+
+// Credits returns the balance and every change of it
+func (s *UserService) Credits(ctx context.Context, userID string) (*sdto.CreditsOutput, *errorx.ServiceErr) {
+	user, err := dao.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
+		}
+		zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	referralCode, err := s.referralCode(ctx, user)
+	if err != nil {
+		zlog.Error("Failed to get referral code", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	entries, err := dao.GetCreditLedger(ctx, userID)
+	if err != nil {
+		zlog.Error("Failed to get credit ledger", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
+	}
+
+	res := &sdto.CreditsOutput{
+		Balance:      user.Credits,
+		ReferralCode: referralCode,
+		Entries:      make([]*sdto.CreditEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		record := &sdto.CreditEntry{
+			Amount: entry.Amount,
+			Reason: entry.Reason,
+			RefID:  entry.RefID,
+		}
+		if entry.CreatedAt != nil {
+			record.CreatedAt = entry.CreatedAt.Unix()
+		}
+		res.Entries = append(res.Entries, record)
+	}
+
+	return res, nil
+}
+
+// referrerOf returns the user who owns referralCode
+func (s *UserService) referrerOf(ctx context.Context, referralCode string) (string, *errorx.ServiceErr) {
+	referrer, err := dao.GetUserByReferralCode(ctx, normalizeReferralCode(referralCode))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errorx.NewServicerErr(errorx.ErrExternal, "Invalid referral code", nil)
+		}
+		zlog.Error("Failed to get user by referral code", zap.Error(err))
+		return "", errorx.NewInternalErr()
+	}
+
+	return referrer.UserID, nil
+}
+
+// referralCode returns the referral code of the user, giving it one if it has none yet
+func (s *UserService) referralCode(ctx context.Context, user *model.User) (string, error) {
+	if user.ReferralCode != nil {
+		return *user.ReferralCode, nil
+	}
+
+	code, err := newReferralCode()
+	if err != nil {
+		return "", err
+	}
+
+	set, err := dao.SetReferralCode(ctx, user.UserID, code)
+	if err != nil {
+		return "", err
+	}
+	if !set {
+		// set by a concurrent request
+		current, err := dao.GetUserByID(ctx, user.UserID)
+		if err != nil {
+			return "", err
+		}
+		if current.ReferralCode == nil {
+			return "", errors.New("referral code not set")
+		}
+		code = *current.ReferralCode
+	}
+
+	user.ReferralCode = &code
+	return code, nil
+}
+
+func newReferralCode() (string, error) {
+	b := make([]byte, referralCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+func normalizeReferralCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
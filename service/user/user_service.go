@@ -14,11 +14,13 @@ import (
 	"api.backend.xjco2913/dao/minio"
 	"api.backend.xjco2913/dao/model"
 	"api.backend.xjco2913/dao/redis"
+	"api.backend.xjco2913/service/privacy"
 	"api.backend.xjco2913/service/sdto"
 	"api.backend.xjco2913/service/sdto/errorx"
+	"api.backend.xjco2913/service/token"
+	"api.backend.xjco2913/service/twofactor"
 	"api.backend.xjco2913/util"
 	"api.backend.xjco2913/util/zlog"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -50,6 +52,11 @@ func (u *UserService) Create(ctx context.Context, in *sdto.CreateUserInput) *err
 		)
 	}
 
+	email := NormalizeEmail(in.Email)
+	if sErr := u.checkEmailAvailable(ctx, email, ""); sErr != nil {
+		return sErr
+	}
+
 	// Generate uuid for userID
 	uuid, err := uuid.NewUUID()
 	if err != nil {
@@ -58,6 +65,21 @@ func (u *UserService) Create(ctx context.Context, in *sdto.CreateUserInput) *err
 	}
 	newUserID := uuid.String()
 
+	var referredBy *string
+	if !util.IsEmpty(in.ReferralCode) {
+		referrerID, sErr := u.referrerOf(ctx, in.ReferralCode)
+		if sErr != nil {
+			return sErr
+		}
+		referredBy = &referrerID
+	}
+
+	referralCode, err := newReferralCode()
+	if err != nil {
+		zlog.Error("Error while generating referral code", zap.Error(err))
+		return errorx.NewInternalErr()
+	}
+
 	// Parse birthday
 	var birthdayEntity *time.Time = nil
 	if !util.IsEmpty(in.Birthday) {
@@ -82,7 +104,7 @@ func (u *UserService) Create(ctx context.Context, in *sdto.CreateUserInput) *err
 	}
 
 	// DB logic to create new user
-	err = dao.CreateNewUser(ctx, &model.User{
+	newUser := &model.User{
 		UserID:         newUserID,
 		AvatarURL:      nil,
 		MembershipTime: time.Now().Unix(),
@@ -92,12 +114,21 @@ func (u *UserService) Create(ctx context.Context, in *sdto.CreateUserInput) *err
 		Birthday:       birthdayEntity,
 		Username:       in.Username,
 		Password:       hashPwd,
-	})
+		Email:          &email,
+		ReferralCode:   &referralCode,
+		ReferredBy:     referredBy,
+	}
+	err = dao.CreateNewUser(ctx, newUser)
 	if err != nil {
 		zlog.Error("Error while create new user: "+err.Error(), zap.String("username", in.Username))
 		return errorx.NewInternalErr()
 	}
 
+	// The account is usable right away, a failed mail can be resent later
+	if sErr := u.sendVerificationMail(ctx, newUser); sErr != nil {
+		zlog.Warn("Verification mail not sent on sign up", zap.String("userID", newUserID))
+	}
+
 	return nil
 }
 
@@ -119,7 +150,7 @@ func (u *UserService) Authenticate(ctx context.Context, in *sdto.AuthenticateInp
 	// Check if the user is banned
 	if u.IsBanned(ctx, user.UserID) {
 		zlog.Warn("Attempted login by banned user", zap.String("userID", user.UserID))
-		return nil, errorx.NewServicerErr(errorx.ErrExternal, "account is banned", nil)
+		return nil, u.BannedErr(ctx, user.UserID)
 	}
 
 	attemptKey := fmt.Sprintf("WrongPwd:%s", in.Username)
@@ -183,49 +214,20 @@ func (u *UserService) Authenticate(ctx context.Context, in *sdto.AuthenticateInp
 		}
 	}
 
-	// First try to get jwt cache from redis
-	// Key format => jwt:username
-	cacheTokenKey := fmt.Sprintf("jwt:%v", in.Username)
-	cachedToken, err := redis.RDB().Get(ctx, cacheTokenKey).Result()
-	if err != nil && err != redis.KEY_NOT_FOUND {
-		// error occur
-		zlog.Error("Failed to get cached token", zap.Error(err), zap.String("cachedTokenKey", cacheTokenKey))
-		return nil, errorx.NewInternalErr()
+	challenge, sErr := twofactor.Service().BeginLogin(ctx, user.UserID, false, &in.Device)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if challenge != nil {
+		return &sdto.AuthenticateOutput{
+			UserID:    user.UserID,
+			Challenge: challenge,
+		}, nil
 	}
 
-	// If exist cached token, return it immediately
-	var tokenStr string
-	if err != redis.KEY_NOT_FOUND {
-		tokenStr = cachedToken
-	} else {
-		organiser, err := dao.GetOrganiserByID(ctx, user.UserID)
-		isOrganiser := false
-		if err == nil && organiser != nil && organiser.Status == 2 {
-			isOrganiser = true
-		}
-
-		// If not exist cached token, prepare claims for new token
-		claims := jwt.MapClaims{
-			"userID":         user.UserID,
-			"isAdmin":        false,
-			"exp":            time.Now().Add(24 * time.Hour).Unix(),
-			"isOrganiser":    isOrganiser,
-			"membershipType": user.MembershipType,
-		}
-
-		// Generate new token
-		tokenStr, err = util.GenerateJWTToken(claims)
-		if err != nil {
-			zlog.Error("Error while generating jwt", zap.Error(err))
-			return nil, errorx.NewInternalErr()
-		}
-
-		// Store the token into cache
-		err = redis.RDB().Set(ctx, cacheTokenKey, tokenStr, 24*time.Hour).Err()
-		if err != nil {
-			zlog.Error("Failed to store token into cache", zap.Error(err))
-			return nil, errorx.NewInternalErr()
-		}
+	tokens, sErr := token.Service().Issue(ctx, user.UserID, false, &in.Device)
+	if sErr != nil {
+		return nil, sErr
 	}
 
 	var birthdayStr string
@@ -243,12 +245,14 @@ func (u *UserService) Authenticate(ctx context.Context, in *sdto.AuthenticateInp
 	}
 
 	return &sdto.AuthenticateOutput{
-		Token:     tokenStr,
-		UserID:    user.UserID,
-		Gender:    user.Gender,
-		Birthday:  birthdayStr,
-		Region:    user.Region,
-		AvatarUrl: avatarUrl,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		UserID:       user.UserID,
+		Gender:       user.Gender,
+		Birthday:     birthdayStr,
+		Region:       user.Region,
+		AvatarUrl:    avatarUrl,
 	}, nil
 }
 
@@ -326,30 +330,28 @@ func (s *UserService) GetAll(ctx context.Context) ([]*sdto.GetAllOutput, *errorx
 	return userDtos, nil
 }
 
-func (s *UserService) GetByID(ctx context.Context, userID string) (*sdto.GetByIDOutput, *errorx.ServiceErr) {
-	user, err := dao.GetUserByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			zlog.Warn("User not found", zap.String("userID", userID))
-			return nil, errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
-		} else {
-			zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
-			return nil, errorx.NewInternalErr()
-		}
+// GetByID returns the profile of the user as far as the privacy settings let the viewer see it,
+// only the user and admins get the account details
+func (s *UserService) GetByID(ctx context.Context, viewer *sdto.Viewer, userID string) (*sdto.GetByIDOutput, *errorx.ServiceErr) {
+	user, sErr := privacy.Service().CanViewByID(ctx, viewer, userID)
+	if sErr != nil {
+		return nil, sErr
 	}
+	self := privacy.IsSelf(viewer, user)
 
 	var birthday string
-	if user.Birthday != nil {
+	if user.Birthday != nil && (self || !user.HideBirthday) {
 		birthday = user.Birthday.Format(time.RFC822Z)
 	}
 
 	// get avatar url from minio
 	avatarURL := ""
 	if user.AvatarURL != nil || !util.IsEmpty(user.AvatarURL) {
-		avatarURL, err = minio.GetUserAvatarUrl(ctx, *user.AvatarURL)
+		url, err := minio.GetUserAvatarUrl(ctx, *user.AvatarURL)
 		if err != nil {
 			return nil, errorx.NewInternalErr()
 		}
+		avatarURL = url
 	}
 
 	isOrganiserExist := true
@@ -364,75 +366,54 @@ func (s *UserService) GetByID(ctx context.Context, userID string) (*sdto.GetByID
 	}
 
 	userDto := &sdto.GetByIDOutput{
-		UserID:         user.UserID,
-		Username:       user.Username,
-		Gender:         user.Gender,
-		Birthday:       birthday,
-		Region:         user.Region,
-		MembershipTime: user.MembershipTime,
-		AvatarURL:      avatarURL,
-		IsOrganiser:    isOrganiserExist && organiser.Status == 2,
-		MembershipType: user.MembershipType,
+		UserID:      user.UserID,
+		Username:    user.Username,
+		Gender:      user.Gender,
+		Birthday:    birthday,
+		Region:      user.Region,
+		AvatarURL:   avatarURL,
+		IsOrganiser: isOrganiserExist && organiser.Status == 2,
+		Restricted:  !self,
 	}
-
-	return userDto, nil
-}
-
-func (s *UserService) DeleteByID(ctx context.Context, userIDs string) *errorx.ServiceErr {
-	ids := strings.Split(userIDs, "|")
-	deletedIDs, notFoundIDs, err := dao.DeleteUsersByID(ctx, userIDs)
-
-	if err != nil {
-		zlog.Error("Failed to delete users", zap.Error(err))
-		return errorx.NewInternalErr()
+	if !self {
+		return userDto, nil
 	}
 
-	// All specified users were not found
-	if len(notFoundIDs) == len(ids) {
-		zlog.Warn("All specified users not found", zap.Strings("not_found_ids", notFoundIDs))
-		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users not found", map[string]any{"not_found_ids": notFoundIDs})
+	referralCode, err := s.referralCode(ctx, user)
+	if err != nil {
+		zlog.Error("Failed to get referral code", zap.String("userID", userID), zap.Error(err))
+		return nil, errorx.NewInternalErr()
 	}
 
-	zlog.Info("Specified users deleted", zap.Strings("deleted_user_ids", deletedIDs))
-	// Part of specified users were not found
-	if len(notFoundIDs) > 0 {
-		zlog.Warn("Some specified users not found", zap.Strings("not_found_ids", notFoundIDs))
+	userDto.MembershipTime = user.MembershipTime
+	userDto.MembershipType = user.MembershipType
+	userDto.EmailVerified = user.EmailVerified
+	userDto.Credits = user.Credits
+	userDto.ReferralCode = referralCode
+	if user.Email != nil {
+		userDto.Email = *user.Email
 	}
 
-	return nil
+	return userDto, nil
 }
 
-func (s *UserService) BanByID(ctx context.Context, userIDs string) *errorx.ServiceErr {
+// DeleteByID schedules the deletion of every account found, see RequestDeletion
+func (s *UserService) DeleteByID(ctx context.Context, userIDs string, adminID string) *errorx.ServiceErr {
 	ids := strings.Split(userIDs, "|")
-	var bannedIDs []string
+	var scheduledIDs []string
 	var notFoundIDs []string
-	var alreadyBannedIDs []string
 
 	for _, id := range ids {
-		_, err := dao.GetUserByID(ctx, id)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, sErr := s.RequestDeletion(ctx, id, adminID)
+		if sErr != nil {
+			if sErr.Code() == errorx.ErrExternal {
 				notFoundIDs = append(notFoundIDs, id)
 				continue
-			} else {
-				zlog.Error("Failed to retrieve user by ID", zap.String("userID", id), zap.Error(err))
-				return errorx.NewInternalErr()
 			}
+			return sErr
 		}
 
-		if s.IsBanned(ctx, id) {
-			alreadyBannedIDs = append(alreadyBannedIDs, id)
-			continue
-		}
-
-		banKey := fmt.Sprintf("ban:%s", id)
-		// Disable never expire
-		err = redis.RDB().Set(ctx, banKey, "banned", 0).Err()
-		if err != nil {
-			zlog.Error("Failed to ban user", zap.String("userID", id), zap.Error(err))
-			return errorx.NewInternalErr()
-		}
-		bannedIDs = append(bannedIDs, id)
+		scheduledIDs = append(scheduledIDs, id)
 	}
 
 	// All specified users were not found
@@ -441,121 +422,15 @@ func (s *UserService) BanByID(ctx context.Context, userIDs string) *errorx.Servi
 		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users not found", map[string]any{"not_found_ids": notFoundIDs})
 	}
 
-	// All specified users were already banned
-	if len(alreadyBannedIDs) == len(ids) {
-		zlog.Warn("All specified users already banned", zap.Strings("already_banned_ids", alreadyBannedIDs))
-		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users already banned", map[string]any{"already_banned_ids": alreadyBannedIDs})
-	}
-
-	zlog.Info("Specified users banned", zap.Strings("banned_user_ids", bannedIDs))
+	zlog.Info("Deletion of specified users scheduled", zap.Strings("scheduled_user_ids", scheduledIDs))
 	// Part of specified users were not found
 	if len(notFoundIDs) > 0 {
 		zlog.Warn("Some specified users not found", zap.Strings("not_found_ids", notFoundIDs))
 	}
 
-	// Part of specified users were already banned
-	if len(alreadyBannedIDs) > 0 {
-		zlog.Warn("Some specified users already banned", zap.Strings("already_banned_ids", alreadyBannedIDs))
-	}
-
 	return nil
 }
 
-func (s *UserService) UnbanByID(ctx context.Context, userIDs string) *errorx.ServiceErr {
-	ids := strings.Split(userIDs, "|")
-	var unbannedIDs []string
-	var notFoundIDs []string
-	var notBannedIDs []string
-
-	for _, id := range ids {
-		_, err := dao.GetUserByID(ctx, id)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				notFoundIDs = append(notFoundIDs, id)
-				continue
-			} else {
-				zlog.Error("Failed to retrieve user by ID", zap.String("userID", id), zap.Error(err))
-				return errorx.NewInternalErr()
-			}
-		}
-
-		if !s.IsBanned(ctx, id) {
-			notBannedIDs = append(notBannedIDs, id)
-			continue
-		}
-
-		banKey := fmt.Sprintf("ban:%s", id)
-		err = redis.RDB().Del(ctx, banKey).Err()
-		if err != nil {
-			zlog.Error("Failed to unban user", zap.String("userID", id), zap.Error(err))
-			return errorx.NewInternalErr()
-		}
-		unbannedIDs = append(unbannedIDs, id)
-	}
-
-	// All specified users were not found
-	if len(notFoundIDs) == len(ids) {
-		zlog.Warn("All specified users not found", zap.Strings("not_found_ids", notFoundIDs))
-		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users not found", map[string]any{"not_found_ids": notFoundIDs})
-	}
-
-	// All specified users were not banned
-	if len(notBannedIDs) == len(ids) {
-		zlog.Warn("All specified users were not banned", zap.Strings("not_banned_ids", notBannedIDs))
-		return errorx.NewServicerErr(errorx.ErrExternal, "All specified users were not banned", map[string]any{"not_banned_ids": notBannedIDs})
-	}
-
-	zlog.Info("Specified users unbanned", zap.Strings("unbanned_user_ids", unbannedIDs))
-	// Part of specified users were not found
-	if len(notFoundIDs) > 0 {
-		zlog.Warn("Some specified users not found", zap.Strings("not_found_ids", notFoundIDs))
-	}
-
-	// Part of specified users were not banned
-	if len(notBannedIDs) > 0 {
-		zlog.Warn("Some specified users were not banned", zap.Strings("not_banned_ids", notBannedIDs))
-	}
-
-	return nil
-}
-
-func (s *UserService) IsBanned(ctx context.Context, userID string) bool {
-	_, err := dao.GetUserByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			zlog.Warn("User not found", zap.String("userID", userID))
-		} else {
-			zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
-		}
-		return false
-	}
-
-	banKey := fmt.Sprintf("ban:%s", userID)
-	exists, err := redis.RDB().Exists(ctx, banKey).Result()
-	if err != nil || exists == 0 {
-		return false
-	}
-	return true
-}
-
-func (s *UserService) GetAllStatus(ctx context.Context) ([]*sdto.GetAllStatusOutput, *errorx.ServiceErr) {
-	users, err := dao.GetAllUsers(ctx)
-	if err != nil {
-		return nil, errorx.NewInternalErr()
-	}
-
-	var statusList []*sdto.GetAllStatusOutput
-	for _, user := range users {
-		isBanned := s.IsBanned(ctx, user.UserID)
-		statusList = append(statusList, &sdto.GetAllStatusOutput{
-			UserID:   user.UserID,
-			IsBanned: isBanned,
-		})
-	}
-
-	return statusList, nil
-}
-
 func (s *UserService) UpdateByID(ctx context.Context, userID string, input sdto.UpdateUserInput) *errorx.ServiceErr {
 	updates := make(map[string]interface{})
 
@@ -572,6 +447,26 @@ func (s *UserService) UpdateByID(ctx context.Context, userID string, input sdto.
 	addUpdate("username", input.Username)
 	addUpdate("gender", input.Gender)
 	addUpdate("region", input.Region)
+	if input.Email != nil {
+		email := NormalizeEmail(*input.Email)
+		if util.IsEmpty(email) {
+			return errorx.NewServicerErr(errorx.ErrExternal, "Email cannot be removed", nil)
+		}
+		if sErr := s.checkEmailAvailable(ctx, email, userID); sErr != nil {
+			return sErr
+		}
+
+		// a new address has to be verified again
+		user, err := dao.GetUserByID(ctx, userID)
+		if err != nil {
+			zlog.Error("Error while get user by id", zap.String("userID", userID), zap.Error(err))
+			return errorx.NewInternalErr()
+		}
+		if user.Email == nil || *user.Email != email {
+			updates["email"] = email
+			updates["emailVerified"] = false
+		}
+	}
 	if input.Birthday != nil {
 		if *input.Birthday == "" {
 			addUpdate("birthday", nil)
@@ -601,77 +496,8 @@ func (s *UserService) UpdateByID(ctx context.Context, userID string, input sdto.
 		}
 	}
 
-	return nil
-}
-
-func (s *UserService) Subscribe(ctx context.Context, userID string, membershipType int) *errorx.ServiceErr {
-	user, err := dao.GetUserByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			zlog.Warn("User not found", zap.String("userID", userID))
-			return errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
-		} else {
-			zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
-			return errorx.NewInternalErr()
-		}
-	}
-
-	if user.MembershipType != 0 {
-		zlog.Warn("User has already subscribed", zap.String("userID", userID))
-		return errorx.NewServicerErr(errorx.ErrExternal, "User has already subscribed", nil)
-	}
-
-	newExpiration := time.Now().Unix() + 30*24*60*60
-
-	updates := map[string]interface{}{
-		"membershipTime": newExpiration,
-		"membershipType": membershipType,
-	}
-
-	err = dao.UpdateUserByID(ctx, userID, updates)
-	if err != nil {
-		zlog.Error("Failed to update user", zap.String("userID", userID), zap.Any("updates", updates), zap.Error(err))
-		return errorx.NewInternalErr()
-	}
-
-	return nil
-}
-
-func (s *UserService) CancelByID(ctx context.Context, userID string) *errorx.ServiceErr {
-	user, err := dao.GetUserByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			zlog.Warn("User not found", zap.String("userID", userID))
-			return errorx.NewServicerErr(errorx.ErrExternal, "User not found", nil)
-		} else {
-			zlog.Error("Failed to retrieve user by ID", zap.String("userID", userID), zap.Error(err))
-			return errorx.NewInternalErr()
-		}
-	}
-
-	if user.MembershipType == 0 {
-		zlog.Error("User has not subscribed", zap.String("userID", userID))
-		return errorx.NewServicerErr(errorx.ErrExternal, "User has not subscribed", nil)
-	}
-
-	// No-reason refund (7 days after subscription start date)
-	cancellationDeadline := user.MembershipTime - 23*24*60*60
-
-	// Check if the current time is before the cancellation deadline
-	if time.Now().Unix() > cancellationDeadline {
-		zlog.Error("Cancellation period has expired", zap.String("userID", userID))
-		return errorx.NewServicerErr(errorx.ErrExternal, "Cancellation period has expired", nil)
-	}
-
-	updates := map[string]interface{}{
-		"membershipType": 0,
-		"membershipTime": 0,
-	}
-
-	err = dao.UpdateUserByID(ctx, userID, updates)
-	if err != nil {
-		zlog.Error("Failed to update user", zap.String("userID", userID), zap.Any("updates", updates), zap.Error(err))
-		return errorx.NewInternalErr()
+	if _, ok := updates["email"]; ok {
+		return s.SendVerification(ctx, userID)
 	}
 
 	return nil
@@ -718,54 +544,35 @@ func (s *UserService) UploadAvatar(ctx context.Context, in sdto.UploadAvatarInpu
 	return nil
 }
 
-func (s *UserService) RefreshToken(ctx context.Context, userID string) (*sdto.RefreshTokenOutput, *errorx.ServiceErr) {
-	user, err := dao.GetUserByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errorx.NewServicerErr(400, "User not found", nil)
-		}
-
-		zlog.Error("Error while find user by userID", zap.Error(err), zap.String("userID", userID))
-		return nil, errorx.NewInternalErr()
+func (s *UserService) RefreshToken(ctx context.Context, refreshToken string, device *sdto.SessionDevice) (*sdto.RefreshTokenOutput, *errorx.ServiceErr) {
+	tokens, sErr := token.Service().Refresh(ctx, refreshToken, false, device)
+	if sErr != nil {
+		return nil, sErr
 	}
 
-	// delete cache
-	cacheKey := fmt.Sprintf("jwt:%v", user.Username)
-	err = redis.RDB().Del(ctx, cacheKey).Err()
-	if err != nil {
-		zlog.Error("Error while delete jwt cache", zap.Error(err))
-		return nil, errorx.NewInternalErr()
-	}
+	return &sdto.RefreshTokenOutput{
+		NewToken:        tokens.AccessToken,
+		NewRefreshToken: tokens.RefreshToken,
+		ExpiresAt:       tokens.ExpiresAt,
+	}, nil
+}
 
-	// generate new token
-	organiser, err := dao.GetOrganiserByID(ctx, user.UserID)
-	isOrganiser := false
-	if err == nil && organiser != nil {
-		isOrganiser = true
-	}
+// Logout revokes the session the request was made with
+func (s *UserService) Logout(ctx context.Context, userID string, sessionID string, tokenID string, tokenExp time.Time) *errorx.ServiceErr {
+	return token.Service().Logout(ctx, userID, sessionID, tokenID, tokenExp)
+}
 
-	claims := jwt.MapClaims{
-		"userID":         user.UserID,
-		"isAdmin":        false,
-		"exp":            time.Now().Add(24 * time.Hour).Unix(),
-		"isOrganiser":    isOrganiser,
-		"membershipType": user.MembershipType,
-	}
-	newToken, err := util.GenerateJWTToken(claims)
-	if err != nil {
-		zlog.Error("Error while generate new jwt token", zap.Error(err))
-		return nil, errorx.NewInternalErr()
-	}
+// LogoutAll revokes every session of the user
+func (s *UserService) LogoutAll(ctx context.Context, userID string) *errorx.ServiceErr {
+	return token.Service().RevokeAll(ctx, userID)
+}
 
-	err = redis.RDB().Set(ctx, cacheKey, newToken, 24*time.Hour).Err()
-	if err != nil {
-		zlog.Error("Error while set jwt cache", zap.Error(err))
-		return nil, errorx.NewInternalErr()
-	}
+func (s *UserService) GetSessions(ctx context.Context, userID string, currentSessionID string) ([]*sdto.Session, *errorx.ServiceErr) {
+	return token.Service().ListSessions(ctx, userID, currentSessionID)
+}
 
-	return &sdto.RefreshTokenOutput{
-		NewToken: newToken,
-	}, nil
+func (s *UserService) RevokeSession(ctx context.Context, userID string, sessionID string) *errorx.ServiceErr {
+	return token.Service().RevokeSession(ctx, userID, sessionID)
 }
 
 func (s *UserService) MockUserList(ctx context.Context) (*sdto.MockUserListOutput, *errorx.ServiceErr) {
@@ -1,6 +1,10 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"sort"
+
+	"github.com/spf13/viper"
+)
 
 var (
 	localConfig *viper.Viper
@@ -28,3 +32,16 @@ func init() {
 func Get(key string) string {
 	return localConfig.GetString(key)
 }
+
+// Keys returns the sorted names of the children of key, e.g. the providers under oidc.providers
+func Keys(key string) []string {
+	children := localConfig.GetStringMap(key)
+
+	keys := make([]string, 0, len(children))
+	for k := range children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
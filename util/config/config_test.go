@@ -19,4 +19,18 @@ func TestConfig(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestKeys(t *testing.T) {
+	expected := []string{"minio", "mysql", "redis"}
+
+	actual := Keys("database")
+	if len(actual) != len(expected) {
+		t.Fatalf("Keys(database) = %v; expected %v", actual, expected)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Keys(database) = %v; expected %v", actual, expected)
+		}
+	}
+}
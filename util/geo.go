@@ -0,0 +1,91 @@
+package util
+
+import (
+	"math"
+	"strconv"
+)
+
+const earthRadius = 6371000.0
+
+// Zone is a circle on the map, radius is in meters
+type Zone struct {
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+}
+
+// Distance returns the great-circle distance in meters between two points
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+func (z Zone) Contains(lat, lon float64) bool {
+	return Distance(z.Latitude, z.Longitude, lat, lon) <= z.Radius
+}
+
+// TrimRoute leaves out the points of a [[lon, lat]...] route that are inside any of zones,
+// points that cannot be parsed are left out as well
+func TrimRoute(route [][]string, zones []Zone) [][]string {
+	if len(zones) == 0 {
+		return route
+	}
+
+	res := [][]string{}
+	for _, point := range route {
+		if len(point) < 2 {
+			continue
+		}
+		lon, err1 := strconv.ParseFloat(point[0], 64)
+		lat, err2 := strconv.ParseFloat(point[1], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		inside := false
+		for _, zone := range zones {
+			if zone.Contains(lat, lon) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			res = append(res, point)
+		}
+	}
+
+	return res
+}
+
+// RouteLength returns the length in meters of a [[lon, lat]...] route,
+// points that cannot be parsed are skipped
+func RouteLength(route [][]string) float64 {
+	var (
+		length           float64
+		prevLat, prevLon float64
+		hasPrev          bool
+	)
+	for _, point := range route {
+		if len(point) < 2 {
+			continue
+		}
+		lon, err1 := strconv.ParseFloat(point[0], 64)
+		lat, err2 := strconv.ParseFloat(point[1], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		if hasPrev {
+			length += Distance(prevLat, prevLon, lat, lon)
+		}
+		prevLat, prevLon, hasPrev = lat, lon, true
+	}
+
+	return length
+}
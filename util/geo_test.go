@@ -0,0 +1,68 @@
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	// Leeds to York is about 36km
+	actual := Distance(53.8008, -1.5491, 53.9600, -1.0873)
+	if math.Abs(actual-35500) > 1000 {
+		t.Errorf("Distance(Leeds, York) = %v; expected about 35500", actual)
+	}
+
+	if actual := Distance(53.8008, -1.5491, 53.8008, -1.5491); actual != 0 {
+		t.Errorf("Distance to the same point = %v; expected 0", actual)
+	}
+}
+
+func TestTrimRoute(t *testing.T) {
+	route := [][]string{
+		{"-1.5491", "53.8008"},
+		{"-1.5480", "53.8010"},
+		{"-1.5300", "53.8100"},
+		{"-1.5100", "53.8200"},
+		{"-1.5090", "53.8202"},
+	}
+	home := Zone{Latitude: 53.8008, Longitude: -1.5491, Radius: 200}
+	work := Zone{Latitude: 53.8200, Longitude: -1.5100, Radius: 200}
+
+	testCases := []struct {
+		name     string
+		zones    []Zone
+		expected int
+	}{
+		{"no zones", nil, 5},
+		{"start", []Zone{home}, 3},
+		{"start and end", []Zone{home, work}, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := TrimRoute(route, tc.zones)
+			if len(actual) != tc.expected {
+				t.Errorf("TrimRoute kept %d points; expected %d", len(actual), tc.expected)
+			}
+		})
+	}
+
+	if actual := TrimRoute([][]string{{"x", "y"}, {"-1.5300"}}, []Zone{home}); len(actual) != 0 {
+		t.Errorf("TrimRoute kept invalid points: %v", actual)
+	}
+}
+
+func TestRouteLength(t *testing.T) {
+	route := [][]string{
+		{"-1.5491", "53.8008"},
+		{"x", "y"},
+		{"-1.0873", "53.9600"},
+	}
+	if actual := RouteLength(route); math.Abs(actual-35500) > 1000 {
+		t.Errorf("RouteLength = %v; expected about 35500", actual)
+	}
+
+	if actual := RouteLength([][]string{{"-1.5491", "53.8008"}}); actual != 0 {
+		t.Errorf("RouteLength of a single point = %v; expected 0", actual)
+	}
+}
@@ -3,7 +3,9 @@ package util
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tkrajina/gpxgo/gpx"
 )
@@ -37,6 +39,81 @@ func GPXToLonLat(gpxDataBytes []byte) ([]string, error) {
 	return res, nil
 }
 
+// RideSummary is what the statistics keep of a route, distances are in meters
+type RideSummary struct {
+	Distance      float64
+	ElevationGain float64
+	// zero if the gpx has no timestamps
+	StartTime time.Time
+	// fastest 10 km within a segment, zero if no segment is long enough or has timestamps
+	Best10K time.Duration
+}
+
+const splitDistance = 10000.0
+
+// GPXSummary measures the length and the climbing of every track in the gpx data.
+// Segments are not joined, the gap between two segments is not part of the ride.
+func GPXSummary(gpxDataBytes []byte) (*RideSummary, error) {
+	gpxHandler, err := gpx.ParseBytes(gpxDataBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &RideSummary{
+		StartTime: gpxHandler.TimeBounds().StartTime,
+	}
+	for _, track := range gpxHandler.Tracks {
+		for _, segment := range track.Segments {
+			for i := 1; i < len(segment.Points); i++ {
+				prev, point := segment.Points[i-1], segment.Points[i]
+
+				res.Distance += Distance(prev.Latitude, prev.Longitude, point.Latitude, point.Longitude)
+				if prev.Elevation.NotNull() && point.Elevation.NotNull() && point.Elevation.Value() > prev.Elevation.Value() {
+					res.ElevationGain += point.Elevation.Value() - prev.Elevation.Value()
+				}
+			}
+
+			if best := fastestSplit(segment.Points, splitDistance); best > 0 && (res.Best10K == 0 || best < res.Best10K) {
+				res.Best10K = best
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// fastestSplit returns the shortest time in which consecutive points cover distance,
+// zero if they never do or a point has no timestamp
+func fastestSplit(points []gpx.GPXPoint, distance float64) time.Duration {
+	covered := make([]float64, len(points))
+	for i := range points {
+		if points[i].Timestamp.IsZero() {
+			return 0
+		}
+		if i > 0 {
+			covered[i] = covered[i-1] + Distance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+		}
+	}
+
+	var best time.Duration
+	start := 0
+	for end := range points {
+		// the shortest stretch ending at end that still covers distance
+		for start+1 < end && covered[end]-covered[start+1] >= distance {
+			start++
+		}
+		if covered[end]-covered[start] < distance {
+			continue
+		}
+
+		if elapsed := points[end].Timestamp.Sub(points[start].Timestamp); elapsed > 0 && (best == 0 || elapsed < best) {
+			best = elapsed
+		}
+	}
+
+	return best
+}
+
 // Convert LINESTRING(x x, y y, z z,...) to x x, y y, z z,...
 func GPXRoute(linestring string) (string, error) {
 	re := regexp.MustCompile(`\((.*?)\)`)
@@ -74,4 +151,42 @@ func StrStrToGPXStr(gpxStrStr [][]string) []string {
 	}
 
 	return res
-}
\ No newline at end of file
+}
+
+// Convert LINESTRING(lon lat, lon lat,...) to a gpx document with a single track
+func LineStringToGPX(name string, linestring string) ([]byte, error) {
+	route, err := GPXRoute(linestring)
+	if err != nil {
+		return nil, err
+	}
+
+	segment := gpx.GPXTrackSegment{}
+	for _, lonLat := range GPXStrTo2DString(route) {
+		if len(lonLat) != 2 {
+			return nil, fmt.Errorf("invalid point %q", strings.Join(lonLat, " "))
+		}
+
+		lon, err := strconv.ParseFloat(lonLat[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		lat, err := strconv.ParseFloat(lonLat[1], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		segment.Points = append(segment.Points, gpx.GPXPoint{
+			Point: gpx.Point{Latitude: lat, Longitude: lon},
+		})
+	}
+
+	doc := gpx.GPX{
+		Creator: "xjco2913",
+		Tracks: []gpx.GPXTrack{{
+			Name:     name,
+			Segments: []gpx.GPXTrackSegment{segment},
+		}},
+	}
+
+	return doc.ToXml(gpx.ToXmlParams{Version: "1.1", Indent: true})
+}
@@ -3,6 +3,9 @@ package util
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
 )
 
 func TestGPXParser(t *testing.T) {
@@ -770,3 +773,89 @@ func TestGPXParser(t *testing.T) {
 		}
 	}
 }
+
+func TestLineStringToGPX(t *testing.T) {
+	data, err := LineStringToGPX("route", "LINESTRING(13.748273 46.434981,13.748193 46.43489)")
+	if err != nil {
+		t.Fatalf("LineStringToGPX returned an error: %v", err)
+	}
+
+	points, err := GPXToLonLat(data)
+	if err != nil {
+		t.Fatalf("exported gpx does not parse: %v", err)
+	}
+
+	expected := []string{"13.748273 46.434981", "13.748193 46.43489"}
+	if len(points) != len(expected) {
+		t.Fatalf("points = %v; expected %v", points, expected)
+	}
+	for i := range expected {
+		if points[i] != expected[i] {
+			t.Errorf("points[%d] = %v; expected %v", i, points[i], expected[i])
+		}
+	}
+
+	if _, err := LineStringToGPX("route", "POINT"); err == nil {
+		t.Error("LineStringToGPX accepted an invalid linestring")
+	}
+}
+
+func TestGPXSummary(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+	<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+	<trk>
+	<trkseg>
+	<trkpt lat="53.8008" lon="-1.5491"><ele>100</ele><time>2024-05-01T08:00:00Z</time></trkpt>
+	<trkpt lat="53.9600" lon="-1.0873"><ele>130</ele><time>2024-05-01T09:00:00Z</time></trkpt>
+	<trkpt lat="53.8008" lon="-1.5491"><ele>110</ele><time>2024-05-01T10:00:00Z</time></trkpt>
+	</trkseg>
+	</trk>
+	</gpx>`
+
+	summary, err := GPXSummary([]byte(data))
+	if err != nil {
+		t.Fatalf("GPXSummary returned an error: %v", err)
+	}
+
+	if summary.Distance < 70000 || summary.Distance > 73000 {
+		t.Errorf("Distance = %v; expected about 71000", summary.Distance)
+	}
+	if summary.ElevationGain != 30 {
+		t.Errorf("ElevationGain = %v; expected 30", summary.ElevationGain)
+	}
+	if expected := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC); !summary.StartTime.Equal(expected) {
+		t.Errorf("StartTime = %v; expected %v", summary.StartTime, expected)
+	}
+
+	// Leeds to York and back, 2 hours for about 71km
+	if summary.Best10K <= 0 || summary.Best10K > time.Hour {
+		t.Errorf("Best10K = %v; expected a split within the first hour", summary.Best10K)
+	}
+
+	if _, err := GPXSummary([]byte("not gpx")); err == nil {
+		t.Error("GPXSummary accepted invalid data")
+	}
+}
+
+func TestFastestSplit(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	// about 5.5km between consecutive points, slow first then fast
+	points := []gpx.GPXPoint{
+		{Point: gpx.Point{Latitude: 53.80, Longitude: -1.55}, Timestamp: start},
+		{Point: gpx.Point{Latitude: 53.85, Longitude: -1.55}, Timestamp: start.Add(30 * time.Minute)},
+		{Point: gpx.Point{Latitude: 53.90, Longitude: -1.55}, Timestamp: start.Add(40 * time.Minute)},
+		{Point: gpx.Point{Latitude: 53.95, Longitude: -1.55}, Timestamp: start.Add(50 * time.Minute)},
+	}
+
+	if actual := fastestSplit(points, 10000); actual != 20*time.Minute {
+		t.Errorf("fastestSplit = %v; expected 20m", actual)
+	}
+	if actual := fastestSplit(points, 100000); actual != 0 {
+		t.Errorf("fastestSplit of a too short route = %v; expected 0", actual)
+	}
+
+	points[2].Timestamp = time.Time{}
+	if actual := fastestSplit(points, 10000); actual != 0 {
+		t.Errorf("fastestSplit without timestamps = %v; expected 0", actual)
+	}
+}
@@ -0,0 +1,178 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"api.backend.xjco2913/util/config"
+	"api.backend.xjco2913/util/zlog"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Supported values of mail.driver
+const (
+	DRIVER_SMTP = "smtp"
+	DRIVER_FILE = "file"
+	DRIVER_LOG  = "log"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers plain text mails, the implementation is picked by mail.driver
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+var (
+	defaultMailer Mailer
+	defaultOnce   sync.Once
+)
+
+// Default returns the mailer configured in config.yml, falling back to LogMailer
+func Default() Mailer {
+	defaultOnce.Do(func() {
+		from := config.Get("mail.from")
+
+		switch config.Get("mail.driver") {
+		case DRIVER_SMTP:
+			defaultMailer = &SMTPMailer{
+				Host:     config.Get("mail.smtp.host"),
+				Port:     config.Get("mail.smtp.port"),
+				Username: config.Get("mail.smtp.username"),
+				Password: config.Get("mail.smtp.password"),
+				From:     from,
+			}
+		case DRIVER_FILE:
+			defaultMailer = &FileMailer{
+				Dir:  config.Get("mail.file.dir"),
+				From: from,
+			}
+		default:
+			defaultMailer = &LogMailer{From: from}
+		}
+	})
+
+	return defaultMailer
+}
+
+// SMTPMailer sends mails through an SMTP server, port 465 uses implicit TLS, other ports STARTTLS when offered
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(m.Host, m.Port)
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if m.Port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: m.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("dial smtp server: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(Format(m.From, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// FileMailer writes every mail into its own .eml file under Dir, for local development
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), uuid.NewString())
+	return os.WriteFile(filepath.Join(m.Dir, name), Format(m.From, msg), 0o644)
+}
+
+// LogMailer only logs the mail, it is the fallback when no driver is configured
+type LogMailer struct {
+	From string
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	zlog.Info("Mail not delivered, printed by log mailer",
+		zap.String("from", m.From),
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
+
+// Format renders msg as a RFC 5322 message
+func Format(from string, msg *Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m := &FileMailer{Dir: dir, From: "noreply@example.com"}
+
+	err := m.Send(context.Background(), &Message{
+		To:      "user@example.com",
+		Subject: "Hello",
+		Body:    "line 1\nline 2",
+	})
+	if err != nil {
+		t.Fatalf("Send returned an error: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir returned an error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("FileMailer wrote %d files; expected 1", len(entries))
+	}
+
+	content, err := os.ReadFile(dir + "/" + entries[0].Name())
+	if err != nil {
+		t.Fatalf("ReadFile returned an error: %v", err)
+	}
+
+	for _, expected := range []string{"To: user@example.com\r\n", "Subject: Hello\r\n", "\r\n\r\nline 1\r\nline 2"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("mail %q does not contain %q", content, expected)
+		}
+	}
+}
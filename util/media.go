@@ -0,0 +1,148 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"net/http"
+	"strings"
+
+	// decoders for the image formats moments accept
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrUnsupportedVideo = errors.New("unsupported video format")
+)
+
+// ftyp brands of ISO media files that are not videos
+var nonVideoBrands = map[string]bool{
+	"M4A ": true, "M4B ": true, "M4P ": true,
+	"heic": true, "heix": true, "mif1": true, "msf1": true, "avif": true,
+}
+
+// DetectImage returns the content type and the dimensions of a JPEG, PNG or GIF image
+func DetectImage(data []byte) (string, int, int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", 0, 0, ErrUnsupportedImage
+	}
+
+	return http.DetectContentType(data), config.Width, config.Height, nil
+}
+
+// DetectVideo returns the content type of a video and, for the MP4 and QuickTime family, the
+// dimensions of its first video track as stored, without rotation. Dimensions of other formats
+// are 0. Videos the content cannot tell apart are accepted if declared, the content type the
+// client sent, is a video type.
+func DetectVideo(data []byte, declared string) (string, int, int, error) {
+	if contentType, ok := isoMediaType(data); ok {
+		width, height := isoMediaDimensions(data)
+		return contentType, width, height, nil
+	}
+
+	contentType := http.DetectContentType(data)
+	if strings.HasPrefix(contentType, "video/") {
+		return contentType, 0, 0, nil
+	}
+	if declared = strings.ToLower(strings.TrimSpace(declared)); strings.HasPrefix(declared, "video/") {
+		return declared, 0, 0, nil
+	}
+
+	return "", 0, 0, ErrUnsupportedVideo
+}
+
+// isoMediaType reads the major brand of the ftyp box that begins MP4, QuickTime and 3GP files
+func isoMediaType(data []byte) (string, bool) {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return "", false
+	}
+
+	brand := string(data[8:12])
+	switch {
+	case nonVideoBrands[brand]:
+		return "", false
+	case brand == "qt  ":
+		return "video/quicktime", true
+	case strings.HasPrefix(brand, "3g2"):
+		return "video/3gpp2", true
+	case strings.HasPrefix(brand, "3gp"):
+		return "video/3gpp", true
+	default:
+		return "video/mp4", true
+	}
+}
+
+// isoMediaDimensions returns the width and height in the tkhd box of the first track that has any,
+// audio tracks have none. 0 if no track has dimensions.
+func isoMediaDimensions(data []byte) (int, int) {
+	for _, trak := range isoBoxes(isoBox(data, "moov"), "trak") {
+		tkhd := isoBox(trak, "tkhd")
+		if len(tkhd) < 4 {
+			continue
+		}
+
+		// version 1 has 64-bit times and duration
+		offset := 4 + 20
+		if tkhd[0] == 1 {
+			offset = 4 + 32
+		}
+		// reserved, layer, alternate group, volume, reserved and the matrix come first
+		offset += 8 + 2 + 2 + 2 + 2 + 36
+		if len(tkhd) < offset+8 {
+			continue
+		}
+
+		// 16.16 fixed point
+		width := int(binary.BigEndian.Uint32(tkhd[offset:]) >> 16)
+		height := int(binary.BigEndian.Uint32(tkhd[offset+4:]) >> 16)
+		if width > 0 && height > 0 {
+			return width, height
+		}
+	}
+
+	return 0, 0
+}
+
+// isoBox returns the payload of the first box of the type in data, nil if there is none
+func isoBox(data []byte, boxType string) []byte {
+	boxes := isoBoxes(data, boxType)
+	if len(boxes) == 0 {
+		return nil
+	}
+
+	return boxes[0]
+}
+
+// isoBoxes returns the payloads of the boxes of the type at the top level of data
+func isoBoxes(data []byte, boxType string) [][]byte {
+	res := [][]byte{}
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		header := uint64(8)
+		switch size {
+		case 0:
+			// the box runs to the end
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return res
+			}
+			size, header = binary.BigEndian.Uint64(data[8:]), 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return res
+		}
+
+		if string(data[4:8]) == boxType {
+			res = append(res, data[header:size])
+		}
+		data = data[size:]
+	}
+
+	return res
+}
@@ -0,0 +1,94 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestDetectImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatal(err)
+	}
+
+	contentType, width, height, err := DetectImage(buf.Bytes())
+	if err != nil {
+		t.Fatalf("DetectImage(png) returned error %v", err)
+	}
+	if contentType != "image/png" || width != 40 || height != 30 {
+		t.Errorf("DetectImage(png) = %s %dx%d; expected image/png 40x30", contentType, width, height)
+	}
+
+	if _, _, _, err := DetectImage([]byte("not an image")); err != ErrUnsupportedImage {
+		t.Errorf("DetectImage(text) error = %v; expected %v", err, ErrUnsupportedImage)
+	}
+}
+
+// box builds an ISO media box
+func box(boxType string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	res := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(res, uint32(8+len(body)))
+	copy(res[4:], boxType)
+
+	return append(res, body...)
+}
+
+// tkhd builds a version 0 track header with the dimensions
+func tkhd(width, height uint32) []byte {
+	payload := make([]byte, 4+20+52+8)
+	binary.BigEndian.PutUint32(payload[76:], width<<16)
+	binary.BigEndian.PutUint32(payload[80:], height<<16)
+
+	return box("tkhd", payload)
+}
+
+func isoMedia(brand string) []byte {
+	return bytes.Join([][]byte{
+		box("ftyp", []byte(brand), make([]byte, 4)),
+		box("moov",
+			// the audio track has no dimensions
+			box("trak", tkhd(0, 0)),
+			box("trak", tkhd(1920, 1080)),
+		),
+	}, nil)
+}
+
+func TestDetectVideo(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        []byte
+		declared    string
+		contentType string
+		width       int
+		height      int
+	}{
+		{"mp4", isoMedia("isom"), "", "video/mp4", 1920, 1080},
+		{"quicktime", isoMedia("qt  "), "application/octet-stream", "video/quicktime", 1920, 1080},
+		{"3gp", isoMedia("3gp5"), "", "video/3gpp", 1920, 1080},
+		{"mp4 without moov", box("ftyp", []byte("mp42"), make([]byte, 4)), "", "video/mp4", 0, 0},
+		{"webm", []byte("\x1A\x45\xDF\xA3\x01\x00\x00\x00"), "", "video/webm", 0, 0},
+		{"declared", []byte("unknown content"), "Video/X-Matroska", "video/x-matroska", 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			contentType, width, height, err := DetectVideo(tc.data, tc.declared)
+			if err != nil {
+				t.Fatalf("DetectVideo returned error %v", err)
+			}
+			if contentType != tc.contentType || width != tc.width || height != tc.height {
+				t.Errorf("DetectVideo = %s %dx%d; expected %s %dx%d", contentType, width, height, tc.contentType, tc.width, tc.height)
+			}
+		})
+	}
+
+	for _, data := range [][]byte{[]byte("not a video"), isoMedia("M4A ")} {
+		if _, _, _, err := DetectVideo(data, "text/plain"); err != ErrUnsupportedVideo {
+			t.Errorf("DetectVideo(%q) error = %v; expected %v", data[:8], err, ErrUnsupportedVideo)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}_.-]+)`)
+
+// ParseMentions returns the usernames mentioned as @username in the content,
+// each once and in the order they first appear
+func ParseMentions(content string) []string {
+	res := []string{}
+	seen := map[string]bool{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// a full stop ends the sentence, not the username
+		name := strings.TrimRight(match[1], ".")
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		res = append(res, name)
+	}
+
+	return res
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{"none", "Nice ride!", []string{}},
+		{"one", "@alice nice ride", []string{"alice"}},
+		{"end of sentence", "Thanks @bob.", []string{"bob"}},
+		{"repeated", "@alice and @bob_1, right @alice?", []string{"alice", "bob_1"}},
+		{"unicode", "@李雷 加油", []string{"李雷"}},
+		{"bare at", "meet @ 9", []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := ParseMentions(tc.content)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("ParseMentions(%q) = %v; expected %v", tc.content, actual, tc.expected)
+			}
+		})
+	}
+}
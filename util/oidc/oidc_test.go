@@ -0,0 +1,264 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"api.backend.xjco2913/util/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "test-client"
+	testClientSecret = "test-secret"
+	testRedirectURL  = "http://localhost:3000/sso/callback"
+)
+
+func newTestProvider(t *testing.T) (*oidctest.Server, *Provider) {
+	t.Helper()
+
+	idp := oidctest.NewServer(testClientID, testClientSecret)
+	t.Cleanup(idp.Close)
+
+	provider, err := NewProvider(context.Background(), Config{
+		Issuer:       idp.Issuer(),
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+
+	return idp, provider
+}
+
+// login runs the authorization code flow up to the token response
+func login(t *testing.T, idp *oidctest.Server, provider *Provider, nonce string, claims jwt.MapClaims) *Token {
+	t.Helper()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE returned an error: %v", err)
+	}
+
+	code, state, err := idp.Login(provider.AuthCodeURL("state-1", nonce, challenge), claims)
+	if err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %v; expected state-1", state)
+	}
+
+	token, err := provider.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange returned an error: %v", err)
+	}
+
+	return token
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp, provider := newTestProvider(t)
+
+	token := login(t, idp, provider, "nonce-1", jwt.MapClaims{
+		"sub":            "alice",
+		"email":          "alice@example.com",
+		"email_verified": "true",
+		"name":           "Alice",
+	})
+
+	idToken, err := provider.VerifyIDToken(context.Background(), token.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken returned an error: %v", err)
+	}
+
+	if idToken.Subject != "alice" {
+		t.Errorf("Subject = %v; expected alice", idToken.Subject)
+	}
+	if idToken.Email != "alice@example.com" || !idToken.EmailVerified {
+		t.Errorf("Email = %v (verified %v); expected alice@example.com (verified true)", idToken.Email, idToken.EmailVerified)
+	}
+	if idToken.Issuer != idp.Issuer() {
+		t.Errorf("Issuer = %v; expected %v", idToken.Issuer, idp.Issuer())
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	_, provider := newTestProvider(t)
+
+	u, err := url.Parse(provider.AuthCodeURL("s", "n", "c"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL is not a valid url: %v", err)
+	}
+
+	expected := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "s",
+		"nonce":                 "n",
+		"code_challenge":        "c",
+		"code_challenge_method": "S256",
+	}
+	for key, value := range expected {
+		if actual := u.Query().Get(key); actual != value {
+			t.Errorf("%s = %v; expected %v", key, actual, value)
+		}
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp, provider := newTestProvider(t)
+
+	_, challenge, _ := NewPKCE()
+	code, _, err := idp.Login(provider.AuthCodeURL("s", "n", challenge), idp.DefaultClaims)
+	if err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+
+	otherVerifier, _, _ := NewPKCE()
+	if _, err := provider.Exchange(context.Background(), code, otherVerifier); err == nil {
+		t.Error("Exchange succeeded with the wrong code verifier")
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	idp, provider := newTestProvider(t)
+
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   idp.Issuer(),
+			"aud":   testClientID,
+			"sub":   "alice",
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Minute).Unix(),
+			"nonce": "n",
+		}
+	}
+
+	if _, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(valid()), "n"); err != nil {
+		t.Fatalf("VerifyIDToken rejected a valid token: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(claims jwt.MapClaims)
+	}{
+		{"wrong nonce", func(c jwt.MapClaims) { c["nonce"] = "other" }},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other-client" }},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() }},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"no subject", func(c jwt.MapClaims) { delete(c, "sub") }},
+		{"foreign azp", func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+			c["azp"] = "other-client"
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := valid()
+			tc.modify(claims)
+
+			if _, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(claims), "n"); err == nil {
+				t.Errorf("VerifyIDToken accepted a token with %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsUnsigned(t *testing.T) {
+	idp, provider := newTestProvider(t)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss":   idp.Issuer(),
+		"aud":   testClientID,
+		"sub":   "alice",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "n",
+	})
+	raw, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("SignedString returned an error: %v", err)
+	}
+
+	if _, err := provider.VerifyIDToken(context.Background(), raw, "n"); err == nil {
+		t.Error("VerifyIDToken accepted an unsigned token")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp, provider := newTestProvider(t)
+
+	first := login(t, idp, provider, "n", idp.DefaultClaims)
+	if _, err := provider.VerifyIDToken(context.Background(), first.IDToken, "n"); err != nil {
+		t.Fatalf("VerifyIDToken returned an error: %v", err)
+	}
+
+	// a new kid forces a refetch once the refresh interval has passed
+	idp.RotateKey()
+	provider.keys.fetchedAt = time.Now().Add(-jwksMinRefreshInterval)
+
+	second := login(t, idp, provider, "n", idp.DefaultClaims)
+	if _, err := provider.VerifyIDToken(context.Background(), second.IDToken, "n"); err != nil {
+		t.Errorf("VerifyIDToken did not pick up the rotated key: %v", err)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer(testClientID, "")
+	defer idp.Close()
+
+	_, err := NewProvider(context.Background(), Config{
+		Issuer:   idp.Issuer() + "/other",
+		ClientID: testClientID,
+	})
+	if err == nil {
+		t.Error("NewProvider accepted a provider whose metadata names another issuer")
+	}
+}
+
+func TestAuthorizeRedirect(t *testing.T) {
+	_, provider := newTestProvider(t)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	_, challenge, _ := NewPKCE()
+	resp, err := client.Get(provider.AuthCodeURL("s", "n", challenge))
+	if err != nil {
+		t.Fatalf("GET authorize returned an error: %v", err)
+	}
+	resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusFound || !strings.HasPrefix(location, testRedirectURL) {
+		t.Fatalf("authorize answered %d %v; expected a redirect to %v", resp.StatusCode, location, testRedirectURL)
+	}
+
+	u, _ := url.Parse(location)
+	if u.Query().Get("code") == "" || u.Query().Get("state") != "s" {
+		t.Errorf("redirect %v does not carry code and state", location)
+	}
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// RFC 7636 Appendix B
+	actual := PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if expected := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; actual != expected {
+		t.Errorf("PKCEChallenge = %v; expected %v", actual, expected)
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import "time"
+
+// Prorate returns the part of price that covers remaining out of period, rounded to the
+// nearest unit. remaining may span several periods, e.g. after a renewal.
+func Prorate(price int32, remaining time.Duration, period time.Duration) int32 {
+	if period <= 0 || remaining <= 0 {
+		return 0
+	}
+
+	return int32((int64(price)*int64(remaining) + int64(period)/2) / int64(period))
+}
+
+// ConvertPeriod returns how long remaining time on one plan lasts on another plan of the same
+// value, comparing the price per time of both. Free plans keep the time as it is.
+func ConvertPeriod(remaining time.Duration, fromPrice int32, fromPeriod time.Duration, toPrice int32, toPeriod time.Duration) time.Duration {
+	if remaining <= 0 {
+		return 0
+	}
+	if fromPrice <= 0 || toPrice <= 0 || fromPeriod <= 0 || toPeriod <= 0 {
+		return remaining
+	}
+
+	value := float64(remaining) / float64(fromPeriod) * float64(fromPrice)
+	return time.Duration(value / float64(toPrice) * float64(toPeriod)).Round(time.Second)
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProrate(t *testing.T) {
+	period := 30 * 24 * time.Hour
+
+	testCases := []struct {
+		name      string
+		price     int32
+		remaining time.Duration
+		expected  int32
+	}{
+		{"full period", 1000, period, 1000},
+		{"half period", 1000, period / 2, 500},
+		{"a third rounds", 1000, period / 3, 333},
+		{"two thirds rounds", 1000, 2 * period / 3, 667},
+		{"nothing left", 1000, 0, 0},
+		{"already expired", 1000, -time.Hour, 0},
+		{"renewed period", 1000, 3 * period / 2, 1500},
+		{"free plan", 0, period / 2, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := Prorate(tc.price, tc.remaining, period); actual != tc.expected {
+				t.Errorf("Prorate(%d, %v) = %d; expected %d", tc.price, tc.remaining, actual, tc.expected)
+			}
+		})
+	}
+
+	if actual := Prorate(1000, time.Hour, 0); actual != 0 {
+		t.Errorf("Prorate with an empty period = %d; expected 0", actual)
+	}
+}
+
+func TestConvertPeriod(t *testing.T) {
+	period := 30 * 24 * time.Hour
+
+	testCases := []struct {
+		name      string
+		remaining time.Duration
+		fromPrice int32
+		toPrice   int32
+		expected  time.Duration
+	}{
+		{"same price", 10 * 24 * time.Hour, 500, 500, 10 * 24 * time.Hour},
+		{"to a dearer plan", 10 * 24 * time.Hour, 500, 1000, 5 * 24 * time.Hour},
+		{"to a cheaper plan", 10 * 24 * time.Hour, 1000, 500, 20 * 24 * time.Hour},
+		{"from a free plan", 10 * 24 * time.Hour, 0, 1000, 10 * 24 * time.Hour},
+		{"nothing left", -time.Hour, 500, 1000, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := ConvertPeriod(tc.remaining, tc.fromPrice, period, tc.toPrice, period)
+			if actual != tc.expected {
+				t.Errorf("ConvertPeriod = %v; expected %v", actual, tc.expected)
+			}
+		})
+	}
+}
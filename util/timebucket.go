@@ -0,0 +1,141 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	// the alpine runtime image has no zoneinfo, embed it into the binary
+	_ "time/tzdata"
+)
+
+// Supported calendar intervals
+const (
+	INTERVAL_DAY   = "day"
+	INTERVAL_WEEK  = "week"
+	INTERVAL_MONTH = "month"
+)
+
+// Upper bound of buckets in one report, keeps the generated SQL reasonable
+const MAX_TIME_BUCKETS = 400
+
+// TimeBucket is a half-open calendar period [Start, End)
+type TimeBucket struct {
+	Start time.Time
+	End   time.Time
+}
+
+// LoadLocation returns the named timezone, an empty name falls back to server local time
+func LoadLocation(name string) (*time.Location, error) {
+	if IsEmpty(name) {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(name)
+}
+
+// StartOfInterval truncates t to the beginning of its day, ISO week (Monday) or month in loc
+func StartOfInterval(t time.Time, interval string, loc *time.Location) (time.Time, error) {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+	switch interval {
+	case INTERVAL_DAY:
+		return day, nil
+	case INTERVAL_WEEK:
+		// time.Sunday is 0, shift it so that Monday is the first day of week
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset), nil
+	case INTERVAL_MONTH:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported interval: %s", interval)
+	}
+}
+
+// NextInterval returns the beginning of the interval following start.
+// AddDate is used instead of fixed durations so DST changes and month lengths are respected
+func NextInterval(start time.Time, interval string) (time.Time, error) {
+	switch interval {
+	case INTERVAL_DAY:
+		return start.AddDate(0, 0, 1), nil
+	case INTERVAL_WEEK:
+		return start.AddDate(0, 0, 7), nil
+	case INTERVAL_MONTH:
+		return start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported interval: %s", interval)
+	}
+}
+
+// CalendarBuckets splits [start, end) into calendar aligned buckets in loc.
+// The buckets follow the intervals containing start to end - 1ns, the first one is clamped
+// to begin at start and the last one to finish at end, so nothing outside the range is covered.
+func CalendarBuckets(start, end time.Time, interval string, loc *time.Location) ([]TimeBucket, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	cur, err := StartOfInterval(start, interval, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := []TimeBucket{}
+	for cur.Before(end) {
+		if len(buckets) >= MAX_TIME_BUCKETS {
+			return nil, fmt.Errorf("too many buckets, at most %d are allowed", MAX_TIME_BUCKETS)
+		}
+
+		next, err := NextInterval(cur, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		bucket := TimeBucket{
+			Start: cur,
+			End:   next,
+		}
+		if bucket.Start.Before(start) {
+			bucket.Start = start.In(loc)
+		}
+		if bucket.End.After(end) {
+			bucket.End = end.In(loc)
+		}
+
+		buckets = append(buckets, bucket)
+		cur = next
+	}
+
+	return buckets, nil
+}
+
+// LongestDayStreak returns the longest run of consecutive calendar days in loc
+// with at least one of times
+func LongestDayStreak(times []time.Time, loc *time.Location) int {
+	seen := make(map[int64]bool, len(times))
+	days := make([]int64, 0, len(times))
+	for _, t := range times {
+		t = t.In(loc)
+		// days since the epoch of the calendar date, the same in every timezone
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+
+	longest, run := 0, 0
+	for i, day := range days {
+		if i > 0 && day == days[i-1]+1 {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	return longest
+}
@@ -0,0 +1,165 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartOfInterval(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	// Wednesday 2024-03-13 15:04 in London
+	now := time.Date(2024, 3, 13, 15, 4, 0, 0, loc)
+
+	testCases := []struct {
+		interval string
+		expected time.Time
+	}{
+		{INTERVAL_DAY, time.Date(2024, 3, 13, 0, 0, 0, 0, loc)},
+		{INTERVAL_WEEK, time.Date(2024, 3, 11, 0, 0, 0, 0, loc)},
+		{INTERVAL_MONTH, time.Date(2024, 3, 1, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.interval, func(t *testing.T) {
+			actual, err := StartOfInterval(now, tc.interval, loc)
+			if err != nil {
+				t.Fatalf("StartOfInterval returned an error: %v", err)
+			}
+			if !actual.Equal(tc.expected) {
+				t.Errorf("StartOfInterval(%v) = %v; expected %v", tc.interval, actual, tc.expected)
+			}
+		})
+	}
+
+	if _, err := StartOfInterval(now, "year", loc); err == nil {
+		t.Errorf("StartOfInterval should reject unsupported interval")
+	}
+}
+
+func TestCalendarBucketsMonth(t *testing.T) {
+	loc := time.UTC
+	start := time.Date(2024, 1, 31, 12, 0, 0, 0, loc)
+	end := time.Date(2024, 4, 1, 0, 0, 0, 0, loc)
+
+	buckets, err := CalendarBuckets(start, end, INTERVAL_MONTH, loc)
+	if err != nil {
+		t.Fatalf("CalendarBuckets returned an error: %v", err)
+	}
+
+	// the first bucket begins at start, not at the beginning of January
+	expected := []time.Time{
+		start,
+		time.Date(2024, 2, 1, 0, 0, 0, 0, loc),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, loc),
+	}
+	if len(buckets) != len(expected) {
+		t.Fatalf("CalendarBuckets returned %d buckets; expected %d", len(buckets), len(expected))
+	}
+	for i, bucket := range buckets {
+		if !bucket.Start.Equal(expected[i]) {
+			t.Errorf("bucket %d starts at %v; expected %v", i, bucket.Start, expected[i])
+		}
+	}
+
+	// February of a leap year must end on the 1st of March, not 30 days later
+	if !buckets[1].End.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, loc)) {
+		t.Errorf("February bucket ends at %v", buckets[1].End)
+	}
+}
+
+func TestCalendarBucketsClamped(t *testing.T) {
+	loc := time.UTC
+	// 2024-03-15 to 2024-04-10 inclusive
+	start := time.Date(2024, 3, 15, 0, 0, 0, 0, loc)
+	end := time.Date(2024, 4, 11, 0, 0, 0, 0, loc)
+
+	buckets, err := CalendarBuckets(start, end, INTERVAL_MONTH, loc)
+	if err != nil {
+		t.Fatalf("CalendarBuckets returned an error: %v", err)
+	}
+
+	expected := []TimeBucket{
+		{Start: start, End: time.Date(2024, 4, 1, 0, 0, 0, 0, loc)},
+		{Start: time.Date(2024, 4, 1, 0, 0, 0, 0, loc), End: end},
+	}
+	if len(buckets) != len(expected) {
+		t.Fatalf("CalendarBuckets returned %d buckets; expected %d", len(buckets), len(expected))
+	}
+	for i, bucket := range buckets {
+		if !bucket.Start.Equal(expected[i].Start) || !bucket.End.Equal(expected[i].End) {
+			t.Errorf("bucket %d is [%v, %v); expected [%v, %v)", i, bucket.Start, bucket.End, expected[i].Start, expected[i].End)
+		}
+	}
+}
+
+func TestCalendarBucketsDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	// Clocks go forward on 2024-03-31
+	start := time.Date(2024, 3, 30, 0, 0, 0, 0, loc)
+	end := time.Date(2024, 4, 2, 0, 0, 0, 0, loc)
+
+	buckets, err := CalendarBuckets(start, end, INTERVAL_DAY, loc)
+	if err != nil {
+		t.Fatalf("CalendarBuckets returned an error: %v", err)
+	}
+	if len(buckets) != 3 {
+		t.Fatalf("CalendarBuckets returned %d buckets; expected 3", len(buckets))
+	}
+	if d := buckets[1].End.Sub(buckets[1].Start); d != 23*time.Hour {
+		t.Errorf("DST day lasts %v; expected 23h", d)
+	}
+	for _, bucket := range buckets {
+		if bucket.Start.Hour() != 0 {
+			t.Errorf("bucket starts at %v; expected local midnight", bucket.Start)
+		}
+	}
+}
+
+func TestCalendarBucketsInvalid(t *testing.T) {
+	now := time.Now()
+
+	if _, err := CalendarBuckets(now, now, INTERVAL_DAY, time.UTC); err == nil {
+		t.Errorf("CalendarBuckets should reject an empty range")
+	}
+
+	if _, err := CalendarBuckets(now, now.AddDate(5, 0, 0), INTERVAL_DAY, time.UTC); err == nil {
+		t.Errorf("CalendarBuckets should reject too many buckets")
+	}
+}
+
+func TestLongestDayStreak(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	day := func(d int, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, loc)
+	}
+
+	testCases := []struct {
+		name     string
+		times    []time.Time
+		expected int
+	}{
+		{"no rides", nil, 0},
+		{"one day", []time.Time{day(1, 8), day(1, 18)}, 1},
+		{"gap", []time.Time{day(1, 8), day(2, 8), day(4, 8)}, 2},
+		{"unordered", []time.Time{day(5, 8), day(3, 8), day(4, 8), day(1, 8)}, 3},
+		// 23:30 UTC on the 1st is the 2nd in loc
+		{"timezone", []time.Time{day(1, 8), time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)}, 2},
+		{"month end", []time.Time{time.Date(2024, 2, 29, 8, 0, 0, 0, loc), day(1, 8)}, 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := LongestDayStreak(tc.times, loc); actual != tc.expected {
+				t.Errorf("LongestDayStreak = %d; expected %d", actual, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors of RFC 6238 Appendix B (SHA1, the last 6 digits of the 8 digit codes)
+func TestTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	testCases := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range testCases {
+		actual, err := TOTPCode(secret, TOTPStep(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode returned an error: %v", err)
+		}
+		if actual != tc.expected {
+			t.Errorf("TOTPCode(%d) = %v; expected %v", tc.unix, actual, tc.expected)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret returned an error: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+	tooOld, _ := TOTPCode(secret, TOTPStep(now)-3)
+
+	if step, ok := ValidateTOTP(secret, previous, now, 1); !ok || step != TOTPStep(now)-1 {
+		t.Errorf("ValidateTOTP should accept the previous step, got %v %v", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, tooOld, now, 1); ok {
+		t.Errorf("ValidateTOTP should reject a code outside the skew window")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now, 1); ok {
+		t.Errorf("ValidateTOTP should reject a code of the wrong length")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Walk Club", "alice@example.com", "ABC")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Walk%20Club:alice@example.com?") {
+		t.Errorf("unexpected label in %v", uri)
+	}
+	for _, param := range []string{"secret=ABC", "issuer=Walk+Club", "digits=6", "period=30"} {
+		if !strings.Contains(uri, param) {
+			t.Errorf("%v does not contain %v", uri, param)
+		}
+	}
+}